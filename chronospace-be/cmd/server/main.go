@@ -18,14 +18,18 @@
 //
 // The main function initializes the configuration, database connection, services, controllers, middleware, and routers.
 // It also starts the HTTP server and handles graceful shutdown on interrupt signals.
+// Run "server migrate up|down|status" to manage the database schema instead.
 package main
 
 import (
 	"chronospace-be/internal/config"
 	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/mailer"
 	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/payments"
 	"chronospace-be/internal/routers"
 	"chronospace-be/internal/services"
+	"chronospace-be/internal/token"
 	"context"
 	"fmt"
 	"log"
@@ -52,14 +56,61 @@ func main() {
 	}
 	defer newPool.Close()
 
-	newService := services.NewService(newPool, newConfig.SecretKey, newConfig.GoogleAPI)
+	// "server migrate ..." manages the schema and exits without serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(context.Background(), newPool, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if newConfig.AutoMigrate {
+		if err := autoMigrate(context.Background(), newPool); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to migrate database: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	newMailer, err := mailer.New(&newConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to create mailer: %v\n", err)
+		os.Exit(1)
+	}
+
+	tokens, err := token.NewManager(&newConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to load token signing keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	paymentProvider, err := payments.New(&newConfig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to create payment provider: %v\n", err)
+		os.Exit(1)
+	}
+
+	newService := services.NewService(newPool, newMailer, tokens, paymentProvider, &newConfig)
+	if newConfig.ExchangeRatesFile != "" {
+		if err := newService.ExchangeRateService.LoadRatesFile(context.Background(), newConfig.ExchangeRatesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to load exchange rates: %v\n", err)
+			os.Exit(1)
+		}
+	}
 	newController := controllers.NewController(*newService)
 
-	jwtMiddleware := middleware.NewJWTMiddleware(newConfig.SecretKey)
+	jwtMiddleware := middleware.NewJWTMiddleware(tokens)
 
 	newRouter := routers.NewRouter(&newConfig, newController, jwtMiddleware)
 	newRouter.SetRoutes()
 
+	// Import external calendar feeds and retry failed payment operations in
+	// the background until shutdown
+	syncCtx, stopSync := context.WithCancel(context.Background())
+	defer stopSync()
+	go newService.CalendarService.RunSync(syncCtx, newConfig.CalendarSyncInterval)
+	go newService.PaymentService.RunOperations(syncCtx, newConfig.PaymentRetryInterval)
+
 	newServer := &http.Server{
 		Addr:    ":" + newConfig.ServerPort,
 		Handler: newRouter.Gin,
@@ -78,6 +129,7 @@ func main() {
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutdown initiated...")
+	stopSync()
 
 	// Context for graceful shutdown with a timeout of 5 seconds
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
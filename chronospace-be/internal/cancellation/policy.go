@@ -0,0 +1,128 @@
+// Package cancellation decides how much of a booking's price is refunded when
+// a guest cancels. A policy is a list of tiers keyed by the number of days
+// left before check-in; the named presets cover the common cases.
+package cancellation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrInvalidPolicy is returned for unknown preset names and malformed tiers.
+var ErrInvalidPolicy = errors.New("invalid cancellation policy")
+
+const (
+	Flexible = "flexible"
+	Moderate = "moderate"
+	Strict   = "strict"
+	Custom   = "custom"
+)
+
+// maxTiers bounds custom policies to something a guest can still read.
+const maxTiers = 10
+
+// Tier refunds RefundPercent of the price when the guest cancels at least
+// DaysBefore days before check-in.
+type Tier struct {
+	DaysBefore    int `json:"days_before"`
+	RefundPercent int `json:"refund_percent"`
+}
+
+type Policy struct {
+	Name  string `json:"name"`
+	Tiers []Tier `json:"tiers"`
+}
+
+var presets = map[string][]Tier{
+	Flexible: {{DaysBefore: 1, RefundPercent: 100}},
+	Moderate: {{DaysBefore: 5, RefundPercent: 100}, {DaysBefore: 1, RefundPercent: 50}},
+	Strict:   {{DaysBefore: 14, RefundPercent: 100}, {DaysBefore: 7, RefundPercent: 50}},
+}
+
+// Default is the policy of services that never chose one.
+func Default() Policy {
+	policy, _ := Normalize(Policy{Name: Flexible})
+	return policy
+}
+
+// Normalize validates a policy and returns it in canonical form: presets get
+// their tiers filled in and custom tiers are sorted from the earliest
+// cancellation to the latest.
+func Normalize(policy Policy) (Policy, error) {
+	if tiers, ok := presets[policy.Name]; ok {
+		if len(policy.Tiers) > 0 {
+			return Policy{}, fmt.Errorf("%w: the %s policy has fixed tiers", ErrInvalidPolicy, policy.Name)
+		}
+		return Policy{Name: policy.Name, Tiers: append([]Tier(nil), tiers...)}, nil
+	}
+	if policy.Name != Custom {
+		return Policy{}, fmt.Errorf("%w: unknown policy %q", ErrInvalidPolicy, policy.Name)
+	}
+
+	if len(policy.Tiers) == 0 || len(policy.Tiers) > maxTiers {
+		return Policy{}, fmt.Errorf("%w: custom policies need 1 to %d tiers", ErrInvalidPolicy, maxTiers)
+	}
+
+	tiers := append([]Tier(nil), policy.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].DaysBefore > tiers[j].DaysBefore })
+	for i, tier := range tiers {
+		if tier.DaysBefore < 0 || tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+			return Policy{}, fmt.Errorf("%w: tiers need days_before >= 0 and refund_percent between 0 and 100", ErrInvalidPolicy)
+		}
+		if i > 0 && tier.DaysBefore == tiers[i-1].DaysBefore {
+			return Policy{}, fmt.Errorf("%w: duplicate tier for %d days", ErrInvalidPolicy, tier.DaysBefore)
+		}
+		// Cancelling later must never pay back more than cancelling earlier
+		if i > 0 && tier.RefundPercent > tiers[i-1].RefundPercent {
+			return Policy{}, fmt.Errorf("%w: refunds must not grow closer to check-in", ErrInvalidPolicy)
+		}
+	}
+
+	return Policy{Name: Custom, Tiers: tiers}, nil
+}
+
+// Parse decodes a stored policy. Empty input yields the default policy.
+func Parse(raw []byte) (Policy, error) {
+	if len(raw) == 0 {
+		return Default(), nil
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(raw, &policy); err != nil {
+		return Policy{}, fmt.Errorf("%w: %v", ErrInvalidPolicy, err)
+	}
+	return Normalize(policy)
+}
+
+// Marshal encodes a policy for storage.
+func (p Policy) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+// DaysBefore returns the number of whole days between now and check-in,
+// counted in calendar days like check-in dates are.
+func DaysBefore(checkIn, now time.Time) int {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	day := time.Date(checkIn.Year(), checkIn.Month(), checkIn.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Sub(today).Hours() / 24)
+}
+
+// RefundPercent returns the share of the price refunded for a cancellation
+// daysBefore days before check-in.
+func (p Policy) RefundPercent(daysBefore int) int {
+	for _, tier := range p.Tiers {
+		if daysBefore >= tier.DaysBefore {
+			return tier.RefundPercent
+		}
+	}
+	return 0
+}
+
+// Refund returns the part of amount refunded for a cancellation daysBefore
+// days before check-in, rounded down to the minor unit.
+func (p Policy) Refund(amount int64, daysBefore int) int64 {
+	return amount * int64(p.RefundPercent(daysBefore)) / 100
+}
@@ -0,0 +1,158 @@
+package cancellation
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		want    []Tier
+		wantErr bool
+	}{
+		{
+			name:   "preset gets its tiers",
+			policy: Policy{Name: Moderate},
+			want:   []Tier{{DaysBefore: 5, RefundPercent: 100}, {DaysBefore: 1, RefundPercent: 50}},
+		},
+		{
+			name:    "preset with tiers",
+			policy:  Policy{Name: Strict, Tiers: []Tier{{DaysBefore: 1, RefundPercent: 100}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown name",
+			policy:  Policy{Name: "lenient"},
+			wantErr: true,
+		},
+		{
+			name: "custom tiers are sorted from the earliest cancellation",
+			policy: Policy{Name: Custom, Tiers: []Tier{
+				{DaysBefore: 2, RefundPercent: 25},
+				{DaysBefore: 30, RefundPercent: 100},
+				{DaysBefore: 7, RefundPercent: 50},
+			}},
+			want: []Tier{
+				{DaysBefore: 30, RefundPercent: 100},
+				{DaysBefore: 7, RefundPercent: 50},
+				{DaysBefore: 2, RefundPercent: 25},
+			},
+		},
+		{
+			name: "equal refunds in consecutive tiers",
+			policy: Policy{Name: Custom, Tiers: []Tier{
+				{DaysBefore: 10, RefundPercent: 50},
+				{DaysBefore: 3, RefundPercent: 50},
+			}},
+			want: []Tier{{DaysBefore: 10, RefundPercent: 50}, {DaysBefore: 3, RefundPercent: 50}},
+		},
+		{
+			name: "refund grows closer to check-in",
+			policy: Policy{Name: Custom, Tiers: []Tier{
+				{DaysBefore: 14, RefundPercent: 50},
+				{DaysBefore: 2, RefundPercent: 100},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "refund grows in the middle tier",
+			policy: Policy{Name: Custom, Tiers: []Tier{
+				{DaysBefore: 30, RefundPercent: 80},
+				{DaysBefore: 10, RefundPercent: 90},
+				{DaysBefore: 1, RefundPercent: 10},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate days",
+			policy: Policy{Name: Custom, Tiers: []Tier{
+				{DaysBefore: 5, RefundPercent: 100},
+				{DaysBefore: 5, RefundPercent: 50},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "refund above 100 percent",
+			policy:  Policy{Name: Custom, Tiers: []Tier{{DaysBefore: 1, RefundPercent: 120}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative days",
+			policy:  Policy{Name: Custom, Tiers: []Tier{{DaysBefore: -1, RefundPercent: 100}}},
+			wantErr: true,
+		},
+		{
+			name:    "no tiers",
+			policy:  Policy{Name: Custom},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.policy)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPolicy) {
+					t.Fatalf("Normalize() error = %v, want %v", err, ErrInvalidPolicy)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Tiers, tt.want) {
+				t.Fatalf("Normalize() tiers = %v, want %v", got.Tiers, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyRefund(t *testing.T) {
+	policy, err := Normalize(Policy{Name: Strict})
+	if err != nil {
+		t.Fatalf("Normalize() error = %v", err)
+	}
+
+	tests := []struct {
+		daysBefore int
+		amount     int64
+		want       int64
+	}{
+		{daysBefore: 30, amount: 10000, want: 10000},
+		{daysBefore: 14, amount: 10000, want: 10000},
+		{daysBefore: 13, amount: 10000, want: 5000},
+		{daysBefore: 7, amount: 999, want: 499},
+		{daysBefore: 6, amount: 10000, want: 0},
+		{daysBefore: 0, amount: 10000, want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := policy.Refund(tt.amount, tt.daysBefore); got != tt.want {
+			t.Errorf("Refund(%d, %d) = %d, want %d", tt.amount, tt.daysBefore, got, tt.want)
+		}
+	}
+}
+
+func TestDaysBefore(t *testing.T) {
+	checkIn := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		now  time.Time
+		want int
+	}{
+		{now: time.Date(2024, time.March, 10, 23, 59, 0, 0, time.UTC), want: 0},
+		{now: time.Date(2024, time.March, 9, 0, 1, 0, 0, time.UTC), want: 1},
+		{now: time.Date(2024, time.March, 3, 12, 0, 0, 0, time.UTC), want: 7},
+		{now: time.Date(2024, time.March, 11, 8, 0, 0, 0, time.UTC), want: -1},
+	}
+
+	for _, tt := range tests {
+		if got := DaysBefore(checkIn, tt.now); got != tt.want {
+			t.Errorf("DaysBefore(%v) = %d, want %d", tt.now, got, tt.want)
+		}
+	}
+}
@@ -2,10 +2,17 @@ package config
 
 import (
 	"log"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// minMFAKeyLength is the shortest MFA_ENCRYPTION_KEY accepted, in bytes.
+const minMFAKeyLength = 32
+
 type Config struct {
 	WebappBaseUrl string `mapstructure:"WEBAPP_BASE_URL"`
 	ServerPort    string `mapstructure:"SERVER_PORT"`
@@ -13,11 +20,121 @@ type Config struct {
 	DBSource      string `mapstructure:"DB_SOURCE"`
 	SecretKey     string `mapstructure:"SECRET_KEY"`
 	GoogleAPI     string `mapstructure:"GOOGLE_API"`
+
+	// Token signing: JWT_ALGORITHM is HS256 (signed with SECRET_KEY), RS256
+	// or EdDSA (PEM keys in JWT_KEYS_DIR). JWT_KEY_ID names the signing key;
+	// see token.NewManager for rotation.
+	JWTAlgorithm       string `mapstructure:"JWT_ALGORITHM"`
+	JWTKeyID           string `mapstructure:"JWT_KEY_ID"`
+	JWTKeysDir         string `mapstructure:"JWT_KEYS_DIR"`
+	JWTPreviousSecrets string `mapstructure:"JWT_PREVIOUS_SECRETS"`
+
+	// MFAEncryptionKey encrypts the TOTP secrets stored for two-factor
+	// authentication. It is required, at least minMFAKeyLength bytes long and
+	// independent of the token signing keys.
+	MFAEncryptionKey string `mapstructure:"MFA_ENCRYPTION_KEY"`
+
+	// AutoMigrate applies pending migrations when the server starts.
+	AutoMigrate bool `mapstructure:"AUTO_MIGRATE"`
+
+	// Mail delivery: MAIL_BACKEND is "smtp", "file" (writes .eml files to
+	// MAIL_DIR) or "log" (default).
+	MailBackend  string `mapstructure:"MAIL_BACKEND"`
+	MailFrom     string `mapstructure:"MAIL_FROM"`
+	MailDir      string `mapstructure:"MAIL_DIR"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+
+	// MaxSessions caps the concurrent sessions per user; signing in on
+	// another device ends the least recently used one. Zero means no limit.
+	MaxSessions int `mapstructure:"MAX_SESSIONS"`
+
+	// Failed sign-ins: an account is locked for LOGIN_LOCKOUT_DURATION after
+	// LOGIN_MAX_ATTEMPTS failures in a row, a client IP after
+	// LOGIN_IP_MAX_ATTEMPTS. Zero disables the respective lockout.
+	LoginMaxAttempts     int           `mapstructure:"LOGIN_MAX_ATTEMPTS"`
+	LoginIPMaxAttempts   int           `mapstructure:"LOGIN_IP_MAX_ATTEMPTS"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	// TRUSTED_PROXIES lists the addresses or CIDR ranges of reverse proxies
+	// whose X-Forwarded-For header is believed. By default none is, and the
+	// client IP is the address of the connection.
+	TrustedProxyList string   `mapstructure:"TRUSTED_PROXIES"`
+	TrustedProxies   []string `mapstructure:"-"`
+
+	// PublicBaseURL is where clients reach this API; OIDC redirect URIs are
+	// derived from it.
+	PublicBaseURL string `mapstructure:"PUBLIC_BASE_URL"`
+
+	// OIDC_PROVIDERS lists the enabled identity providers by name; each one
+	// is configured through OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID and
+	// OIDC_<NAME>_CLIENT_SECRET.
+	OIDCProviderNames string         `mapstructure:"OIDC_PROVIDERS"`
+	OIDCProviders     []OIDCProvider `mapstructure:"-"`
+
+	// Payments: PAYMENT_PROVIDER selects the payment service provider
+	// ("fake" keeps payments in memory and is the default outside
+	// production). Webhooks are only accepted with a PAYMENT_WEBHOOK_SECRET,
+	// which production requires. Captures and refunds that fail are retried
+	// every PAYMENT_RETRY_INTERVAL.
+	PaymentProvider      string        `mapstructure:"PAYMENT_PROVIDER"`
+	PaymentWebhookSecret string        `mapstructure:"PAYMENT_WEBHOOK_SECRET"`
+	PaymentRetryInterval time.Duration `mapstructure:"PAYMENT_RETRY_INTERVAL"`
+
+	// Currencies: DEFAULT_CURRENCY is the ISO 4217 code of services that do
+	// not declare one; EXCHANGE_RATES_FILE optionally names a JSON file of
+	// exchange rates loaded at startup.
+	DefaultCurrency   string `mapstructure:"DEFAULT_CURRENCY"`
+	ExchangeRatesFile string `mapstructure:"EXCHANGE_RATES_FILE"`
+
+	// InvoiceTaxRate is the VAT rate in percent included in service prices
+	// and shown on invoices, e.g. 10 or 7.7, with at most two decimals.
+	InvoiceTaxRate float64 `mapstructure:"INVOICE_TAX_RATE"`
+
+	// CalendarSyncInterval controls how often external iCal feeds are
+	// imported; zero disables the background import.
+	CalendarSyncInterval time.Duration `mapstructure:"CALENDAR_SYNC_INTERVAL"`
+}
+
+type OIDCProvider struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
 }
 
 func LoadConfig(path string) (config Config, err error) {
 	viper.SetConfigFile(path + ".env")
 
+	viper.SetDefault("AUTO_MIGRATE", false)
+	viper.SetDefault("JWT_ALGORITHM", "HS256")
+	viper.SetDefault("JWT_KEY_ID", "default")
+	viper.SetDefault("JWT_KEYS_DIR", "")
+	viper.SetDefault("JWT_PREVIOUS_SECRETS", "")
+	viper.SetDefault("MFA_ENCRYPTION_KEY", "")
+	viper.SetDefault("CALENDAR_SYNC_INTERVAL", "30m")
+	viper.SetDefault("MAX_SESSIONS", 10)
+	viper.SetDefault("LOGIN_MAX_ATTEMPTS", 10)
+	viper.SetDefault("LOGIN_IP_MAX_ATTEMPTS", 50)
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("PUBLIC_BASE_URL", "http://localhost:8080")
+	viper.SetDefault("OIDC_PROVIDERS", "")
+	viper.SetDefault("PAYMENT_PROVIDER", "")
+	viper.SetDefault("PAYMENT_WEBHOOK_SECRET", "")
+	viper.SetDefault("PAYMENT_RETRY_INTERVAL", "1m")
+	viper.SetDefault("DEFAULT_CURRENCY", "EUR")
+	viper.SetDefault("EXCHANGE_RATES_FILE", "")
+	viper.SetDefault("INVOICE_TAX_RATE", 0)
+	viper.SetDefault("MAIL_BACKEND", "log")
+	viper.SetDefault("MAIL_FROM", "Chronospace <no-reply@chronospace.com>")
+	viper.SetDefault("MAIL_DIR", "./mail")
+	viper.SetDefault("SMTP_HOST", "")
+	viper.SetDefault("SMTP_PORT", "587")
+	viper.SetDefault("SMTP_USERNAME", "")
+	viper.SetDefault("SMTP_PASSWORD", "")
 	viper.AutomaticEnv()
 
 	err = viper.ReadInConfig()
@@ -31,5 +148,94 @@ func LoadConfig(path string) (config Config, err error) {
 		log.Fatalf("could not loadconfig: %v", err)
 	}
 
+	config.OIDCProviders = loadOIDCProviders(config.OIDCProviderNames)
+	config.TrustedProxies = loadTrustedProxies(config.TrustedProxyList)
+
+	if len(config.MFAEncryptionKey) < minMFAKeyLength {
+		log.Fatalf("could not loadconfig: MFA_ENCRYPTION_KEY must be at least %d bytes", minMFAKeyLength)
+	}
+
+	loadPaymentConfig(&config)
+
+	if config.InvoiceTaxRate < 0 || config.InvoiceTaxRate >= 100 {
+		log.Fatalf("could not loadconfig: INVOICE_TAX_RATE must be between 0 and 100")
+	}
+	// Invoices store the rate with two decimals and compute the tax from it
+	rate := strconv.FormatFloat(config.InvoiceTaxRate, 'f', -1, 64)
+	if _, decimals, ok := strings.Cut(rate, "."); ok && len(decimals) > 2 {
+		log.Fatalf("could not loadconfig: INVOICE_TAX_RATE must have at most two decimals")
+	}
+
 	return
 }
+
+// loadPaymentConfig checks the payment settings. Production needs a real
+// provider and a webhook secret; elsewhere the fake provider is used unless
+// another one is configured.
+func loadPaymentConfig(config *Config) {
+	config.PaymentProvider = strings.ToLower(strings.TrimSpace(config.PaymentProvider))
+
+	if config.EnvType == "prod" {
+		if config.PaymentProvider == "" || config.PaymentProvider == "fake" {
+			log.Fatalf("could not loadconfig: PAYMENT_PROVIDER must name a real payment provider in production")
+		}
+		if config.PaymentWebhookSecret == "" {
+			log.Fatalf("could not loadconfig: PAYMENT_WEBHOOK_SECRET is required in production")
+		}
+		return
+	}
+
+	if config.PaymentProvider == "" {
+		log.Printf("PAYMENT_PROVIDER is not set, using the fake payment provider")
+		config.PaymentProvider = "fake"
+	}
+	if config.PaymentWebhookSecret == "" {
+		log.Printf("PAYMENT_WEBHOOK_SECRET is not set, payment webhooks are disabled")
+	}
+}
+
+// loadTrustedProxies parses the comma-separated list of proxy addresses and
+// CIDR ranges.
+func loadTrustedProxies(list string) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(list, ",") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+
+		_, prefixErr := netip.ParsePrefix(proxy)
+		_, addrErr := netip.ParseAddr(proxy)
+		if prefixErr != nil && addrErr != nil {
+			log.Fatalf("could not loadconfig: TRUSTED_PROXIES entry %q is not an address or CIDR range", proxy)
+		}
+		proxies = append(proxies, proxy)
+	}
+	return proxies
+}
+
+// loadOIDCProviders reads the settings of every provider named in the
+// comma-separated list. Their keys depend on the names, so they cannot be
+// unmarshalled with the rest of the config.
+func loadOIDCProviders(names string) []OIDCProvider {
+	var providers []OIDCProvider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProvider{
+			Name:         name,
+			Issuer:       viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+		}
+		if provider.Issuer == "" || provider.ClientID == "" {
+			log.Fatalf("could not loadconfig: %sISSUER and %sCLIENT_ID are required", prefix, prefix)
+		}
+		providers = append(providers, provider)
+	}
+	return providers
+}
@@ -4,8 +4,12 @@ import (
 	"chronospace-be/internal/models"
 	"chronospace-be/internal/services"
 	"chronospace-be/internal/utils"
+	"context"
+	"errors"
 	"net/http"
 
+	err2 "chronospace-be/internal/models/enums"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,6 +32,7 @@ func NewBookingController(bookingService services.BookingService) *BookingContro
 // @Param booking body models.CreateBookingParams true "Booking details"
 // @Success 201 {object} models.Booking
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /v1/api/bookings [post]
 func (c *BookingController) CreateBooking(ctx *gin.Context) {
 	userID, err := utils.GetUserIDFromContext(ctx)
@@ -45,7 +50,7 @@ func (c *BookingController) CreateBooking(ctx *gin.Context) {
 
 	booking, err := c.bookingService.CreateBooking(ctx, params)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -57,20 +62,27 @@ func (c *BookingController) CreateBooking(ctx *gin.Context) {
 // @Tags Booking
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Booking ID"
 // @Success 200 {object} models.Booking
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
 // @Router /v1/api/bookings/{id} [get]
 func (c *BookingController) GetBooking(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
 		return
 	}
 
-	booking, err := c.bookingService.GetBooking(ctx, id)
+	booking, err := c.bookingService.GetBooking(ctx, actor, id)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -78,12 +90,13 @@ func (c *BookingController) GetBooking(ctx *gin.Context) {
 }
 
 // @Summary List all bookings
-// @Description Get all bookings
+// @Description Get all bookings (admin only)
 // @Tags Booking
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Success 200 {array} models.Booking
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400,403 {object} models.ErrorResponse
 // @Router /v1/api/bookings [get]
 func (c *BookingController) ListBookings(ctx *gin.Context) {
 	bookings, err := c.bookingService.ListBookings(ctx)
@@ -121,16 +134,24 @@ func (c *BookingController) ListUserBookings(ctx *gin.Context) {
 }
 
 // @Summary Update booking
-// @Description Update an existing booking
+// @Description Reschedule a booking that is still in the Requested status
 // @Tags Booking
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Booking ID"
 // @Param booking body models.UpdateBookingParams true "Booking details"
 // @Success 200 {object} models.Booking
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /v1/api/bookings/{id} [put]
 func (c *BookingController) UpdateBooking(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
@@ -144,9 +165,9 @@ func (c *BookingController) UpdateBooking(ctx *gin.Context) {
 	}
 	params.ID = id
 
-	booking, err := c.bookingService.UpdateBooking(ctx, params)
+	booking, err := c.bookingService.UpdateBooking(ctx, actor, params)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -154,13 +175,14 @@ func (c *BookingController) UpdateBooking(ctx *gin.Context) {
 }
 
 // @Summary Delete booking
-// @Description Delete a booking
+// @Description Delete a booking (admin only)
 // @Tags Booking
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Booking ID"
 // @Success 204 "No Content"
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,404,409 {object} models.ErrorResponse
 // @Router /v1/api/bookings/{id} [delete]
 func (c *BookingController) DeleteBooking(ctx *gin.Context) {
 	id, err := utils.ParseUUID(ctx.Param("id"))
@@ -170,9 +192,190 @@ func (c *BookingController) DeleteBooking(ctx *gin.Context) {
 	}
 
 	if err := c.bookingService.DeleteBooking(ctx, id); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// @Summary Accept booking
+// @Description Accept a requested booking and capture its authorized payment
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Param request body models.BookingStatusChangeRequest false "Reason for the change"
+// @Success 200 {object} models.Booking
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/accept [post]
+func (c *BookingController) AcceptBooking(ctx *gin.Context) {
+	c.changeBookingStatus(ctx, c.bookingService.AcceptBooking)
+}
+
+// @Summary Reject booking
+// @Description Reject a requested booking
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Param request body models.BookingStatusChangeRequest false "Reason for the change"
+// @Success 200 {object} models.Booking
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/reject [post]
+func (c *BookingController) RejectBooking(ctx *gin.Context) {
+	c.changeBookingStatus(ctx, c.bookingService.RejectBooking)
+}
+
+// @Summary Cancel booking
+// @Description Cancel a requested or accepted booking before it starts. Guests are refunded according to the booking's cancellation policy, cancellations by the provider are refunded in full.
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Param request body models.BookingStatusChangeRequest false "Reason for the change"
+// @Success 200 {object} models.Booking
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/cancel [post]
+func (c *BookingController) CancelBooking(ctx *gin.Context) {
+	c.changeBookingStatus(ctx, c.bookingService.CancelBooking)
+}
+
+// @Summary Complete booking
+// @Description Mark an accepted booking as completed
+// @Tags Booking
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Param request body models.BookingStatusChangeRequest false "Reason for the change"
+// @Success 200 {object} models.Booking
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/complete [post]
+func (c *BookingController) CompleteBooking(ctx *gin.Context) {
+	c.changeBookingStatus(ctx, c.bookingService.CompleteBooking)
+}
+
+// @Summary Booking status history
+// @Description Get the status changes of a booking
+// @Tags Booking
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Success 200 {array} models.BookingStatusChange
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/history [get]
+func (c *BookingController) ListBookingHistory(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	history, err := c.bookingService.ListBookingStatusHistory(ctx, actor, id)
+	if err != nil {
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, history)
+}
+
+// @Summary Quote booking cancellation
+// @Description Get the refund the guest would receive when cancelling the booking now, under the cancellation policy it was booked with
+// @Tags Booking
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Success 200 {object} models.CancellationQuote
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/cancellation [get]
+func (c *BookingController) QuoteCancellation(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	quote, err := c.bookingService.QuoteCancellation(ctx, actor, id)
+	if err != nil {
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+type bookingStatusChangeFunc func(context.Context, models.BookingStatusChangeParams) (models.Booking, error)
+
+// changeBookingStatus runs a booking status transition on behalf of the
+// authenticated user.
+func (c *BookingController) changeBookingStatus(ctx *gin.Context, change bookingStatusChangeFunc) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	var req models.BookingStatusChangeRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	booking, err := change(ctx, models.BookingStatusChangeParams{
+		ID:     id,
+		Actor:  actor,
+		Reason: req.Reason,
+	})
+	if err != nil {
+		ctx.JSON(bookingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, booking)
+}
+
+// bookingErrorStatus maps booking service errors to HTTP status codes.
+func bookingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrBookingConflict),
+		errors.Is(err, err2.ErrBookingNotEditable),
+		errors.Is(err, err2.ErrBookingInvalidTransition),
+		errors.Is(err, err2.ErrBookingCancellationClosed),
+		errors.Is(err, err2.ErrBookingNotEnded),
+		errors.Is(err, err2.ErrPaymentRequired),
+		errors.Is(err, err2.ErrPaymentAlreadyAuthorized),
+		errors.Is(err, err2.ErrBookingHasPayments),
+		errors.Is(err, err2.ErrBookingHasInvoice),
+		errors.Is(err, err2.ErrMinimumStayNotMet):
+		return http.StatusConflict
+	case errors.Is(err, err2.ErrBookingNotFound):
+		return http.StatusNotFound
+	default:
+		return errorStatus(err, http.StatusBadRequest)
+	}
+}
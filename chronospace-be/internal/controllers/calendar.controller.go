@@ -0,0 +1,222 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"net/http"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+const calendarContentType = "text/calendar; charset=utf-8"
+
+type CalendarController struct {
+	calendarService services.CalendarService
+}
+
+func NewCalendarController(calendarService services.CalendarService) *CalendarController {
+	return &CalendarController{
+		calendarService: calendarService,
+	}
+}
+
+// @Summary Export service calendar
+// @Description Export the reserved and blocked periods of a service as an iCalendar feed
+// @Tags Calendar
+// @Produce text/calendar
+// @Param id path string true "Service ID"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400,404 {object} models.ErrorResponse
+// @Router /v1/api/services/{id}/calendar.ics [get]
+func (c *CalendarController) ServiceCalendar(ctx *gin.Context) {
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	calendar, err := c.calendarService.ServiceCalendar(ctx, id)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusInternalServerError), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Data(http.StatusOK, calendarContentType, calendar)
+}
+
+// @Summary Export user bookings calendar
+// @Description Export the bookings of the user owning the secret calendar token as an iCalendar feed
+// @Tags Calendar
+// @Produce text/calendar
+// @Param token path string true "Secret calendar token"
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 404 {object} models.ErrorResponse
+// @Router /v1/api/calendar/{token}/bookings.ics [get]
+func (c *CalendarController) UserCalendar(ctx *gin.Context) {
+	calendar, err := c.calendarService.UserCalendar(ctx, ctx.Param("token"))
+	if err != nil {
+		if errors.Is(err, err2.ErrInvalidCalendarToken) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Data(http.StatusOK, calendarContentType, calendar)
+}
+
+// @Summary Rotate calendar token
+// @Description Issue a new secret URL for the authenticated user's bookings feed; the previous URL stops working
+// @Tags Calendar
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.CalendarTokenResponse
+// @Failure 401,500 {object} models.ErrorResponse
+// @Router /v1/api/calendar/token [post]
+func (c *CalendarController) RotateToken(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	token, err := c.calendarService.RotateUserCalendarToken(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.CalendarTokenResponse{
+		Token: token,
+		URL:   "/v1/api/calendar/" + token + "/bookings.ics",
+	})
+}
+
+// @Summary Add calendar feed
+// @Description Import an external iCalendar feed as blocked periods of a service
+// @Tags Calendar
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param feed body models.CreateCalendarFeedRequest true "Feed details"
+// @Success 201 {object} models.CalendarFeedResponse
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/calendar/feeds [post]
+func (c *CalendarController) CreateFeed(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req models.CreateCalendarFeedRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feed, err := c.calendarService.CreateFeed(ctx, actor, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, feed)
+}
+
+// @Summary List calendar feeds
+// @Description List the external iCalendar feeds imported into a service
+// @Tags Calendar
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Service ID"
+// @Success 200 {array} models.CalendarFeedResponse
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/calendar/services/{id}/feeds [get]
+func (c *CalendarController) ListFeeds(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	serviceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	feeds, err := c.calendarService.ListFeeds(ctx, actor, serviceID)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feeds)
+}
+
+// @Summary Sync calendar feed
+// @Description Import an external iCalendar feed immediately
+// @Tags Calendar
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feed ID"
+// @Success 200 {object} models.CalendarFeedResponse
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Failure 502 {object} models.ErrorResponse
+// @Router /v1/api/calendar/feeds/{id}/sync [post]
+func (c *CalendarController) SyncFeed(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid feed id"})
+		return
+	}
+
+	feed, err := c.calendarService.SyncFeed(ctx, actor, id)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadGateway), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, feed)
+}
+
+// @Summary Delete calendar feed
+// @Description Stop importing an external feed and remove the periods it imported
+// @Tags Calendar
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Feed ID"
+// @Success 204 "No Content"
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/calendar/feeds/{id} [delete]
+func (c *CalendarController) DeleteFeed(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid feed id"})
+		return
+	}
+
+	if err := c.calendarService.DeleteFeed(ctx, actor, id); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
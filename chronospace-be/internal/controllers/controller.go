@@ -1,21 +1,62 @@
 package controllers
 
-import "chronospace-be/internal/services"
+import (
+	"chronospace-be/internal/services"
+	"errors"
+	"net/http"
+
+	err2 "chronospace-be/internal/models/enums"
+)
 
 type Controller struct {
-	UserController     *UserController
-	BookingController  *BookingController
-	ScheduleController *ScheduleController
-	ServiceController  *ServiceController
-	MapsController     *MapsController
+	UserController         *UserController
+	BookingController      *BookingController
+	ScheduleController     *ScheduleController
+	ServiceController      *ServiceController
+	MapsController         *MapsController
+	ProviderController     *ProviderController
+	CalendarController     *CalendarController
+	OIDCController         *OIDCController
+	PaymentController      *PaymentController
+	PricingController      *PricingController
+	ExchangeRateController *ExchangeRateController
+	InvoiceController      *InvoiceController
+	KeysController         *KeysController
 }
 
 func NewController(services services.Service) *Controller {
 	return &Controller{
-		UserController:     NewUserController(*services.UserService),
-		BookingController:  NewBookingController(*services.BookingService),
-		ScheduleController: NewScheduleController(*services.ScheduleService),
-		ServiceController:  NewServiceController(*services.ServiceService),
-		MapsController:     NewMapsController(*&services.MapsService),
+		UserController:         NewUserController(*services.UserService),
+		BookingController:      NewBookingController(*services.BookingService),
+		ScheduleController:     NewScheduleController(*services.ScheduleService),
+		ServiceController:      NewServiceController(*services.ServiceService),
+		MapsController:         NewMapsController(*&services.MapsService),
+		ProviderController:     NewProviderController(*services.ServiceService, *services.BookingService),
+		CalendarController:     NewCalendarController(*services.CalendarService),
+		OIDCController:         NewOIDCController(*services.OIDCService),
+		PaymentController:      NewPaymentController(*services.PaymentService),
+		PricingController:      NewPricingController(*services.PricingService),
+		ExchangeRateController: NewExchangeRateController(*services.ExchangeRateService),
+		InvoiceController:      NewInvoiceController(*services.InvoiceService),
+		KeysController:         NewKeysController(services.Tokens),
+	}
+}
+
+// errorStatus maps errors shared by all services to HTTP status codes and
+// falls back to the given status for everything else.
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, err2.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, err2.ErrServiceNotFound),
+		errors.Is(err, err2.ErrScheduleRuleNotFound),
+		errors.Is(err, err2.ErrScheduleBlackoutNotFound),
+		errors.Is(err, err2.ErrCalendarFeedNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrUnknownCurrency),
+		errors.Is(err, err2.ErrExchangeRateNotFound):
+		return http.StatusBadRequest
+	default:
+		return fallback
 	}
 }
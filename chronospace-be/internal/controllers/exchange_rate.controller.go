@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExchangeRateController struct {
+	exchangeRateService services.ExchangeRateService
+}
+
+func NewExchangeRateController(exchangeRateService services.ExchangeRateService) *ExchangeRateController {
+	return &ExchangeRateController{
+		exchangeRateService: exchangeRateService,
+	}
+}
+
+// @Summary List exchange rates
+// @Description Get the exchange rates used to show prices in other currencies
+// @Tags ExchangeRate
+// @Produce json
+// @Success 200 {array} models.ExchangeRateResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /v1/api/exchange-rates [get]
+func (c *ExchangeRateController) ListRates(ctx *gin.Context) {
+	rates, err := c.exchangeRateService.ListRates(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// @Summary Replace exchange rates
+// @Description Replace the exchange rates of a base currency (admin only). Rates of that base missing from the request are removed.
+// @Tags ExchangeRate
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param rates body models.ExchangeRatesRequest true "Exchange rates"
+// @Success 200 {array} models.ExchangeRateResponse
+// @Failure 400,401,403 {object} models.ErrorResponse
+// @Router /v1/api/exchange-rates [put]
+func (c *ExchangeRateController) ReplaceRates(ctx *gin.Context) {
+	var req models.ExchangeRatesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rates, err := c.exchangeRateService.ReplaceRates(ctx, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"fmt"
+	"net/http"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceController struct {
+	invoiceService services.InvoiceService
+}
+
+func NewInvoiceController(invoiceService services.InvoiceService) *InvoiceController {
+	return &InvoiceController{
+		invoiceService: invoiceService,
+	}
+}
+
+// @Summary Download booking invoice
+// @Description Get the invoice of an accepted booking as a PDF, with the payments received so far. The invoice is numbered in the provider's sequence when it is first requested.
+// @Tags Invoice
+// @Produce application/pdf
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Success 200 {file} file
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/invoice.pdf [get]
+func (c *InvoiceController) GetInvoice(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	document, err := c.invoiceService.Invoice(ctx, actor, id)
+	if err != nil {
+		ctx.JSON(invoiceErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", document.FileName))
+	ctx.Data(http.StatusOK, "application/pdf", document.Content)
+}
+
+func invoiceErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrBookingNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrInvoiceNotAvailable):
+		return http.StatusConflict
+	default:
+		return errorStatus(err, http.StatusInternalServerError)
+	}
+}
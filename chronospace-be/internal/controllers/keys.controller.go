@@ -0,0 +1,30 @@
+package controllers
+
+import (
+	"chronospace-be/internal/token"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KeysController struct {
+	tokens *token.Manager
+}
+
+func NewKeysController(tokens *token.Manager) *KeysController {
+	return &KeysController{
+		tokens: tokens,
+	}
+}
+
+// @Summary Token signing keys
+// @Description Publish the public keys access tokens are signed with as a JSON Web Key Set
+// @Tags auth
+// @Produce json
+// @Success 200 {object} token.JSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (c *KeysController) JWKS(ctx *gin.Context) {
+	// Keys only change on restart, but clients should pick up rotations
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, c.tokens.JWKS())
+}
@@ -0,0 +1,160 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"net/http"
+	"net/url"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OIDCController struct {
+	oidcService services.OIDCService
+}
+
+func NewOIDCController(oidcService services.OIDCService) *OIDCController {
+	return &OIDCController{
+		oidcService: oidcService,
+	}
+}
+
+// @Summary List identity providers
+// @Description List the external identity providers users can sign in with
+// @Tags users
+// @Produce json
+// @Success 200 {object} models.OIDCProvidersResponse
+// @Router /v1/api/users/oidc/providers [get]
+func (c *OIDCController) Providers(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.OIDCProvidersResponse{Providers: c.oidcService.Providers()})
+}
+
+// @Summary Sign in with identity provider
+// @Description Redirect to the identity provider to sign in with the authorization code flow and PKCE
+// @Tags users
+// @Param provider path string true "Provider name"
+// @Success 302
+// @Failure 404,502 {object} models.ErrorResponse
+// @Router /v1/api/users/oidc/{provider}/login [get]
+func (c *OIDCController) Login(ctx *gin.Context) {
+	authURL, err := c.oidcService.StartLogin(ctx, ctx.Param("provider"))
+	if err != nil {
+		ctx.JSON(oidcErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Redirect(http.StatusFound, authURL)
+}
+
+// @Summary Identity provider callback
+// @Description Complete a sign-in at an identity provider and redirect to the webapp with access_token and refresh_token, mfa_token, or error in the URL fragment
+// @Tags users
+// @Param provider path string true "Provider name"
+// @Param state query string true "State from the login redirect"
+// @Param code query string true "Authorization code"
+// @Success 302
+// @Router /v1/api/users/oidc/{provider}/callback [get]
+func (c *OIDCController) Callback(ctx *gin.Context) {
+	values := url.Values{}
+
+	// The user declined or the provider failed before issuing a code
+	if ctx.Query("error") != "" {
+		values.Set("error", "provider_error")
+		ctx.Redirect(http.StatusFound, c.oidcService.WebappCallbackURL(values))
+		return
+	}
+
+	response, err := c.oidcService.CompleteLogin(ctx, ctx.Param("provider"), ctx.Query("state"), ctx.Query("code"), requestDevice(ctx))
+	switch {
+	case err != nil:
+		values.Set("error", oidcErrorCode(err))
+	case response.MFARequired:
+		values.Set("mfa_token", response.MFAToken)
+	default:
+		values.Set("access_token", response.AccessToken)
+		values.Set("refresh_token", response.RefreshToken)
+	}
+
+	ctx.Redirect(http.StatusFound, c.oidcService.WebappCallbackURL(values))
+}
+
+// @Summary List linked identities
+// @Description List the external identities linked to the current user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.IdentityResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /v1/api/users/me/identities [get]
+func (c *OIDCController) ListIdentities(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	identities, err := c.oidcService.ListIdentities(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, identities)
+}
+
+// @Summary Unlink identity
+// @Description Remove the link between the current user and their account at an identity provider
+// @Tags users
+// @Security BearerAuth
+// @Param provider path string true "Provider name"
+// @Success 204
+// @Failure 401,404 {object} models.ErrorResponse
+// @Router /v1/api/users/me/identities/{provider} [delete]
+func (c *OIDCController) UnlinkIdentity(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	if err := c.oidcService.UnlinkIdentity(ctx, userID, ctx.Param("provider")); err != nil {
+		ctx.JSON(oidcErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func oidcErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrOIDCProviderNotFound),
+		errors.Is(err, err2.ErrIdentityNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrOIDCLoginFailed):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// oidcErrorCode names a failed callback for the webapp.
+func oidcErrorCode(err error) string {
+	switch {
+	case errors.Is(err, err2.ErrOIDCProviderNotFound):
+		return "provider_not_found"
+	case errors.Is(err, err2.ErrOIDCInvalidState):
+		return "invalid_state"
+	case errors.Is(err, err2.ErrOIDCEmailNotVerified):
+		return "email_not_verified"
+	case errors.Is(err, err2.ErrEmailAlreadyExists):
+		return "email_already_exists"
+	case errors.Is(err, err2.ErrOIDCLoginFailed):
+		return "login_failed"
+	default:
+		return "server_error"
+	}
+}
@@ -0,0 +1,142 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"io"
+	"net/http"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody bounds the size of webhook payloads we are willing to read.
+const maxWebhookBody = 1 << 20
+
+type PaymentController struct {
+	paymentService services.PaymentService
+}
+
+func NewPaymentController(paymentService services.PaymentService) *PaymentController {
+	return &PaymentController{
+		paymentService: paymentService,
+	}
+}
+
+// @Summary Authorize booking payment
+// @Description Place a hold for the price of a requested booking. The payment is captured when the booking is accepted and released when it is rejected or canceled.
+// @Tags Payment
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Param request body models.AuthorizePaymentRequest true "Payment method"
+// @Success 201 {object} models.Payment
+// @Failure 400,401,403,404,409 {object} models.ErrorResponse
+// @Failure 402 {object} models.ErrorResponse "Payment declined"
+// @Failure 502 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/payments [post]
+func (c *PaymentController) AuthorizePayment(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	var req models.AuthorizePaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payment, err := c.paymentService.AuthorizeBooking(ctx, actor, id, req.PaymentMethod)
+	if err != nil {
+		ctx.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, payment)
+}
+
+// @Summary List booking payments
+// @Description Get all payment attempts of a booking
+// @Tags Payment
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Booking ID"
+// @Success 200 {array} models.Payment
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/bookings/{id}/payments [get]
+func (c *PaymentController) ListPayments(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid booking id"})
+		return
+	}
+
+	payments, err := c.paymentService.ListPayments(ctx, actor, id)
+	if err != nil {
+		ctx.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payments)
+}
+
+// @Summary Payment provider webhook
+// @Description Receive a signed payment notification from the payment provider
+// @Tags Payment
+// @Accept json
+// @Param provider path string true "Payment provider"
+// @Success 204
+// @Failure 400,404 {object} models.ErrorResponse
+// @Router /v1/api/payments/webhooks/{provider} [post]
+func (c *PaymentController) Webhook(ctx *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxWebhookBody))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook body"})
+		return
+	}
+
+	if err := c.paymentService.HandleWebhook(ctx, ctx.Param("provider"), ctx.Request.Header, body); err != nil {
+		ctx.JSON(paymentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrPaymentDeclined):
+		return http.StatusPaymentRequired
+	case errors.Is(err, err2.ErrPaymentAlreadyAuthorized),
+		errors.Is(err, err2.ErrBookingNotPayable):
+		return http.StatusConflict
+	case errors.Is(err, err2.ErrBookingNotFound),
+		errors.Is(err, err2.ErrPaymentProviderNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrPaymentProviderFailed):
+		return http.StatusBadGateway
+	case errors.Is(err, err2.ErrBookingInvalidInput),
+		errors.Is(err, err2.ErrInvalidPaymentWebhook):
+		return http.StatusBadRequest
+	default:
+		return errorStatus(err, http.StatusInternalServerError)
+	}
+}
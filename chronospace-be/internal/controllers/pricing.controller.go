@@ -0,0 +1,167 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"net/http"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingController struct {
+	pricingService services.PricingService
+}
+
+func NewPricingController(pricingService services.PricingService) *PricingController {
+	return &PricingController{
+		pricingService: pricingService,
+	}
+}
+
+// @Summary Quote a stay
+// @Description Get the itemized price of a stay at a service under its current pricing rules. Booking the stay locks this price in.
+// @Tags Pricing
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param from query string true "Check-in date (YYYY-MM-DD)"
+// @Param to query string true "Check-out date (YYYY-MM-DD)"
+// @Param guests query int false "Number of guests" default(1)
+// @Param currency query string false "Currency to show the quote in"
+// @Success 200 {object} models.PriceQuote
+// @Failure 400,404,409 {object} models.ErrorResponse
+// @Router /v1/api/services/{id}/quote [get]
+func (c *PricingController) Quote(ctx *gin.Context) {
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	var query models.PriceQuoteQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	quote, err := c.pricingService.Quote(ctx, id, query)
+	if err != nil {
+		ctx.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+// @Summary List pricing rules
+// @Description Get the seasonal rates, weekday modifiers, minimum stays and length-of-stay discounts of a service
+// @Tags Pricing
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} models.PricingRuleResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/services/{id}/pricing-rules [get]
+func (c *PricingController) ListRules(ctx *gin.Context) {
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	rules, err := c.pricingService.ListRules(ctx, id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// @Summary Create pricing rule
+// @Description Add a pricing rule to a service. Prices of existing bookings do not change.
+// @Tags Pricing
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Service ID"
+// @Param rule body models.CreatePricingRuleRequest true "Pricing rule"
+// @Success 201 {object} models.PricingRuleResponse
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/services/{id}/pricing-rules [post]
+func (c *PricingController) CreateRule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	var req models.CreatePricingRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule, err := c.pricingService.CreateRule(ctx, actor, id, req)
+	if err != nil {
+		ctx.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rule)
+}
+
+// @Summary Delete pricing rule
+// @Description Remove a pricing rule from a service
+// @Tags Pricing
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Service ID"
+// @Param rule_id path string true "Pricing rule ID"
+// @Success 204
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/services/{id}/pricing-rules/{rule_id} [delete]
+func (c *PricingController) DeleteRule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	serviceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+	id, err := utils.ParseUUID(ctx.Param("rule_id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid pricing rule id"})
+		return
+	}
+
+	if err := c.pricingService.DeleteRule(ctx, actor, serviceID, id); err != nil {
+		ctx.JSON(pricingErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func pricingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrPricingRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrMinimumStayNotMet),
+		errors.Is(err, err2.ErrTooManyGuests):
+		return http.StatusConflict
+	default:
+		return errorStatus(err, http.StatusBadRequest)
+	}
+}
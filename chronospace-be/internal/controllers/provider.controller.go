@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProviderController struct {
+	serviceService services.ServiceService
+	bookingService services.BookingService
+}
+
+func NewProviderController(serviceService services.ServiceService, bookingService services.BookingService) *ProviderController {
+	return &ProviderController{
+		serviceService: serviceService,
+		bookingService: bookingService,
+	}
+}
+
+// @Summary List my services
+// @Description Get all services owned by the authenticated provider
+// @Tags Provider
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} models.ServiceResponse
+// @Failure 400,401,403 {object} models.ErrorResponse
+// @Router /v1/api/providers/me/services [get]
+func (c *ProviderController) ListMyServices(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	services, err := c.serviceService.ListServicesByOwner(ctx, userID)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services)
+}
+
+// @Summary List my incoming bookings
+// @Description Get bookings made for any service owned by the authenticated provider
+// @Tags Provider
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param status query string false "Filter by booking status"
+// @Success 200 {array} models.Booking
+// @Failure 400,401,403 {object} models.ErrorResponse
+// @Router /v1/api/providers/me/bookings [get]
+func (c *ProviderController) ListMyBookings(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var query models.ProviderBookingsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	bookings, err := c.bookingService.ListBookingsByProvider(ctx, userID, query.Status)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bookings)
+}
+
+// @Summary List bookings of my service
+// @Description Get all bookings for a single service owned by the authenticated provider
+// @Tags Provider
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Service ID"
+// @Success 200 {array} models.Booking
+// @Failure 400,401,403,404 {object} models.ErrorResponse
+// @Router /v1/api/providers/me/services/{id}/bookings [get]
+func (c *ProviderController) ListServiceBookings(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	serviceID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
+		return
+	}
+
+	bookings, err := c.bookingService.ListBookingsByService(ctx, actor, serviceID)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bookings)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"chronospace-be/internal/models"
 	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -25,20 +26,27 @@ func NewScheduleController(scheduleService services.ScheduleService) *ScheduleCo
 // @Tags schedules
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param schedule body models.CreateScheduleRequest true "Schedule details"
 // @Success 201 {object} models.ScheduleResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400,403 {object} models.ErrorResponse
 // @Router /v1/api/schedules [post]
 func (c *ScheduleController) CreateSchedule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	var req models.CreateScheduleRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	schedule, err := c.scheduleService.CreateSchedule(ctx, req)
+	schedule, err := c.scheduleService.CreateSchedule(ctx, actor, req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -93,12 +101,19 @@ func (c *ScheduleController) ListSchedules(ctx *gin.Context) {
 // @Tags schedules
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Schedule ID"
 // @Param schedule body models.UpdateScheduleRequest true "Schedule details"
 // @Success 200 {object} models.ScheduleResponse
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
 // @Router /v1/api/schedules/{id} [put]
 func (c *ScheduleController) UpdateSchedule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	var id pgtype.UUID
 	if err := id.Scan(ctx.Param("id")); err != nil {
 		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid schedule ID"})
@@ -111,9 +126,9 @@ func (c *ScheduleController) UpdateSchedule(ctx *gin.Context) {
 		return
 	}
 
-	schedule, err := c.scheduleService.UpdateSchedule(ctx, id, req)
+	schedule, err := c.scheduleService.UpdateSchedule(ctx, actor, id, req)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusNotFound), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -124,21 +139,312 @@ func (c *ScheduleController) UpdateSchedule(ctx *gin.Context) {
 // @Summary Delete a schedule
 // @Description Delete a schedule by its ID
 // @Tags schedules
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Schedule ID"
 // @Success 204 "No Content"
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
 // @Router /schedules/{id} [delete]
 func (c *ScheduleController) DeleteSchedule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	var id pgtype.UUID
 	if err := id.Scan(ctx.Param("id")); err != nil {
 		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid schedule ID"})
 		return
 	}
 
-	if err := c.scheduleService.DeleteSchedule(ctx, id); err != nil {
-		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+	if err := c.scheduleService.DeleteSchedule(ctx, actor, id); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusNotFound), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// ListServiceSlots godoc
+// @Summary List concrete slots of a service
+// @Description Expand schedules and recurring rules of a service into slots for a date window (at most one year)
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Service ID"
+// @Param from query string true "Window start (YYYY-MM-DD)"
+// @Param to query string true "Window end, exclusive (YYYY-MM-DD)"
+// @Success 200 {array} models.ScheduleSlot
+// @Failure 400,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/services/{id}/slots [get]
+func (c *ScheduleController) ListServiceSlots(ctx *gin.Context) {
+	var serviceID pgtype.UUID
+	if err := serviceID.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid service ID"})
+		return
+	}
+
+	var query models.ScheduleSlotsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	slots, err := c.scheduleService.ListSlots(ctx, serviceID, query.From, query.To)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, slots)
+}
+
+// ListServiceRules godoc
+// @Summary List recurring schedule rules of a service
+// @Description Get the recurring schedule templates of a service with their exception dates
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} models.ScheduleRuleResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/schedules/services/{id}/rules [get]
+func (c *ScheduleController) ListServiceRules(ctx *gin.Context) {
+	var serviceID pgtype.UUID
+	if err := serviceID.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid service ID"})
+		return
+	}
+
+	rules, err := c.scheduleService.ListRules(ctx, serviceID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// ListServiceBlackouts godoc
+// @Summary List blackout periods of a service
+// @Description Get the periods in which a service is closed
+// @Tags schedules
+// @Produce json
+// @Param id path string true "Service ID"
+// @Success 200 {array} models.ScheduleBlackoutResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/schedules/services/{id}/blackouts [get]
+func (c *ScheduleController) ListServiceBlackouts(ctx *gin.Context) {
+	var serviceID pgtype.UUID
+	if err := serviceID.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid service ID"})
+		return
+	}
+
+	blackouts, err := c.scheduleService.ListBlackouts(ctx, serviceID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, blackouts)
+}
+
+// CreateRule godoc
+// @Summary Create a recurring schedule rule
+// @Description Create a schedule template from an RFC 5545 recurrence rule, optionally with exception dates
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param rule body models.CreateScheduleRuleRequest true "Rule details"
+// @Success 201 {object} models.ScheduleRuleResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/rules [post]
+func (c *ScheduleController) CreateRule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.CreateScheduleRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rule, err := c.scheduleService.CreateRule(ctx, actor, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rule)
+}
+
+// DeleteRule godoc
+// @Summary Delete a recurring schedule rule
+// @Description Delete a schedule rule and its exception dates
+// @Tags schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Rule ID"
+// @Success 204 "No Content"
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/rules/{id} [delete]
+func (c *ScheduleController) DeleteRule(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var id pgtype.UUID
+	if err := id.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid rule ID"})
+		return
+	}
+
+	if err := c.scheduleService.DeleteRule(ctx, actor, id); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// AddRuleException godoc
+// @Summary Exclude a date from a recurring rule
+// @Description Add an EXDATE so the rule produces no slot on the given date
+// @Tags schedules
+// @Accept json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Rule ID"
+// @Param exception body models.ScheduleExceptionRequest true "Excluded date"
+// @Success 204 "No Content"
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/rules/{id}/exceptions [post]
+func (c *ScheduleController) AddRuleException(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var id pgtype.UUID
+	if err := id.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid rule ID"})
+		return
+	}
+
+	var req models.ScheduleExceptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.scheduleService.AddRuleException(ctx, actor, id, req.Date); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
-}
\ No newline at end of file
+}
+
+// RemoveRuleException godoc
+// @Summary Remove an excluded date from a recurring rule
+// @Description Delete an EXDATE so the rule produces its slot on the given date again
+// @Tags schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Rule ID"
+// @Param date path string true "Excluded date (YYYY-MM-DD)"
+// @Success 204 "No Content"
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/rules/{id}/exceptions/{date} [delete]
+func (c *ScheduleController) RemoveRuleException(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var id pgtype.UUID
+	if err := id.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid rule ID"})
+		return
+	}
+
+	var date pgtype.Date
+	if err := date.Scan(ctx.Param("date")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid date"})
+		return
+	}
+
+	if err := c.scheduleService.RemoveRuleException(ctx, actor, id, date); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// CreateBlackout godoc
+// @Summary Create a blackout period
+// @Description Close a service for a range of dates, overriding schedules and recurring rules
+// @Tags schedules
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param blackout body models.CreateScheduleBlackoutRequest true "Blackout period"
+// @Success 201 {object} models.ScheduleBlackoutResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/blackouts [post]
+func (c *ScheduleController) CreateBlackout(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.CreateScheduleBlackoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	blackout, err := c.scheduleService.CreateBlackout(ctx, actor, req)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, blackout)
+}
+
+// DeleteBlackout godoc
+// @Summary Delete a blackout period
+// @Description Reopen a service by removing a blackout period
+// @Tags schedules
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Blackout ID"
+// @Success 204 "No Content"
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/schedules/blackouts/{id} [delete]
+func (c *ScheduleController) DeleteBlackout(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var id pgtype.UUID
+	if err := id.Scan(ctx.Param("id")); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid blackout ID"})
+		return
+	}
+
+	if err := c.scheduleService.DeleteBlackout(ctx, actor, id); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
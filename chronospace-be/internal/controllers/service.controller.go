@@ -4,8 +4,11 @@ import (
 	"chronospace-be/internal/models"
 	"chronospace-be/internal/services"
 	"chronospace-be/internal/utils"
+	"errors"
 	"net/http"
 
+	err2 "chronospace-be/internal/models/enums"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,24 +23,31 @@ func NewServiceController(serviceService services.ServiceService) *ServiceContro
 }
 
 // @Summary Create service
-// @Description Create a new service
+// @Description Create a new service owned by the authenticated provider
 // @Tags Service
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param service body models.CreateServiceRequest true "Service details"
 // @Success 201 {object} models.ServiceResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400,401,403 {object} models.ErrorResponse
 // @Router /v1/api/services [post]
 func (c *ServiceController) CreateService(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	var req models.CreateServiceRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	service, err := c.serviceService.CreateService(ctx, req)
+	service, err := c.serviceService.CreateService(ctx, actor, req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -50,6 +60,7 @@ func (c *ServiceController) CreateService(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Service ID"
+// @Param currency query string false "Currency to show the price in"
 // @Success 200 {object} models.ServiceResponse
 // @Failure 400,404 {object} models.ErrorResponse
 // @Router /v1/api/services/{id} [get]
@@ -60,9 +71,9 @@ func (c *ServiceController) GetService(ctx *gin.Context) {
 		return
 	}
 
-	service, err := c.serviceService.GetService(ctx, id)
+	service, err := c.serviceService.GetService(ctx, id, ctx.Query("currency"))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusNotFound), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -70,16 +81,23 @@ func (c *ServiceController) GetService(ctx *gin.Context) {
 }
 
 // @Summary Update service
-// @Description Update an existing service
+// @Description Update an existing service (owner or admin only)
 // @Tags Service
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Service ID"
 // @Param service body models.UpdateServiceRequest true "Service details"
 // @Success 200 {object} models.ServiceResponse
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
 // @Router /v1/api/services/{id} [put]
 func (c *ServiceController) UpdateService(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
@@ -92,9 +110,9 @@ func (c *ServiceController) UpdateService(ctx *gin.Context) {
 		return
 	}
 
-	service, err := c.serviceService.UpdateService(ctx, id, req)
+	service, err := c.serviceService.UpdateService(ctx, actor, id, req)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -102,23 +120,30 @@ func (c *ServiceController) UpdateService(ctx *gin.Context) {
 }
 
 // @Summary Delete service
-// @Description Delete a service
+// @Description Delete a service (owner or admin only)
 // @Tags Service
 // @Accept json
 // @Produce json
+// @Param Authorization header string true "Bearer token"
 // @Param id path string true "Service ID"
 // @Success 204 "No Content"
-// @Failure 400,404 {object} models.ErrorResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
 // @Router /v1/api/services/{id} [delete]
 func (c *ServiceController) DeleteService(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
 	id, err := utils.ParseUUID(ctx.Param("id"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid service id"})
 		return
 	}
 
-	if err := c.serviceService.DeleteService(ctx, id); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := c.serviceService.DeleteService(ctx, actor, id); err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -130,15 +155,67 @@ func (c *ServiceController) DeleteService(ctx *gin.Context) {
 // @Tags Service
 // @Accept json
 // @Produce json
+// @Param currency query string false "Currency to show prices in"
 // @Success 200 {array} models.ServiceResponse
 // @Failure 400 {object} models.ErrorResponse
 // @Router /v1/api/services [get]
 func (c *ServiceController) ListServices(ctx *gin.Context) {
-	services, err := c.serviceService.ListServices(ctx)
+	services, err := c.serviceService.ListServices(ctx, ctx.Query("currency"))
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	ctx.JSON(http.StatusOK, services)
-}
\ No newline at end of file
+}
+
+// @Summary Search available services
+// @Description Find services that are free for the whole stay and can host the given number of guests
+// @Tags Service
+// @Accept json
+// @Produce json
+// @Param location query string false "Location to search in"
+// @Param check_in query string true "Check-in date (YYYY-MM-DD)"
+// @Param check_out query string true "Check-out date (YYYY-MM-DD)"
+// @Param guests query int false "Number of guests" default(1)
+// @Param min_price query number false "Minimum nightly price, in the requested or default currency"
+// @Param max_price query number false "Maximum nightly price, in the requested or default currency"
+// @Param limit query int false "Page size" default(20)
+// @Param offset query int false "Page offset" default(0)
+// @Param currency query string false "Currency to show prices in"
+// @Success 200 {array} models.AvailableServiceResponse
+// @Failure 400,500 {object} models.ErrorResponse
+// @Router /v1/api/services/availability [get]
+func (c *ServiceController) SearchAvailability(ctx *gin.Context) {
+	var query models.AvailabilitySearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	services, err := c.serviceService.SearchAvailability(ctx, query)
+	if err != nil {
+		status := searchErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			// Keep database errors in the log rather than the response
+			_ = ctx.Error(err)
+			ctx.JSON(status, gin.H{"error": "failed to search services"})
+			return
+		}
+		ctx.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, services)
+}
+
+// searchErrorStatus maps availability search errors to HTTP status codes.
+func searchErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrBookingInvalidDateRange),
+		errors.Is(err, err2.ErrInvalidPrice):
+		return http.StatusBadRequest
+	default:
+		return errorStatus(err, http.StatusInternalServerError)
+	}
+}
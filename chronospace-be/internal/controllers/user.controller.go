@@ -3,10 +3,16 @@ package controllers
 import (
 	"chronospace-be/internal/models"
 	"chronospace-be/internal/services"
+	"chronospace-be/internal/utils"
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgtype"
+
+	err2 "chronospace-be/internal/models/enums"
 )
 
 type UserController struct {
@@ -52,6 +58,9 @@ func (c *UserController) Register(ctx *gin.Context) {
 // @Param credentials body models.LoginRequest true "Login credentials"
 // @Success 200 {object} models.LoginResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse "Email address not verified"
+// @Failure 423 {object} models.ErrorResponse "Account locked after too many failed attempts"
+// @Failure 429 {object} models.ErrorResponse "Too many failed attempts, see Retry-After"
 // @Router /v1/api/users/login [post]
 func (c *UserController) Login(ctx *gin.Context) {
 	var req models.LoginRequest
@@ -60,7 +69,59 @@ func (c *UserController) Login(ctx *gin.Context) {
 		return
 	}
 
-	response, err := c.userService.LoginUser(ctx, req.Email, req.Password)
+	response, err := c.userService.LoginUser(ctx, req.Email, req.Password, requestDevice(ctx))
+	if err != nil {
+		writeLoginError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Complete two-factor login
+// @Description Exchange the MFA token from /users/login and a TOTP or recovery code for access and refresh tokens
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.LoginMFARequest true "MFA token and code"
+// @Success 200 {object} models.LoginResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 423 {object} models.ErrorResponse "Account locked after too many failed attempts"
+// @Failure 429 {object} models.ErrorResponse "Too many failed attempts, see Retry-After"
+// @Router /v1/api/users/login/mfa [post]
+func (c *UserController) LoginMFA(ctx *gin.Context) {
+	var req models.LoginMFARequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := c.userService.LoginMFA(ctx, req.MFAToken, req.Code, requestDevice(ctx))
+	if err != nil {
+		writeLoginError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access and refresh token pair
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400,401 {object} models.ErrorResponse
+// @Router /v1/api/users/refresh [post]
+func (c *UserController) Refresh(ctx *gin.Context) {
+	var req models.RefreshTokenRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := c.userService.RefreshTokens(ctx, req.RefreshToken, requestDevice(ctx))
 	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: err.Error()})
 		return
@@ -69,8 +130,156 @@ func (c *UserController) Login(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, response)
 }
 
+// @Summary Verify email address
+// @Description Confirm an email address with the token from the verification mail
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} models.UserResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/users/verify [post]
+func (c *UserController) VerifyEmail(ctx *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := c.userService.VerifyEmail(ctx, req.Token)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Resend verification email
+// @Description Send a new verification link to an unverified address. The response does not reveal whether the address is registered.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.ResendVerificationRequest true "Email address"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/users/verify/resend [post]
+func (c *UserController) ResendVerification(ctx *gin.Context) {
+	var req models.ResendVerificationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.userService.ResendEmailVerification(ctx, req.Email); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrInvalidEmailFormat) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "if the address is registered and unverified, a new link has been sent"})
+}
+
+// @Summary Request password reset
+// @Description Mail a single-use password reset link. The response does not reveal whether the address is registered.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Email address"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/users/password/forgot [post]
+func (c *UserController) ForgotPassword(ctx *gin.Context) {
+	var req models.ForgotPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.userService.RequestPasswordReset(ctx, req.Email); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrInvalidEmailFormat) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "if the address is registered, a reset link has been sent"})
+}
+
+// @Summary Reset password
+// @Description Set a new password with the token from the reset mail and sign out all sessions
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /v1/api/users/password/reset [post]
+func (c *UserController) ResetPassword(ctx *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.userService.ResetPassword(ctx, req.Token, req.Password); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrInvalidResetToken) || isPasswordPolicyError(err) {
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "password has been reset"})
+}
+
+// @Summary Change password
+// @Description Change the password of the signed-in user. Other sessions are signed out and a new token pair is returned.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400,401,403 {object} models.ErrorResponse
+// @Router /v1/api/users/me/password [put]
+func (c *UserController) ChangePassword(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	response, err := c.userService.ChangePassword(ctx, userID, req.CurrentPassword, req.NewPassword, requestDevice(ctx))
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, err2.ErrWrongPassword):
+			status = http.StatusForbidden
+		case errors.Is(err, err2.ErrPasswordUnchanged), isPasswordPolicyError(err):
+			status = http.StatusBadRequest
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
 // @Summary User logout
-// @Description Logout user and invalidate their token
+// @Description End the current session so its refresh token can no longer be used
 // @Tags users
 // @Security BearerAuth
 // @Produce json
@@ -78,13 +287,14 @@ func (c *UserController) Login(ctx *gin.Context) {
 // @Failure 401 {object} models.ErrorResponse
 // @Router /v1/api/users/logout [post]
 func (c *UserController) Logout(ctx *gin.Context) {
-	userID, exists := ctx.Get("userID")
-	if !exists {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
 		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
 		return
 	}
 
-	if err := c.userService.LogoutUser(ctx, userID.(pgtype.UUID)); err != nil {
+	sessionID, _ := utils.GetSessionIDFromContext(ctx)
+	if err := c.userService.LogoutUser(ctx, userID, sessionID); err != nil {
 		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -92,16 +302,374 @@ func (c *UserController) Logout(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "logged out successfully"})
 }
 
+// @Summary Two-factor status
+// @Description Show whether two-factor authentication is enabled and how many recovery codes are left
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.MFAStatusResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /v1/api/users/me/mfa [get]
+func (c *UserController) MFAStatus(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	status, err := c.userService.MFAStatus(ctx, userID)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, status)
+}
+
+// @Summary Start TOTP enrollment
+// @Description Generate a TOTP secret and provisioning URI for an authenticator app. Two-factor authentication is enabled once a code is confirmed.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TOTPEnrollmentResponse
+// @Failure 401,409 {object} models.ErrorResponse
+// @Router /v1/api/users/me/mfa/totp [post]
+func (c *UserController) EnrollTOTP(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	enrollment, err := c.userService.EnrollTOTP(ctx, userID)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, enrollment)
+}
+
+// @Summary Confirm TOTP enrollment
+// @Description Enable two-factor authentication with a code from the authenticator app and receive recovery codes
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TOTPCodeRequest true "Authenticator code"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400,401,409 {object} models.ErrorResponse
+// @Router /v1/api/users/me/mfa/totp/confirm [post]
+func (c *UserController) ConfirmTOTP(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := c.userService.ConfirmTOTP(ctx, userID, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codes)
+}
+
+// @Summary Disable TOTP
+// @Description Turn off two-factor authentication; requires the password and a TOTP or recovery code
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.DisableTOTPRequest true "Password and code"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400,401,403 {object} models.ErrorResponse
+// @Router /v1/api/users/me/mfa/totp [delete]
+func (c *UserController) DisableTOTP(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.DisableTOTPRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := c.userService.DisableTOTP(ctx, userID, req.Password, req.Code); err != nil {
+		ctx.JSON(mfaErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "two-factor authentication disabled"})
+}
+
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes; requires a TOTP or recovery code
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TOTPCodeRequest true "Authenticator code"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400,401 {object} models.ErrorResponse
+// @Router /v1/api/users/me/mfa/recovery-codes [post]
+func (c *UserController) RegenerateRecoveryCodes(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var req models.TOTPCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	codes, err := c.userService.RegenerateRecoveryCodes(ctx, userID, req.Code)
+	if err != nil {
+		ctx.JSON(mfaErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, codes)
+}
+
+// @Summary List sessions
+// @Description List the devices the current user is signed in on
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SessionResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /v1/api/users/me/sessions [get]
+func (c *UserController) ListSessions(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	sessionID, _ := utils.GetSessionIDFromContext(ctx)
+	sessions, err := c.userService.ListSessions(ctx, userID, sessionID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// @Summary Revoke session
+// @Description Sign the current user out on one device
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.SuccessResponse
+// @Failure 400,401,404 {object} models.ErrorResponse
+// @Router /v1/api/users/me/sessions/{id} [delete]
+func (c *UserController) RevokeSession(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	sessionID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid session ID"})
+		return
+	}
+
+	if err := c.userService.RevokeSession(ctx, userID, sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "session revoked"})
+}
+
+// @Summary Revoke other sessions
+// @Description Sign the current user out on every device except this one
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /v1/api/users/me/sessions [delete]
+func (c *UserController) RevokeOtherSessions(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	sessionID, _ := utils.GetSessionIDFromContext(ctx)
+	if err := c.userService.RevokeOtherSessions(ctx, userID, sessionID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "other sessions revoked"})
+}
+
+// @Summary Get own profile
+// @Description Get the profile of the signed-in user
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.UserResponse
+// @Failure 401,404 {object} models.ErrorResponse
+// @Router /v1/api/users/me [get]
+func (c *UserController) GetMe(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	user, err := c.userService.GetUser(ctx, actor, actor.UserID)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusNotFound), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Update own profile
+// @Description Update the profile of the signed-in user. A new email address has to be verified again.
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param user body models.UpdateUserParams true "User update information"
+// @Success 200 {object} models.UserResponse
+// @Failure 400,401 {object} models.ErrorResponse
+// @Router /v1/api/users/me [put]
+func (c *UserController) UpdateMe(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var params models.UpdateUserParams
+	if err := ctx.ShouldBindJSON(&params); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := c.userService.UpdateUser(ctx, actor, actor.UserID, params)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// @Summary Delete own account
+// @Description Erase the account of the signed-in user. Personal data is anonymized; bookings are kept for the providers' records.
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.SuccessResponse
+// @Failure 401,404,409 {object} models.ErrorResponse
+// @Router /v1/api/users/me [delete]
+func (c *UserController) DeleteMe(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	if err := c.userService.DeleteUser(ctx, actor, actor.UserID); err != nil {
+		ctx.JSON(deleteUserErrorStatus(err), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, models.SuccessResponse{Message: "user deleted successfully"})
+}
+
+// @Summary Export own data
+// @Description Download everything stored about the signed-in user: profile, bookings with their status history, sessions and linked identities
+// @Tags users
+// @Security BearerAuth
+// @Produce json,application/zip
+// @Param format query string false "json (default) or zip"
+// @Success 200 {object} models.UserDataExport
+// @Failure 400,401,404 {object} models.ErrorResponse
+// @Router /v1/api/users/me/export [get]
+func (c *UserController) ExportMe(ctx *gin.Context) {
+	userID, err := utils.GetUserIDFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
+	var query models.ExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	export, err := c.userService.ExportUserData(ctx, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if query.Format == "zip" {
+		archive, err := utils.ExportArchive(export)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.Header("Content-Disposition", `attachment; filename="chronospace-export.zip"`)
+		ctx.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
+
+	ctx.Header("Content-Disposition", `attachment; filename="chronospace-export.json"`)
+	ctx.IndentedJSON(http.StatusOK, export)
+}
+
 // @Summary Get user profile
-// @Description Get user profile by ID
+// @Description Get user profile by ID (admin only)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "User ID"
 // @Success 200 {object} models.UserResponse
-// @Failure 404 {object} models.ErrorResponse
+// @Failure 403,404 {object} models.ErrorResponse
 // @Router /v1/api/users/{id} [get]
 func (c *UserController) GetUser(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	userID := ctx.Param("id")
 	var uuid pgtype.UUID
 	if err := uuid.Scan(userID); err != nil {
@@ -109,9 +677,9 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := c.userService.GetUser(ctx, uuid)
+	user, err := c.userService.GetUser(ctx, actor, uuid)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, models.ErrorResponse{Error: err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusNotFound), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -119,7 +687,7 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 }
 
 // @Summary Update user profile
-// @Description Update user profile information
+// @Description Update user profile information (admin only)
 // @Tags users
 // @Security BearerAuth
 // @Accept json
@@ -127,9 +695,15 @@ func (c *UserController) GetUser(ctx *gin.Context) {
 // @Param id path string true "User ID"
 // @Param user body models.UpdateUserParams true "User update information"
 // @Success 200 {object} models.UserResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400,403 {object} models.ErrorResponse
 // @Router /v1/api/users/{id} [put]
 func (c *UserController) UpdateUser(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	userID := ctx.Param("id")
 	var uuid pgtype.UUID
 	if err := uuid.Scan(userID); err != nil {
@@ -143,9 +717,9 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 		return
 	}
 
-	user, err := c.userService.UpdateUser(ctx, uuid, params)
+	user, err := c.userService.UpdateUser(ctx, actor, uuid, params)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -153,15 +727,21 @@ func (c *UserController) UpdateUser(ctx *gin.Context) {
 }
 
 // @Summary Delete user
-// @Description Delete user account
+// @Description Erase a user account the same way as DELETE /users/me (admin only)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
 // @Param id path string true "User ID"
 // @Success 200 {object} models.SuccessResponse
-// @Failure 400 {object} models.ErrorResponse
+// @Failure 400,403,404,409 {object} models.ErrorResponse
 // @Router /v1/api/users/{id} [delete]
 func (c *UserController) DeleteUser(ctx *gin.Context) {
+	actor, err := utils.GetActorFromContext(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: "unauthorized"})
+		return
+	}
+
 	userID := ctx.Param("id")
 	var uuid pgtype.UUID
 	if err := uuid.Scan(userID); err != nil {
@@ -169,8 +749,8 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.userService.DeleteUser(ctx, uuid); err != nil {
-		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+	if err := c.userService.DeleteUser(ctx, actor, uuid); err != nil {
+		ctx.JSON(deleteUserErrorStatus(err), models.ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -178,7 +758,7 @@ func (c *UserController) DeleteUser(ctx *gin.Context) {
 }
 
 // @Summary List users
-// @Description Get a list of users with pagination
+// @Description Get a list of users with pagination (admin only)
 // @Tags users
 // @Security BearerAuth
 // @Produce json
@@ -202,3 +782,130 @@ func (c *UserController) ListUsers(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, users)
 }
+
+// @Summary Update user role
+// @Description Assign a role to a user (admin only)
+// @Tags users
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} models.UserResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/users/{id}/role [put]
+func (c *UserController) UpdateUserRole(ctx *gin.Context) {
+	userID := ctx.Param("id")
+	var uuid pgtype.UUID
+	if err := uuid.Scan(userID); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid user ID"})
+		return
+	}
+
+	var req models.UpdateUserRoleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	user, err := c.userService.UpdateUserRole(ctx, uuid, req.Role)
+	if err != nil {
+		ctx.JSON(errorStatus(err, http.StatusBadRequest), models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// writeLoginError responds to a failed sign-in. Refusals caused by earlier
+// failures carry a Retry-After header and a code clients can act on.
+func writeLoginError(ctx *gin.Context, err error) {
+	var blocked *services.LoginBlockedError
+	if errors.As(err, &blocked) {
+		ctx.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	}
+
+	switch {
+	case errors.Is(err, err2.ErrEmailNotVerified):
+		ctx.JSON(http.StatusForbidden, models.ErrorResponse{Error: err.Error(), Code: "email_not_verified"})
+	case errors.Is(err, err2.ErrAccountLocked):
+		ctx.JSON(http.StatusLocked, models.ErrorResponse{Error: err.Error(), Code: "account_locked"})
+	case errors.Is(err, err2.ErrLoginThrottled):
+		ctx.JSON(http.StatusTooManyRequests, models.ErrorResponse{Error: err.Error(), Code: "login_throttled"})
+	default:
+		ctx.JSON(http.StatusUnauthorized, models.ErrorResponse{Error: err.Error()})
+	}
+}
+
+func mfaErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrMFAAlreadyEnabled):
+		return http.StatusConflict
+	case errors.Is(err, err2.ErrWrongPassword):
+		return http.StatusForbidden
+	case errors.Is(err, err2.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrInvalidMFACode),
+		errors.Is(err, err2.ErrMFANotEnabled),
+		errors.Is(err, err2.ErrMFANotEnrolled):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func deleteUserErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, err2.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, err2.ErrAccountOwnsServices),
+		errors.Is(err, err2.ErrAccountHasUpcomingBookings):
+		return http.StatusConflict
+	default:
+		return errorStatus(err, http.StatusInternalServerError)
+	}
+}
+
+func isPasswordPolicyError(err error) bool {
+	return errors.Is(err, err2.ErrPassword8Symbols) ||
+		errors.Is(err, err2.ErrPasswordTooLong) ||
+		errors.Is(err, err2.ErrPasswordTooWeak) ||
+		errors.Is(err, err2.ErrPasswordContainsIdentity)
+}
+
+// @Summary Unlock user
+// @Description Lift a login lockout and reset the failed attempt count (admin only)
+// @Tags users
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserResponse
+// @Failure 400,403,404 {object} models.ErrorResponse
+// @Router /v1/api/users/{id}/unlock [post]
+func (c *UserController) UnlockUser(ctx *gin.Context) {
+	userID, err := utils.ParseUUID(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid user ID"})
+		return
+	}
+
+	user, err := c.userService.UnlockUser(ctx, userID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, err2.ErrUserNotFound) {
+			status = http.StatusNotFound
+		}
+		ctx.JSON(status, models.ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, user)
+}
+
+// requestDevice describes the client making the request for session records.
+func requestDevice(ctx *gin.Context) models.Device {
+	return models.Device{
+		UserAgent: ctx.Request.UserAgent(),
+		IPAddress: ctx.ClientIP(),
+	}
+}
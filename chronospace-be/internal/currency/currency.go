@@ -0,0 +1,170 @@
+// Package currency knows the ISO 4217 currencies Chronospace accepts and
+// converts amounts between them with exact rational arithmetic. Amounts are
+// integers in the minor unit of their currency (cents for EUR, yen for JPY).
+package currency
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var (
+	// ErrUnknownCurrency is returned for codes that are not supported.
+	ErrUnknownCurrency = errors.New("unknown currency")
+	// ErrNoRate is returned when no exchange rate links two currencies.
+	ErrNoRate = errors.New("no exchange rate")
+	// ErrInvalidAmount is returned for decimal amounts that are not finite
+	// or have more fraction digits than their currency.
+	ErrInvalidAmount = errors.New("invalid amount")
+)
+
+// digits maps supported currencies to the number of digits of their minor
+// unit.
+var digits = map[string]int32{
+	"AUD": 2, "BGN": 2, "BHD": 3, "BRL": 2, "CAD": 2, "CHF": 2, "CNY": 2,
+	"CZK": 2, "DKK": 2, "EUR": 2, "GBP": 2, "HKD": 2, "HUF": 2, "IDR": 2,
+	"ILS": 2, "INR": 2, "ISK": 0, "JPY": 0, "KRW": 0, "KWD": 3, "MXN": 2,
+	"MYR": 2, "NOK": 2, "NZD": 2, "PHP": 2, "PLN": 2, "RON": 2, "SEK": 2,
+	"SGD": 2, "THB": 2, "TRY": 2, "USD": 2, "ZAR": 2,
+}
+
+// Normalize returns the upper case code of a supported currency.
+func Normalize(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if _, ok := digits[code]; !ok {
+		return "", fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return code, nil
+}
+
+// Digits returns the number of minor unit digits of a supported currency.
+func Digits(code string) int32 {
+	return digits[code]
+}
+
+// ToMinor converts a decimal amount to minor units of a currency. Amounts
+// with more precision than the currency has are rejected, not rounded.
+func ToMinor(amount pgtype.Numeric, code string) (int64, error) {
+	value, err := NumericToRat(amount)
+	if err != nil {
+		return 0, err
+	}
+
+	value.Mul(value, scale(Digits(code)))
+	if !value.IsInt() {
+		return 0, fmt.Errorf("%w: more than %d fraction digits for %s", ErrInvalidAmount, Digits(code), code)
+	}
+	if !value.Num().IsInt64() {
+		return 0, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+	return value.Num().Int64(), nil
+}
+
+// FromMinor converts minor units of a currency to a decimal amount.
+func FromMinor(amount int64, code string) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(amount), Exp: -Digits(code), Valid: true}
+}
+
+// NumericToRat returns the exact value of a finite decimal.
+func NumericToRat(n pgtype.Numeric) (*big.Rat, error) {
+	if !n.Valid || n.NaN || n.InfinityModifier != pgtype.Finite || n.Int == nil {
+		return nil, fmt.Errorf("%w: not a finite number", ErrInvalidAmount)
+	}
+
+	value := new(big.Rat).SetInt(n.Int)
+	if n.Exp >= 0 {
+		return value.Mul(value, scale(n.Exp)), nil
+	}
+	return value.Quo(value, scale(-n.Exp)), nil
+}
+
+// Pair identifies the rate that converts one unit of Base into Quote.
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// Rates converts between currencies using a set of exchange rates. A rate
+// can be used in either direction, and currencies that share a base
+// currency are converted through it.
+type Rates map[Pair]*big.Rat
+
+// Rate returns how many units of to one unit of from is worth.
+func (r Rates) Rate(from, to string) (*big.Rat, error) {
+	if from == to {
+		return big.NewRat(1, 1), nil
+	}
+	if rate, ok := r[Pair{from, to}]; ok {
+		return new(big.Rat).Set(rate), nil
+	}
+	if rate, ok := r[Pair{to, from}]; ok && rate.Sign() > 0 {
+		return new(big.Rat).Inv(rate), nil
+	}
+
+	// Cross rate through a common base, e.g. USD -> EUR -> GBP. When
+	// several bases link the two currencies the first code wins, so the
+	// result does not depend on map iteration order.
+	var bases []string
+	for pair := range r {
+		if pair.Quote != to {
+			continue
+		}
+		if fromRate, ok := r[Pair{pair.Base, from}]; ok && fromRate.Sign() > 0 {
+			bases = append(bases, pair.Base)
+		}
+	}
+	if len(bases) > 0 {
+		sort.Strings(bases)
+		base := bases[0]
+		return new(big.Rat).Quo(r[Pair{base, to}], r[Pair{base, from}]), nil
+	}
+
+	return nil, fmt.Errorf("%w from %s to %s", ErrNoRate, from, to)
+}
+
+// Convert converts minor units of from into minor units of to, rounding
+// half away from zero.
+func (r Rates) Convert(amount int64, from, to string) (int64, error) {
+	rate, err := r.Rate(from, to)
+	if err != nil {
+		return 0, err
+	}
+	return ConvertAt(amount, from, to, rate), nil
+}
+
+// ConvertAt converts minor units of from into minor units of to at the
+// given rate, rounding half away from zero.
+func ConvertAt(amount int64, from, to string, rate *big.Rat) int64 {
+	value := new(big.Rat).SetInt64(amount)
+	value.Mul(value, rate)
+	value.Mul(value, scale(Digits(to)))
+	value.Quo(value, scale(Digits(from)))
+	return Round(value)
+}
+
+// Round rounds a rational number to the nearest integer, halves away from
+// zero.
+func Round(value *big.Rat) int64 {
+	num := new(big.Int).Abs(value.Num())
+	den := value.Denom()
+
+	// floor(|value| + 1/2) = floor((2|num| + den) / 2den)
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, den)
+	num.Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if value.Sign() < 0 {
+		num.Neg(num)
+	}
+	return num.Int64()
+}
+
+// scale returns 10^exp as a rational number.
+func scale(exp int32) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
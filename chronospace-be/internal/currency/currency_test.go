@@ -0,0 +1,132 @@
+package currency
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func numeric(value int64, exp int32) pgtype.Numeric {
+	return pgtype.Numeric{Int: big.NewInt(value), Exp: exp, Valid: true}
+}
+
+func TestToMinor(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  pgtype.Numeric
+		code    string
+		want    int64
+		wantErr bool
+	}{
+		{name: "cents", amount: numeric(1999, -2), code: "EUR", want: 1999},
+		{name: "fewer digits than the currency", amount: numeric(125, -1), code: "EUR", want: 1250},
+		{name: "whole units", amount: numeric(12, 0), code: "USD", want: 1200},
+		{name: "positive exponent", amount: numeric(3, 2), code: "JPY", want: 300},
+		{name: "trailing zeros beyond the currency", amount: numeric(19990, -3), code: "EUR", want: 1999},
+		{name: "three digit currency", amount: numeric(1234, -3), code: "KWD", want: 1234},
+		{name: "negative", amount: numeric(-505, -2), code: "EUR", want: -505},
+		{name: "fraction of a cent", amount: numeric(19995, -3), code: "EUR", wantErr: true},
+		{name: "fraction of a yen", amount: numeric(15, -1), code: "JPY", wantErr: true},
+		{name: "fraction of a fils", amount: numeric(12345, -4), code: "BHD", wantErr: true},
+		{name: "not a number", amount: pgtype.Numeric{NaN: true, Valid: true}, code: "EUR", wantErr: true},
+		{name: "infinite", amount: pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, code: "EUR", wantErr: true},
+		{name: "null", amount: pgtype.Numeric{}, code: "EUR", wantErr: true},
+		{name: "out of range", amount: numeric(1, 30), code: "EUR", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToMinor(tt.amount, tt.code)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAmount) {
+					t.Fatalf("ToMinor() error = %v, want %v", err, ErrInvalidAmount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ToMinor() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ToMinor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertAt(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		from   string
+		to     string
+		rate   *big.Rat
+		want   int64
+	}{
+		{name: "same digits", amount: 10000, from: "EUR", to: "USD", rate: big.NewRat(108, 100), want: 10800},
+		{name: "half a cent rounds up", amount: 1, from: "EUR", to: "USD", rate: big.NewRat(1, 2), want: 1},
+		{name: "just under half a cent rounds down", amount: 1, from: "EUR", to: "USD", rate: big.NewRat(49, 100), want: 0},
+		{name: "negative halves round away from zero", amount: -1, from: "EUR", to: "USD", rate: big.NewRat(1, 2), want: -1},
+		{name: "repeating fraction", amount: 1000, from: "EUR", to: "GBP", rate: big.NewRat(1, 3), want: 333},
+		{name: "to a currency without minor unit", amount: 1050, from: "EUR", to: "JPY", rate: big.NewRat(16250, 100), want: 1706},
+		{name: "from a currency without minor unit", amount: 1000, from: "JPY", to: "EUR", rate: big.NewRat(1, 160), want: 625},
+		{name: "to a three digit currency", amount: 1001, from: "EUR", to: "KWD", rate: big.NewRat(333, 1000), want: 3333},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertAt(tt.amount, tt.from, tt.to, tt.rate); got != tt.want {
+				t.Fatalf("ConvertAt(%d, %s, %s, %s) = %d, want %d", tt.amount, tt.from, tt.to, tt.rate.RatString(), got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRatesRate(t *testing.T) {
+	rates := Rates{
+		{Base: "EUR", Quote: "USD"}: big.NewRat(110, 100),
+		{Base: "EUR", Quote: "GBP"}: big.NewRat(85, 100),
+		{Base: "USD", Quote: "GBP"}: big.NewRat(80, 100),
+		{Base: "USD", Quote: "CHF"}: big.NewRat(90, 100),
+		{Base: "EUR", Quote: "CHF"}: big.NewRat(95, 100),
+		{Base: "EUR", Quote: "JPY"}: big.NewRat(160, 1),
+	}
+
+	tests := []struct {
+		name    string
+		from    string
+		to      string
+		want    *big.Rat
+		wantErr bool
+	}{
+		{name: "same currency", from: "SEK", to: "SEK", want: big.NewRat(1, 1)},
+		{name: "direct", from: "EUR", to: "USD", want: big.NewRat(11, 10)},
+		{name: "inverse", from: "USD", to: "EUR", want: big.NewRat(10, 11)},
+		// EUR and USD both link GBP and CHF; EUR is the pivot every time
+		{name: "cross rate with several pivots", from: "GBP", to: "CHF", want: big.NewRat(95, 85)},
+		{name: "cross rate", from: "USD", to: "JPY", want: big.NewRat(1600, 11)},
+		{name: "no link", from: "EUR", to: "SEK", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Repeat to catch results that depend on map iteration order
+			for i := 0; i < 20; i++ {
+				got, err := rates.Rate(tt.from, tt.to)
+				if tt.wantErr {
+					if !errors.Is(err, ErrNoRate) {
+						t.Fatalf("Rate() error = %v, want %v", err, ErrNoRate)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Rate() error = %v", err)
+				}
+				if got.Cmp(tt.want) != 0 {
+					t.Fatalf("Rate() = %s, want %s", got.RatString(), tt.want.RatString())
+				}
+			}
+		})
+	}
+}
@@ -1,4 +1,5 @@
 DROP TABLE IF EXISTS schedules;
 DROP TABLE IF EXISTS bookings;
 DROP TABLE IF EXISTS services;
+DROP TABLE IF EXISTS user_tokens;
 DROP TABLE IF EXISTS users;
\ No newline at end of file
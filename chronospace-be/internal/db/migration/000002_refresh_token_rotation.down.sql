@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS user_tokens_refresh_token_idx;
+
+ALTER TABLE user_tokens DROP COLUMN IF EXISTS rotated_at;
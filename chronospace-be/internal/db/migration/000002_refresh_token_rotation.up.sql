@@ -0,0 +1,3 @@
+ALTER TABLE user_tokens ADD COLUMN rotated_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_tokens_refresh_token_idx ON user_tokens (refresh_token);
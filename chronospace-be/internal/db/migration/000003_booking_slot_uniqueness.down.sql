@@ -0,0 +1 @@
+DROP INDEX IF EXISTS bookings_active_slot_idx;
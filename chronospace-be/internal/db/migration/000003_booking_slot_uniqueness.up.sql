@@ -0,0 +1,3 @@
+CREATE UNIQUE INDEX IF NOT EXISTS bookings_active_slot_idx
+    ON bookings (service_id, date, time)
+    WHERE status <> 'Canceled';
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS bookings_active_slot_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookings_active_slot_idx
+    ON bookings (service_id, date, time)
+    WHERE status <> 'Canceled';
+
+DROP TABLE IF EXISTS booking_status_history;
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS booking_status_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    changed_by UUID REFERENCES users(id),
+    reason TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS booking_status_history_booking_idx
+    ON booking_status_history (booking_id, changed_at);
+
+DROP INDEX IF EXISTS bookings_active_slot_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookings_active_slot_idx
+    ON bookings (service_id, date, time)
+    WHERE status NOT IN ('Canceled', 'Rejected');
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS services_owner_idx;
+
+ALTER TABLE services DROP COLUMN IF EXISTS owner_id;
+
+ALTER TABLE users DROP COLUMN IF EXISTS role;
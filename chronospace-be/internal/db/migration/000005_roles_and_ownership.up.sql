@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'guest'
+    CHECK (role IN ('guest', 'provider', 'admin'));
+
+ALTER TABLE services ADD COLUMN owner_id UUID REFERENCES users(id);
+
+CREATE INDEX IF NOT EXISTS services_owner_idx ON services (owner_id);
@@ -0,0 +1,24 @@
+ALTER TABLE bookings
+    ADD COLUMN date DATE,
+    ADD COLUMN time TIME;
+
+UPDATE bookings
+SET date = check_in,
+    time = COALESCE(check_in_time, '00:00');
+
+ALTER TABLE bookings
+    ALTER COLUMN date SET NOT NULL,
+    ALTER COLUMN time SET NOT NULL;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_active_stay_excl;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_stay_range_check;
+
+ALTER TABLE bookings
+    DROP COLUMN check_in,
+    DROP COLUMN check_out,
+    DROP COLUMN check_in_time,
+    DROP COLUMN check_out_time;
+
+CREATE UNIQUE INDEX IF NOT EXISTS bookings_active_slot_idx
+    ON bookings (service_id, date, time)
+    WHERE status NOT IN ('Canceled', 'Rejected');
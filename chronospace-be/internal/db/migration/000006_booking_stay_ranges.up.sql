@@ -0,0 +1,55 @@
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE bookings
+    ADD COLUMN check_in DATE,
+    ADD COLUMN check_out DATE,
+    ADD COLUMN check_in_time TIME,
+    ADD COLUMN check_out_time TIME;
+
+-- Existing single-date bookings become one-night stays starting at the booked time
+UPDATE bookings
+SET check_in = date,
+    check_out = date + 1,
+    check_in_time = time;
+
+ALTER TABLE bookings
+    ALTER COLUMN check_in SET NOT NULL,
+    ALTER COLUMN check_out SET NOT NULL,
+    ADD CONSTRAINT bookings_stay_range_check CHECK (check_out > check_in);
+
+-- Slots used to be unique per time of day, stays are exclusive per night.
+-- Bookings sharing a night cannot be resolved automatically without
+-- canceling a guest's reservation, so the migration stops and lists them
+-- for an operator to cancel or move first. Completed stays are history and
+-- are left out of the constraint.
+DO $$
+DECLARE
+    conflicts TEXT;
+BEGIN
+    SELECT string_agg(ids, '; ')
+    INTO conflicts
+    FROM (
+        SELECT string_agg(id::TEXT, ', ' ORDER BY check_in_time, id) AS ids
+        FROM bookings
+        WHERE service_id IS NOT NULL
+          AND status IN ('Requested', 'Accepted')
+        GROUP BY service_id, check_in
+        HAVING count(*) > 1
+    ) shared;
+
+    IF conflicts IS NOT NULL THEN
+        RAISE EXCEPTION 'bookings share a night of the same service, resolve them before migrating: %', conflicts;
+    END IF;
+END
+$$;
+
+DROP INDEX IF EXISTS bookings_active_slot_idx;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_active_stay_excl
+    EXCLUDE USING gist (service_id WITH =, daterange(check_in, check_out) WITH &&)
+    WHERE (status IN ('Requested', 'Accepted'));
+
+ALTER TABLE bookings
+    DROP COLUMN date,
+    DROP COLUMN time;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS schedules_service_date_idx;
+
+ALTER TABLE services DROP COLUMN IF EXISTS max_guests;
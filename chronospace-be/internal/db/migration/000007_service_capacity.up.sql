@@ -0,0 +1,4 @@
+ALTER TABLE services
+    ADD COLUMN max_guests INTEGER NOT NULL DEFAULT 2 CHECK (max_guests > 0);
+
+CREATE INDEX IF NOT EXISTS schedules_service_date_idx ON schedules (service_id, date);
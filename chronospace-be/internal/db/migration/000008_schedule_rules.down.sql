@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS schedule_blackouts;
+DROP TABLE IF EXISTS schedule_rule_exceptions;
+DROP TABLE IF EXISTS schedule_rules;
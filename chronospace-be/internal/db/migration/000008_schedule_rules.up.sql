@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS schedule_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    starts_on DATE NOT NULL,
+    time_start TIME NOT NULL,
+    time_end TIME NOT NULL,
+    rrule TEXT NOT NULL,
+    status VARCHAR(50) NOT NULL DEFAULT 'Available',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (time_end > time_start)
+);
+
+CREATE INDEX IF NOT EXISTS schedule_rules_service_idx ON schedule_rules (service_id);
+
+CREATE TABLE IF NOT EXISTS schedule_rule_exceptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    rule_id UUID NOT NULL REFERENCES schedule_rules(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    UNIQUE (rule_id, date)
+);
+
+CREATE TABLE IF NOT EXISTS schedule_blackouts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    starts_on DATE NOT NULL,
+    ends_on DATE NOT NULL,
+    reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_on >= starts_on)
+);
+
+CREATE INDEX IF NOT EXISTS schedule_blackouts_service_idx ON schedule_blackouts (service_id, starts_on);
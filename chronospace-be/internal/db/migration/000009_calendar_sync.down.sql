@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS schedule_blackouts_feed_idx;
+
+ALTER TABLE schedule_blackouts
+    DROP COLUMN IF EXISTS external_uid,
+    DROP COLUMN IF EXISTS feed_id;
+
+DROP TABLE IF EXISTS calendar_feeds;
+
+ALTER TABLE users DROP COLUMN IF EXISTS calendar_token_hash;
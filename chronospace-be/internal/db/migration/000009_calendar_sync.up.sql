@@ -0,0 +1,17 @@
+ALTER TABLE users ADD COLUMN calendar_token_hash VARCHAR(64) UNIQUE;
+
+CREATE TABLE IF NOT EXISTS calendar_feeds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    last_synced_at TIMESTAMP,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (service_id, url)
+);
+
+ALTER TABLE schedule_blackouts
+    ADD COLUMN feed_id UUID REFERENCES calendar_feeds(id) ON DELETE CASCADE,
+    ADD COLUMN external_uid TEXT;
+
+CREATE INDEX IF NOT EXISTS schedule_blackouts_feed_idx ON schedule_blackouts (feed_id);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS user_tokens_session_idx;
+
+ALTER TABLE user_tokens
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS session_started_at,
+    DROP COLUMN IF EXISTS session_id;
@@ -0,0 +1,10 @@
+-- Rotated refresh tokens share the session of the token they replaced
+ALTER TABLE user_tokens
+    ADD COLUMN session_id UUID NOT NULL DEFAULT uuid_generate_v4(),
+    ADD COLUMN session_started_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN ip_address TEXT;
+
+UPDATE user_tokens SET session_started_at = created_at WHERE created_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS user_tokens_session_idx ON user_tokens (user_id, session_id);
@@ -0,0 +1,3 @@
+-- Erased accounts keep their row so bookings stay attributable; the
+-- personal fields are overwritten and erased_at records when
+ALTER TABLE users ADD COLUMN erased_at TIMESTAMP;
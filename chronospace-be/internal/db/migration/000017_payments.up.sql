@@ -0,0 +1,32 @@
+-- Amounts are in the minor unit of the currency (cents for EUR)
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL REFERENCES bookings(id),
+    provider VARCHAR(50) NOT NULL,
+    provider_payment_id VARCHAR(255),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL
+        CHECK (status IN ('authorized', 'captured', 'refunded', 'voided', 'failed')),
+    captured_amount BIGINT NOT NULL DEFAULT 0,
+    refunded_amount BIGINT NOT NULL DEFAULT 0,
+    failure_reason TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, provider_payment_id)
+);
+
+CREATE INDEX IF NOT EXISTS payments_booking_id_idx ON payments (booking_id);
+
+-- A booking has at most one payment holding or holding on to money
+CREATE UNIQUE INDEX IF NOT EXISTS payments_active_booking_idx ON payments (booking_id)
+    WHERE status IN ('authorized', 'captured');
+
+-- Webhook deliveries are recorded so that retries are processed once
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, event_id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE bookings DROP COLUMN IF EXISTS cancellation_policy;
+ALTER TABLE services DROP COLUMN IF EXISTS cancellation_policy;
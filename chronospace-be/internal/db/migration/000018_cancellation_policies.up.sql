@@ -0,0 +1,15 @@
+-- Policies are stored as {"name": ..., "tiers": [{"days_before", "refund_percent"}]}.
+-- Bookings keep a copy of their service's policy from the time they were
+-- made so that later edits only apply to new reservations.
+ALTER TABLE services
+    ADD COLUMN cancellation_policy JSONB NOT NULL
+        DEFAULT '{"name": "flexible", "tiers": [{"days_before": 1, "refund_percent": 100}]}';
+
+ALTER TABLE bookings ADD COLUMN cancellation_policy JSONB;
+
+UPDATE bookings b
+SET cancellation_policy = s.cancellation_policy
+FROM services s
+WHERE s.id = b.service_id;
+
+ALTER TABLE bookings ALTER COLUMN cancellation_policy SET NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS price_breakdown,
+    DROP COLUMN IF EXISTS total_amount;
+
+DROP TABLE IF EXISTS pricing_rules;
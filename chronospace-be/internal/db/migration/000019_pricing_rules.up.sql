@@ -0,0 +1,30 @@
+-- Which columns a rule uses depends on its kind, see internal/pricing
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL
+        CHECK (kind IN ('season', 'weekday', 'min_stay', 'length_discount')),
+    starts_on DATE,
+    ends_on DATE,
+    days_of_week INTEGER[],
+    nightly_price DECIMAL(10,2),
+    adjustment_percent INTEGER,
+    min_nights INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (ends_on >= starts_on)
+);
+
+CREATE INDEX IF NOT EXISTS pricing_rules_service_idx ON pricing_rules (service_id);
+
+-- Bookings lock in the price quoted when they were made or last changed.
+-- Existing bookings are priced at the flat nightly rate they were made with.
+ALTER TABLE bookings
+    ADD COLUMN total_amount BIGINT,
+    ADD COLUMN price_breakdown JSONB;
+
+UPDATE bookings b
+SET total_amount = ROUND(s.price * 100) * (b.check_out - b.check_in)
+FROM services s
+WHERE s.id = b.service_id;
+
+ALTER TABLE bookings ALTER COLUMN total_amount SET NOT NULL;
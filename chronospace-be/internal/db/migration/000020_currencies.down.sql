@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS exchange_rates;
+
+ALTER TABLE bookings DROP COLUMN IF EXISTS currency;
+ALTER TABLE services DROP COLUMN IF EXISTS currency;
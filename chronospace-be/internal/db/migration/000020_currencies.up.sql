@@ -0,0 +1,22 @@
+-- Prices of a service are in its currency; bookings lock in the currency
+-- along with the price
+ALTER TABLE services ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'EUR';
+
+ALTER TABLE bookings ADD COLUMN currency CHAR(3);
+
+UPDATE bookings b
+SET currency = s.currency
+FROM services s
+WHERE s.id = b.service_id;
+
+ALTER TABLE bookings ALTER COLUMN currency SET NOT NULL;
+
+-- One unit of base is worth rate units of quote
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate DECIMAL(20,10) NOT NULL CHECK (rate > 0),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (base, quote),
+    CHECK (base <> quote)
+);
@@ -0,0 +1,22 @@
+-- Invoice numbers run without gaps per provider; the counter row is locked
+-- while an invoice is issued
+CREATE TABLE IF NOT EXISTS invoice_counters (
+    provider_id UUID PRIMARY KEY REFERENCES users(id),
+    last_number INTEGER NOT NULL
+);
+
+-- An invoice is issued once per booking and keeps the amounts it was issued
+-- with. Amounts are in the minor unit of the currency and include tax.
+CREATE TABLE IF NOT EXISTS invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    booking_id UUID NOT NULL UNIQUE REFERENCES bookings(id),
+    provider_id UUID NOT NULL REFERENCES users(id),
+    number INTEGER NOT NULL,
+    currency CHAR(3) NOT NULL,
+    total_amount BIGINT NOT NULL,
+    tax_rate DECIMAL(5,2) NOT NULL,
+    tax_amount BIGINT NOT NULL,
+    lines JSONB NOT NULL,
+    issued_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider_id, number)
+);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS user_tokens_expires_idx;
@@ -0,0 +1,2 @@
+-- Expired refresh tokens are purged on sign-in
+CREATE INDEX IF NOT EXISTS user_tokens_expires_idx ON user_tokens (refresh_token_expires_at);
@@ -0,0 +1,21 @@
+-- Captures, refunds and releases are recorded with the booking change that
+-- causes them and sent to the provider after commit. The idempotency key is
+-- derived from the payment, so retries are applied by the provider once.
+CREATE TABLE IF NOT EXISTS payment_operations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('capture', 'refund', 'release')),
+    amount BIGINT NOT NULL CHECK (amount > 0),
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'succeeded', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS payment_operations_payment_idx ON payment_operations (payment_id);
+
+CREATE INDEX IF NOT EXISTS payment_operations_pending_idx ON payment_operations (updated_at)
+    WHERE status = 'pending';
@@ -0,0 +1,8 @@
+UPDATE payments
+SET status = 'refunded'
+WHERE status = 'partially_refunded';
+
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_status_check,
+    ADD CONSTRAINT payments_status_check
+        CHECK (status IN ('authorized', 'captured', 'refunded', 'voided', 'failed'));
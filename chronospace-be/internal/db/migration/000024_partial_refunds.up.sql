@@ -0,0 +1,9 @@
+-- Payments refunded in part are told apart from those refunded in full
+ALTER TABLE payments
+    DROP CONSTRAINT IF EXISTS payments_status_check,
+    ADD CONSTRAINT payments_status_check
+        CHECK (status IN ('authorized', 'captured', 'partially_refunded', 'refunded', 'voided', 'failed'));
+
+UPDATE payments
+SET status = 'partially_refunded'
+WHERE status = 'refunded' AND refunded_amount < captured_amount;
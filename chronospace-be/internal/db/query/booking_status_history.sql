@@ -0,0 +1,15 @@
+-- name: CreateBookingStatusHistory :one
+INSERT INTO booking_status_history (
+    booking_id,
+    from_status,
+    to_status,
+    changed_by,
+    reason
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListBookingStatusHistory :many
+SELECT * FROM booking_status_history
+WHERE booking_id = $1
+ORDER BY changed_at;
\ No newline at end of file
@@ -2,11 +2,18 @@
 INSERT INTO bookings (
     user_id,
     service_id,
-    date,
-    time,
-    status
+    check_in,
+    check_out,
+    check_in_time,
+    check_out_time,
+    status,
+    total_amount,
+    price_breakdown,
+    currency,
+    cancellation_policy
 ) VALUES (
-    $1, $2, $3, $4, $5
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+    (SELECT cancellation_policy FROM services WHERE id = $2)
 ) RETURNING *;
 
 -- name: GetBooking :one
@@ -15,19 +22,59 @@ WHERE id = $1;
 
 -- name: ListBookings :many
 SELECT * FROM bookings
-ORDER BY date, time;
+ORDER BY check_in, check_out;
 
 -- name: ListBookingsByUser :many
 SELECT * FROM bookings
 WHERE user_id = $1
-ORDER BY date, time;
+ORDER BY check_in, check_out;
+
+-- name: LockServiceForBooking :one
+SELECT id FROM services
+WHERE id = $1
+FOR UPDATE;
+
+-- name: CountOverlappingBookings :one
+SELECT COUNT(*) FROM bookings
+WHERE service_id = @service_id
+  AND check_in < @check_out
+  AND check_out > @check_in
+  AND status <> ALL(@inactive_statuses::text[])
+  AND (sqlc.narg(exclude_id)::uuid IS NULL OR id <> sqlc.narg(exclude_id));
+
+-- name: GetBookingForUpdate :one
+SELECT * FROM bookings
+WHERE id = $1
+FOR UPDATE;
+
+-- name: ListBookingsByService :many
+SELECT * FROM bookings
+WHERE service_id = $1
+ORDER BY check_in, check_out;
+
+-- name: ListBookingsByOwner :many
+SELECT b.* FROM bookings b
+JOIN services s ON s.id = b.service_id
+WHERE s.owner_id = @owner_id
+  AND (sqlc.narg(status)::text IS NULL OR b.status = sqlc.narg(status))
+ORDER BY b.check_in, b.check_out;
 
 -- name: UpdateBooking :one
 UPDATE bookings
 SET 
-    date = $2,
-    time = $3,
-    status = $4
+    check_in = $2,
+    check_out = $3,
+    check_in_time = $4,
+    check_out_time = $5,
+    total_amount = $6,
+    price_breakdown = $7,
+    currency = $8
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateBookingStatus :one
+UPDATE bookings
+SET status = $2
 WHERE id = $1
 RETURNING *;
 
@@ -0,0 +1,63 @@
+-- name: SetUserCalendarToken :exec
+UPDATE users
+SET calendar_token_hash = $2
+WHERE id = $1;
+
+-- name: GetUserByCalendarToken :one
+SELECT * FROM users
+WHERE calendar_token_hash = $1;
+
+-- name: CreateCalendarFeed :one
+INSERT INTO calendar_feeds (
+    service_id,
+    url
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetCalendarFeed :one
+SELECT * FROM calendar_feeds
+WHERE id = $1;
+
+-- name: ListCalendarFeeds :many
+SELECT * FROM calendar_feeds
+ORDER BY last_synced_at NULLS FIRST;
+
+-- name: ListCalendarFeedsByService :many
+SELECT * FROM calendar_feeds
+WHERE service_id = $1
+ORDER BY created_at;
+
+-- name: UpdateCalendarFeedSyncStatus :one
+UPDATE calendar_feeds
+SET last_synced_at = CURRENT_TIMESTAMP,
+    last_error = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: DeleteCalendarFeed :exec
+DELETE FROM calendar_feeds
+WHERE id = $1;
+
+-- name: DeleteFeedBlackouts :exec
+DELETE FROM schedule_blackouts
+WHERE feed_id = $1;
+
+-- name: CreateFeedBlackout :one
+INSERT INTO schedule_blackouts (
+    service_id,
+    starts_on,
+    ends_on,
+    reason,
+    feed_id,
+    external_uid
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListActiveBookingsByService :many
+SELECT * FROM bookings
+WHERE service_id = @service_id
+  AND check_out >= @since
+  AND status <> ALL(@inactive_statuses::text[])
+ORDER BY check_in;
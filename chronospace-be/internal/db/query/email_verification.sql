@@ -0,0 +1,33 @@
+-- name: CreateEmailVerificationToken :one
+INSERT INTO email_verification_tokens (
+    user_id,
+    token_hash,
+    expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetEmailVerificationToken :one
+SELECT * FROM email_verification_tokens
+WHERE token_hash = $1 AND used_at IS NULL;
+
+-- name: UseEmailVerificationToken :one
+UPDATE email_verification_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND used_at IS NULL
+RETURNING *;
+
+-- name: DeleteEmailVerificationTokensByUser :exec
+DELETE FROM email_verification_tokens
+WHERE user_id = $1;
+
+-- name: MarkUserEmailVerified :one
+UPDATE users
+SET email_verified_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: MarkUserEmailUnverified :exec
+UPDATE users
+SET email_verified_at = NULL
+WHERE id = $1;
@@ -0,0 +1,19 @@
+-- name: ListExchangeRates :many
+SELECT * FROM exchange_rates
+ORDER BY base, quote;
+
+-- name: UpsertExchangeRate :exec
+INSERT INTO exchange_rates (
+    base,
+    quote,
+    rate
+) VALUES (
+    $1, $2, $3
+)
+ON CONFLICT (base, quote) DO UPDATE
+SET rate = EXCLUDED.rate,
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteExchangeRatesByBase :exec
+DELETE FROM exchange_rates
+WHERE base = $1;
@@ -0,0 +1,28 @@
+-- name: NextInvoiceNumber :one
+INSERT INTO invoice_counters (
+    provider_id,
+    last_number
+) VALUES (
+    $1, 1
+)
+ON CONFLICT (provider_id) DO UPDATE
+SET last_number = invoice_counters.last_number + 1
+RETURNING last_number;
+
+-- name: CreateInvoice :one
+INSERT INTO invoices (
+    booking_id,
+    provider_id,
+    number,
+    currency,
+    total_amount,
+    tax_rate,
+    tax_amount,
+    lines
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetInvoiceByBooking :one
+SELECT * FROM invoices
+WHERE booking_id = $1;
@@ -0,0 +1,29 @@
+-- name: CreatePaymentOperation :one
+INSERT INTO payment_operations (
+    payment_id,
+    kind,
+    amount,
+    idempotency_key
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListPendingPaymentOperations :many
+SELECT * FROM payment_operations
+WHERE status = 'pending' AND updated_at <= $1
+ORDER BY created_at
+LIMIT $2;
+
+-- name: ListPendingPaymentOperationsByBooking :many
+SELECT o.* FROM payment_operations o
+JOIN payments p ON p.id = o.payment_id
+WHERE p.booking_id = $1 AND o.status = 'pending'
+ORDER BY o.created_at;
+
+-- name: UpdatePaymentOperationStatus :exec
+UPDATE payment_operations
+SET status = $2,
+    attempts = attempts + 1,
+    last_error = $3,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1;
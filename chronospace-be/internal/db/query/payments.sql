@@ -0,0 +1,50 @@
+-- name: CreatePayment :one
+INSERT INTO payments (
+    booking_id,
+    provider,
+    provider_payment_id,
+    amount,
+    currency,
+    status,
+    failure_reason
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetActivePaymentForUpdate :one
+SELECT * FROM payments
+WHERE booking_id = $1 AND status IN ('authorized', 'captured')
+FOR UPDATE;
+
+-- name: GetPayment :one
+SELECT * FROM payments
+WHERE id = $1;
+
+-- name: GetPaymentByProviderIDForUpdate :one
+SELECT * FROM payments
+WHERE provider = $1 AND provider_payment_id = $2
+FOR UPDATE;
+
+-- name: ListPaymentsByBooking :many
+SELECT * FROM payments
+WHERE booking_id = $1
+ORDER BY created_at;
+
+-- name: UpdatePaymentStatus :one
+UPDATE payments
+SET status = $2,
+    captured_amount = $3,
+    refunded_amount = $4,
+    failure_reason = $5,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordPaymentEvent :execrows
+INSERT INTO payment_events (
+    provider,
+    event_id,
+    type
+) VALUES (
+    $1, $2, $3
+) ON CONFLICT DO NOTHING;
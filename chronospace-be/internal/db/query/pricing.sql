@@ -0,0 +1,31 @@
+-- name: CreatePricingRule :one
+INSERT INTO pricing_rules (
+    service_id,
+    kind,
+    starts_on,
+    ends_on,
+    days_of_week,
+    nightly_price,
+    adjustment_percent,
+    min_nights
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetPricingRule :one
+SELECT * FROM pricing_rules
+WHERE id = $1;
+
+-- name: ListPricingRulesByService :many
+SELECT * FROM pricing_rules
+WHERE service_id = $1
+ORDER BY kind, starts_on NULLS FIRST, created_at;
+
+-- name: ListPricingRulesByServices :many
+SELECT * FROM pricing_rules
+WHERE service_id = ANY(@service_ids::uuid[])
+ORDER BY service_id, kind, starts_on NULLS FIRST, created_at;
+
+-- name: DeletePricingRule :exec
+DELETE FROM pricing_rules
+WHERE id = $1;
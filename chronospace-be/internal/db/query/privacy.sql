@@ -0,0 +1,31 @@
+-- name: EraseUser :one
+UPDATE users
+SET username = $2,
+    full_name = 'Deleted user',
+    email = $3,
+    password = '!',
+    calendar_token_hash = NULL,
+    email_verified_at = NULL,
+    failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL,
+    totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_step = 0,
+    erased_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND erased_at IS NULL
+RETURNING *;
+
+-- name: DeleteUserIdentities :exec
+DELETE FROM user_identities
+WHERE user_id = $1;
+
+-- name: CountUpcomingBookingsByUser :one
+SELECT COUNT(*) FROM bookings
+WHERE user_id = @user_id
+  AND status = ANY(@statuses::text[])
+  AND check_out > @today;
+
+-- name: CountServicesByOwner :one
+SELECT COUNT(*) FROM services
+WHERE owner_id = $1;
@@ -0,0 +1,78 @@
+-- name: CreateScheduleRule :one
+INSERT INTO schedule_rules (
+    service_id,
+    starts_on,
+    time_start,
+    time_end,
+    rrule,
+    status
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetScheduleRule :one
+SELECT * FROM schedule_rules
+WHERE id = $1;
+
+-- name: ListScheduleRulesByService :many
+SELECT * FROM schedule_rules
+WHERE service_id = $1
+ORDER BY starts_on, time_start;
+
+-- name: ListScheduleRulesByServices :many
+SELECT * FROM schedule_rules
+WHERE service_id = ANY(@service_ids::uuid[])
+ORDER BY starts_on, time_start;
+
+-- name: DeleteScheduleRule :exec
+DELETE FROM schedule_rules
+WHERE id = $1;
+
+-- name: CreateScheduleRuleException :one
+INSERT INTO schedule_rule_exceptions (
+    rule_id,
+    date
+) VALUES (
+    $1, $2
+)
+ON CONFLICT (rule_id, date) DO UPDATE SET date = EXCLUDED.date
+RETURNING *;
+
+-- name: ListScheduleRuleExceptions :many
+SELECT * FROM schedule_rule_exceptions
+WHERE rule_id = ANY(@rule_ids::uuid[])
+ORDER BY date;
+
+-- name: DeleteScheduleRuleException :exec
+DELETE FROM schedule_rule_exceptions
+WHERE rule_id = $1 AND date = $2;
+
+-- name: CreateScheduleBlackout :one
+INSERT INTO schedule_blackouts (
+    service_id,
+    starts_on,
+    ends_on,
+    reason
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetScheduleBlackout :one
+SELECT * FROM schedule_blackouts
+WHERE id = $1;
+
+-- name: ListScheduleBlackoutsByService :many
+SELECT * FROM schedule_blackouts
+WHERE service_id = $1
+ORDER BY starts_on;
+
+-- name: ListScheduleBlackoutsInWindow :many
+SELECT * FROM schedule_blackouts
+WHERE service_id = ANY(@service_ids::uuid[])
+  AND starts_on < @window_end
+  AND ends_on >= @window_start
+ORDER BY starts_on;
+
+-- name: DeleteScheduleBlackout :exec
+DELETE FROM schedule_blackouts
+WHERE id = $1;
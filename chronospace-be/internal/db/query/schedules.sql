@@ -22,6 +22,13 @@ SELECT * FROM schedules
 WHERE service_id = $1
 ORDER BY date, time_start;
 
+-- name: ListSchedulesInWindow :many
+SELECT * FROM schedules
+WHERE service_id = ANY(@service_ids::uuid[])
+  AND date >= @window_start
+  AND date < @window_end
+ORDER BY date, time_start;
+
 -- name: UpdateSchedule :one
 UPDATE schedules
 SET 
@@ -3,9 +3,13 @@ INSERT INTO services (
     name,
     description,
     location, 
-    price
+    price,
+    owner_id,
+    max_guests,
+    cancellation_policy,
+    currency
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5, $6, $7, $8
 ) RETURNING *;
 
 -- name: GetService :one
@@ -16,12 +20,34 @@ WHERE id = $1;
 SELECT * FROM services
 ORDER BY name;
 
+-- name: ListServicesByOwner :many
+SELECT * FROM services
+WHERE owner_id = $1
+ORDER BY name;
+
+-- name: SearchAvailableServices :many
+SELECT s.*
+FROM services s
+WHERE (sqlc.narg(location)::text IS NULL OR s.location ILIKE '%' || sqlc.narg(location) || '%')
+  AND s.max_guests >= sqlc.arg(guests)::int
+  AND NOT EXISTS (
+    SELECT 1 FROM bookings b
+    WHERE b.service_id = s.id
+      AND b.check_in < sqlc.arg(check_out)
+      AND b.check_out > sqlc.arg(check_in)
+      AND b.status <> ALL(sqlc.arg(inactive_statuses)::text[])
+  )
+ORDER BY s.name, s.id;
+
 -- name: UpdateService :one
 UPDATE services
 SET name = $2,
     description = $3,
     location = $4,
-    price = $5
+    price = $5,
+    max_guests = $6,
+    cancellation_policy = $7,
+    currency = $8
 WHERE id = $1
 RETURNING *;
 
@@ -2,9 +2,13 @@
 INSERT INTO user_tokens (
     user_id,
     refresh_token,
-    refresh_token_expires_at
+    refresh_token_expires_at,
+    session_id,
+    session_started_at,
+    user_agent,
+    ip_address
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5, $6, $7
 ) RETURNING *;
 
 -- name: GetUserTokenByID :one
@@ -27,6 +31,12 @@ SET
 WHERE id = $1
 RETURNING *;
 
+-- name: RotateUserToken :one
+UPDATE user_tokens
+SET rotated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND rotated_at IS NULL
+RETURNING *;
+
 -- name: DeleteUserToken :exec
 DELETE FROM user_tokens
 WHERE id = $1;
@@ -35,6 +45,33 @@ WHERE id = $1;
 DELETE FROM user_tokens
 WHERE user_id = $1;
 
+-- name: ListUserSessions :many
+SELECT * FROM user_tokens
+WHERE user_id = $1
+    AND rotated_at IS NULL
+    AND refresh_token_expires_at > CURRENT_TIMESTAMP
+ORDER BY created_at DESC;
+
+-- name: DeleteUserSession :execrows
+DELETE FROM user_tokens
+WHERE user_id = $1 AND session_id = $2;
+
+-- name: DeleteOtherUserSessions :exec
+DELETE FROM user_tokens
+WHERE user_id = $1 AND session_id <> $2;
+
+-- name: PruneUserSessions :exec
+DELETE FROM user_tokens
+WHERE user_id = sqlc.arg(user_id)
+    AND session_id IN (
+        SELECT session_id FROM user_tokens
+        WHERE user_id = sqlc.arg(user_id)
+            AND rotated_at IS NULL
+            AND refresh_token_expires_at > CURRENT_TIMESTAMP
+        ORDER BY created_at DESC
+        OFFSET sqlc.arg(keep)
+    );
+
 -- name: DeleteExpiredTokens :exec
 DELETE FROM user_tokens
 WHERE refresh_token_expires_at < CURRENT_TIMESTAMP;
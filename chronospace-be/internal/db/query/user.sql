@@ -3,9 +3,10 @@ INSERT INTO users (
     username,
     full_name,
     email,
-    password
+    password,
+    role
 ) VALUES (
-    $1, $2, $3, $4
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetUser :one
@@ -31,8 +32,7 @@ UPDATE users
 SET 
     username = COALESCE($2, username),
     full_name = COALESCE($3, full_name),
-    email = COALESCE($4, email),
-    password = COALESCE($5, password)
+    email = COALESCE($4, email)
 WHERE id = $1
 RETURNING *;
 
@@ -44,4 +44,31 @@ WHERE id = $1;
 UPDATE users
 SET password = $2
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_login_attempts = failed_login_attempts + 1,
+    last_failed_login_at = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: LockUser :exec
+UPDATE users
+SET failed_login_attempts = 0,
+    locked_until = $2
+WHERE id = $1;
+
+-- name: UnlockUser :one
+UPDATE users
+SET failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL
+WHERE id = $1
+RETURNING *;
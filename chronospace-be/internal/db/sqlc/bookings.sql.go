@@ -11,42 +11,95 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countOverlappingBookings = `-- name: CountOverlappingBookings :one
+SELECT COUNT(*) FROM bookings
+WHERE service_id = $1
+  AND check_in < $2
+  AND check_out > $3
+  AND status <> ALL($4::text[])
+  AND ($5::uuid IS NULL OR id <> $5)
+`
+
+type CountOverlappingBookingsParams struct {
+	ServiceID        pgtype.UUID `json:"service_id"`
+	CheckOut         pgtype.Date `json:"check_out"`
+	CheckIn          pgtype.Date `json:"check_in"`
+	InactiveStatuses []string    `json:"inactive_statuses"`
+	ExcludeID        pgtype.UUID `json:"exclude_id"`
+}
+
+func (q *Queries) CountOverlappingBookings(ctx context.Context, arg CountOverlappingBookingsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countOverlappingBookings,
+		arg.ServiceID,
+		arg.CheckOut,
+		arg.CheckIn,
+		arg.InactiveStatuses,
+		arg.ExcludeID,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createBooking = `-- name: CreateBooking :one
 INSERT INTO bookings (
     user_id,
     service_id,
-    date,
-    time,
-    status
+    check_in,
+    check_out,
+    check_in_time,
+    check_out_time,
+    status,
+    total_amount,
+    price_breakdown,
+    currency,
+    cancellation_policy
 ) VALUES (
-    $1, $2, $3, $4, $5
-) RETURNING id, user_id, service_id, date, time, status
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10,
+    (SELECT cancellation_policy FROM services WHERE id = $2)
+) RETURNING id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency
 `
 
 type CreateBookingParams struct {
-	UserID    pgtype.UUID `json:"user_id"`
-	ServiceID pgtype.UUID `json:"service_id"`
-	Date      pgtype.Date `json:"date"`
-	Time      pgtype.Time `json:"time"`
-	Status    string      `json:"status"`
+	UserID         pgtype.UUID `json:"user_id"`
+	ServiceID      pgtype.UUID `json:"service_id"`
+	CheckIn        pgtype.Date `json:"check_in"`
+	CheckOut       pgtype.Date `json:"check_out"`
+	CheckInTime    pgtype.Time `json:"check_in_time"`
+	CheckOutTime   pgtype.Time `json:"check_out_time"`
+	Status         string      `json:"status"`
+	TotalAmount    int64       `json:"total_amount"`
+	PriceBreakdown []byte      `json:"price_breakdown"`
+	Currency       string      `json:"currency"`
 }
 
 func (q *Queries) CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error) {
 	row := q.db.QueryRow(ctx, createBooking,
 		arg.UserID,
 		arg.ServiceID,
-		arg.Date,
-		arg.Time,
+		arg.CheckIn,
+		arg.CheckOut,
+		arg.CheckInTime,
+		arg.CheckOutTime,
 		arg.Status,
+		arg.TotalAmount,
+		arg.PriceBreakdown,
+		arg.Currency,
 	)
 	var i Booking
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.ServiceID,
-		&i.Date,
-		&i.Time,
 		&i.Status,
+		&i.CheckIn,
+		&i.CheckOut,
+		&i.CheckInTime,
+		&i.CheckOutTime,
+		&i.CancellationPolicy,
+		&i.TotalAmount,
+		&i.PriceBreakdown,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -62,7 +115,7 @@ func (q *Queries) DeleteBooking(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getBooking = `-- name: GetBooking :one
-SELECT id, user_id, service_id, date, time, status FROM bookings
+SELECT id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency FROM bookings
 WHERE id = $1
 `
 
@@ -73,16 +126,48 @@ func (q *Queries) GetBooking(ctx context.Context, id pgtype.UUID) (Booking, erro
 		&i.ID,
 		&i.UserID,
 		&i.ServiceID,
-		&i.Date,
-		&i.Time,
 		&i.Status,
+		&i.CheckIn,
+		&i.CheckOut,
+		&i.CheckInTime,
+		&i.CheckOutTime,
+		&i.CancellationPolicy,
+		&i.TotalAmount,
+		&i.PriceBreakdown,
+		&i.Currency,
+	)
+	return i, err
+}
+
+const getBookingForUpdate = `-- name: GetBookingForUpdate :one
+SELECT id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency FROM bookings
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) GetBookingForUpdate(ctx context.Context, id pgtype.UUID) (Booking, error) {
+	row := q.db.QueryRow(ctx, getBookingForUpdate, id)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ServiceID,
+		&i.Status,
+		&i.CheckIn,
+		&i.CheckOut,
+		&i.CheckInTime,
+		&i.CheckOutTime,
+		&i.CancellationPolicy,
+		&i.TotalAmount,
+		&i.PriceBreakdown,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const listBookings = `-- name: ListBookings :many
-SELECT id, user_id, service_id, date, time, status FROM bookings
-ORDER BY date, time
+SELECT id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency FROM bookings
+ORDER BY check_in, check_out
 `
 
 func (q *Queries) ListBookings(ctx context.Context) ([]Booking, error) {
@@ -98,9 +183,100 @@ func (q *Queries) ListBookings(ctx context.Context) ([]Booking, error) {
 			&i.ID,
 			&i.UserID,
 			&i.ServiceID,
-			&i.Date,
-			&i.Time,
 			&i.Status,
+			&i.CheckIn,
+			&i.CheckOut,
+			&i.CheckInTime,
+			&i.CheckOutTime,
+			&i.CancellationPolicy,
+			&i.TotalAmount,
+			&i.PriceBreakdown,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingsByOwner = `-- name: ListBookingsByOwner :many
+SELECT b.id, b.user_id, b.service_id, b.status, b.check_in, b.check_out, b.check_in_time, b.check_out_time, b.cancellation_policy, b.total_amount, b.price_breakdown, b.currency FROM bookings b
+JOIN services s ON s.id = b.service_id
+WHERE s.owner_id = $1
+  AND ($2::text IS NULL OR b.status = $2)
+ORDER BY b.check_in, b.check_out
+`
+
+type ListBookingsByOwnerParams struct {
+	OwnerID pgtype.UUID `json:"owner_id"`
+	Status  pgtype.Text `json:"status"`
+}
+
+func (q *Queries) ListBookingsByOwner(ctx context.Context, arg ListBookingsByOwnerParams) ([]Booking, error) {
+	rows, err := q.db.Query(ctx, listBookingsByOwner, arg.OwnerID, arg.Status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Booking{}
+	for rows.Next() {
+		var i Booking
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ServiceID,
+			&i.Status,
+			&i.CheckIn,
+			&i.CheckOut,
+			&i.CheckInTime,
+			&i.CheckOutTime,
+			&i.CancellationPolicy,
+			&i.TotalAmount,
+			&i.PriceBreakdown,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listBookingsByService = `-- name: ListBookingsByService :many
+SELECT id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency FROM bookings
+WHERE service_id = $1
+ORDER BY check_in, check_out
+`
+
+func (q *Queries) ListBookingsByService(ctx context.Context, serviceID pgtype.UUID) ([]Booking, error) {
+	rows, err := q.db.Query(ctx, listBookingsByService, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Booking{}
+	for rows.Next() {
+		var i Booking
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ServiceID,
+			&i.Status,
+			&i.CheckIn,
+			&i.CheckOut,
+			&i.CheckInTime,
+			&i.CheckOutTime,
+			&i.CancellationPolicy,
+			&i.TotalAmount,
+			&i.PriceBreakdown,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -113,9 +289,9 @@ func (q *Queries) ListBookings(ctx context.Context) ([]Booking, error) {
 }
 
 const listBookingsByUser = `-- name: ListBookingsByUser :many
-SELECT id, user_id, service_id, date, time, status FROM bookings
+SELECT id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency FROM bookings
 WHERE user_id = $1
-ORDER BY date, time
+ORDER BY check_in, check_out
 `
 
 func (q *Queries) ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]Booking, error) {
@@ -131,9 +307,15 @@ func (q *Queries) ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([
 			&i.ID,
 			&i.UserID,
 			&i.ServiceID,
-			&i.Date,
-			&i.Time,
 			&i.Status,
+			&i.CheckIn,
+			&i.CheckOut,
+			&i.CheckInTime,
+			&i.CheckOutTime,
+			&i.CancellationPolicy,
+			&i.TotalAmount,
+			&i.PriceBreakdown,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -145,38 +327,100 @@ func (q *Queries) ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([
 	return items, nil
 }
 
+const lockServiceForBooking = `-- name: LockServiceForBooking :one
+SELECT id FROM services
+WHERE id = $1
+FOR UPDATE
+`
+
+func (q *Queries) LockServiceForBooking(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, lockServiceForBooking, id)
+	err := row.Scan(&id)
+	return id, err
+}
+
 const updateBooking = `-- name: UpdateBooking :one
 UPDATE bookings
 SET 
-    date = $2,
-    time = $3,
-    status = $4
+    check_in = $2,
+    check_out = $3,
+    check_in_time = $4,
+    check_out_time = $5,
+    total_amount = $6,
+    price_breakdown = $7,
+    currency = $8
 WHERE id = $1
-RETURNING id, user_id, service_id, date, time, status
+RETURNING id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency
 `
 
 type UpdateBookingParams struct {
-	ID     pgtype.UUID `json:"id"`
-	Date   pgtype.Date `json:"date"`
-	Time   pgtype.Time `json:"time"`
-	Status string      `json:"status"`
+	ID             pgtype.UUID `json:"id"`
+	CheckIn        pgtype.Date `json:"check_in"`
+	CheckOut       pgtype.Date `json:"check_out"`
+	CheckInTime    pgtype.Time `json:"check_in_time"`
+	CheckOutTime   pgtype.Time `json:"check_out_time"`
+	TotalAmount    int64       `json:"total_amount"`
+	PriceBreakdown []byte      `json:"price_breakdown"`
+	Currency       string      `json:"currency"`
 }
 
 func (q *Queries) UpdateBooking(ctx context.Context, arg UpdateBookingParams) (Booking, error) {
 	row := q.db.QueryRow(ctx, updateBooking,
 		arg.ID,
-		arg.Date,
-		arg.Time,
-		arg.Status,
+		arg.CheckIn,
+		arg.CheckOut,
+		arg.CheckInTime,
+		arg.CheckOutTime,
+		arg.TotalAmount,
+		arg.PriceBreakdown,
+		arg.Currency,
+	)
+	var i Booking
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ServiceID,
+		&i.Status,
+		&i.CheckIn,
+		&i.CheckOut,
+		&i.CheckInTime,
+		&i.CheckOutTime,
+		&i.CancellationPolicy,
+		&i.TotalAmount,
+		&i.PriceBreakdown,
+		&i.Currency,
 	)
+	return i, err
+}
+
+const updateBookingStatus = `-- name: UpdateBookingStatus :one
+UPDATE bookings
+SET status = $2
+WHERE id = $1
+RETURNING id, user_id, service_id, status, check_in, check_out, check_in_time, check_out_time, cancellation_policy, total_amount, price_breakdown, currency
+`
+
+type UpdateBookingStatusParams struct {
+	ID     pgtype.UUID `json:"id"`
+	Status string      `json:"status"`
+}
+
+func (q *Queries) UpdateBookingStatus(ctx context.Context, arg UpdateBookingStatusParams) (Booking, error) {
+	row := q.db.QueryRow(ctx, updateBookingStatus, arg.ID, arg.Status)
 	var i Booking
 	err := row.Scan(
 		&i.ID,
 		&i.UserID,
 		&i.ServiceID,
-		&i.Date,
-		&i.Time,
 		&i.Status,
+		&i.CheckIn,
+		&i.CheckOut,
+		&i.CheckInTime,
+		&i.CheckOutTime,
+		&i.CancellationPolicy,
+		&i.TotalAmount,
+		&i.PriceBreakdown,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -9,12 +9,146 @@ import (
 )
 
 type Booking struct {
-	ID        pgtype.UUID `json:"id"`
-	UserID    pgtype.UUID `json:"user_id"`
-	ServiceID pgtype.UUID `json:"service_id"`
-	Date      pgtype.Date `json:"date"`
-	Time      pgtype.Time `json:"time"`
-	Status    string      `json:"status"`
+	ID                 pgtype.UUID `json:"id"`
+	UserID             pgtype.UUID `json:"user_id"`
+	ServiceID          pgtype.UUID `json:"service_id"`
+	Status             string      `json:"status"`
+	CheckIn            pgtype.Date `json:"check_in"`
+	CheckOut           pgtype.Date `json:"check_out"`
+	CheckInTime        pgtype.Time `json:"check_in_time"`
+	CheckOutTime       pgtype.Time `json:"check_out_time"`
+	CancellationPolicy []byte      `json:"cancellation_policy"`
+	TotalAmount        int64       `json:"total_amount"`
+	PriceBreakdown     []byte      `json:"price_breakdown"`
+	Currency           string      `json:"currency"`
+}
+
+type BookingStatusHistory struct {
+	ID         pgtype.UUID      `json:"id"`
+	BookingID  pgtype.UUID      `json:"booking_id"`
+	FromStatus pgtype.Text      `json:"from_status"`
+	ToStatus   string           `json:"to_status"`
+	ChangedBy  pgtype.UUID      `json:"changed_by"`
+	Reason     pgtype.Text      `json:"reason"`
+	ChangedAt  pgtype.Timestamp `json:"changed_at"`
+}
+
+type CalendarFeed struct {
+	ID           pgtype.UUID      `json:"id"`
+	ServiceID    pgtype.UUID      `json:"service_id"`
+	Url          string           `json:"url"`
+	LastSyncedAt pgtype.Timestamp `json:"last_synced_at"`
+	LastError    pgtype.Text      `json:"last_error"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type EmailVerificationToken struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ExchangeRate struct {
+	Base      string           `json:"base"`
+	Quote     string           `json:"quote"`
+	Rate      pgtype.Numeric   `json:"rate"`
+	UpdatedAt pgtype.Timestamp `json:"updated_at"`
+}
+
+type Invoice struct {
+	ID          pgtype.UUID      `json:"id"`
+	BookingID   pgtype.UUID      `json:"booking_id"`
+	ProviderID  pgtype.UUID      `json:"provider_id"`
+	Number      int32            `json:"number"`
+	Currency    string           `json:"currency"`
+	TotalAmount int64            `json:"total_amount"`
+	TaxRate     pgtype.Numeric   `json:"tax_rate"`
+	TaxAmount   int64            `json:"tax_amount"`
+	Lines       []byte           `json:"lines"`
+	IssuedAt    pgtype.Timestamp `json:"issued_at"`
+}
+
+type InvoiceCounter struct {
+	ProviderID pgtype.UUID `json:"provider_id"`
+	LastNumber int32       `json:"last_number"`
+}
+
+type MfaRecoveryCode struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	CodeHash  string           `json:"code_hash"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type OidcLoginState struct {
+	ID           pgtype.UUID      `json:"id"`
+	StateHash    string           `json:"state_hash"`
+	Provider     string           `json:"provider"`
+	Nonce        string           `json:"nonce"`
+	CodeVerifier string           `json:"code_verifier"`
+	ExpiresAt    pgtype.Timestamp `json:"expires_at"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
+type PasswordResetToken struct {
+	ID        pgtype.UUID      `json:"id"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	TokenHash string           `json:"token_hash"`
+	ExpiresAt pgtype.Timestamp `json:"expires_at"`
+	UsedAt    pgtype.Timestamp `json:"used_at"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type Payment struct {
+	ID                pgtype.UUID      `json:"id"`
+	BookingID         pgtype.UUID      `json:"booking_id"`
+	Provider          string           `json:"provider"`
+	ProviderPaymentID pgtype.Text      `json:"provider_payment_id"`
+	Amount            int64            `json:"amount"`
+	Currency          string           `json:"currency"`
+	Status            string           `json:"status"`
+	CapturedAmount    int64            `json:"captured_amount"`
+	RefundedAmount    int64            `json:"refunded_amount"`
+	FailureReason     pgtype.Text      `json:"failure_reason"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
+type PaymentEvent struct {
+	Provider   string           `json:"provider"`
+	EventID    string           `json:"event_id"`
+	Type       string           `json:"type"`
+	ReceivedAt pgtype.Timestamp `json:"received_at"`
+}
+
+type PaymentOperation struct {
+	ID             pgtype.UUID      `json:"id"`
+	PaymentID      pgtype.UUID      `json:"payment_id"`
+	Kind           string           `json:"kind"`
+	Amount         int64            `json:"amount"`
+	IdempotencyKey string           `json:"idempotency_key"`
+	Status         string           `json:"status"`
+	Attempts       int32            `json:"attempts"`
+	LastError      pgtype.Text      `json:"last_error"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+type PricingRule struct {
+	ID                pgtype.UUID      `json:"id"`
+	ServiceID         pgtype.UUID      `json:"service_id"`
+	Kind              string           `json:"kind"`
+	StartsOn          pgtype.Date      `json:"starts_on"`
+	EndsOn            pgtype.Date      `json:"ends_on"`
+	DaysOfWeek        []int32          `json:"days_of_week"`
+	NightlyPrice      pgtype.Numeric   `json:"nightly_price"`
+	AdjustmentPercent pgtype.Int4      `json:"adjustment_percent"`
+	MinNights         pgtype.Int4      `json:"min_nights"`
+	CreatedAt         pgtype.Timestamp `json:"created_at"`
 }
 
 type Schedule struct {
@@ -26,20 +160,71 @@ type Schedule struct {
 	Status    string      `json:"status"`
 }
 
+type ScheduleBlackout struct {
+	ID          pgtype.UUID      `json:"id"`
+	ServiceID   pgtype.UUID      `json:"service_id"`
+	StartsOn    pgtype.Date      `json:"starts_on"`
+	EndsOn      pgtype.Date      `json:"ends_on"`
+	Reason      pgtype.Text      `json:"reason"`
+	CreatedAt   pgtype.Timestamp `json:"created_at"`
+	FeedID      pgtype.UUID      `json:"feed_id"`
+	ExternalUid pgtype.Text      `json:"external_uid"`
+}
+
+type ScheduleRule struct {
+	ID        pgtype.UUID      `json:"id"`
+	ServiceID pgtype.UUID      `json:"service_id"`
+	StartsOn  pgtype.Date      `json:"starts_on"`
+	TimeStart pgtype.Time      `json:"time_start"`
+	TimeEnd   pgtype.Time      `json:"time_end"`
+	Rrule     string           `json:"rrule"`
+	Status    string           `json:"status"`
+	CreatedAt pgtype.Timestamp `json:"created_at"`
+}
+
+type ScheduleRuleException struct {
+	ID     pgtype.UUID `json:"id"`
+	RuleID pgtype.UUID `json:"rule_id"`
+	Date   pgtype.Date `json:"date"`
+}
+
 type Service struct {
-	ID          pgtype.UUID    `json:"id"`
-	Name        string         `json:"name"`
-	Description pgtype.Text    `json:"description"`
-	Location    string         `json:"location"`
-	Price       pgtype.Numeric `json:"price"`
+	ID                 pgtype.UUID    `json:"id"`
+	Name               string         `json:"name"`
+	Description        pgtype.Text    `json:"description"`
+	Location           string         `json:"location"`
+	Price              pgtype.Numeric `json:"price"`
+	OwnerID            pgtype.UUID    `json:"owner_id"`
+	MaxGuests          int32          `json:"max_guests"`
+	CancellationPolicy []byte         `json:"cancellation_policy"`
+	Currency           string         `json:"currency"`
 }
 
 type User struct {
+	ID                  pgtype.UUID      `json:"id"`
+	Username            string           `json:"username"`
+	FullName            string           `json:"full_name"`
+	Email               string           `json:"email"`
+	Password            string           `json:"password"`
+	CreatedAt           pgtype.Timestamp `json:"created_at"`
+	Role                string           `json:"role"`
+	CalendarTokenHash   pgtype.Text      `json:"calendar_token_hash"`
+	EmailVerifiedAt     pgtype.Timestamp `json:"email_verified_at"`
+	FailedLoginAttempts int32            `json:"failed_login_attempts"`
+	LastFailedLoginAt   pgtype.Timestamp `json:"last_failed_login_at"`
+	LockedUntil         pgtype.Timestamp `json:"locked_until"`
+	TotpSecret          pgtype.Text      `json:"totp_secret"`
+	TotpEnabledAt       pgtype.Timestamp `json:"totp_enabled_at"`
+	TotpLastStep        int64            `json:"totp_last_step"`
+	ErasedAt            pgtype.Timestamp `json:"erased_at"`
+}
+
+type UserIdentity struct {
 	ID        pgtype.UUID      `json:"id"`
-	Username  string           `json:"username"`
-	FullName  string           `json:"full_name"`
-	Email     string           `json:"email"`
-	Password  string           `json:"password"`
+	UserID    pgtype.UUID      `json:"user_id"`
+	Provider  string           `json:"provider"`
+	Subject   string           `json:"subject"`
+	Email     pgtype.Text      `json:"email"`
 	CreatedAt pgtype.Timestamp `json:"created_at"`
 }
 
@@ -49,4 +234,9 @@ type UserToken struct {
 	RefreshToken          string           `json:"refresh_token"`
 	RefreshTokenExpiresAt pgtype.Timestamp `json:"refresh_token_expires_at"`
 	CreatedAt             pgtype.Timestamp `json:"created_at"`
+	RotatedAt             pgtype.Timestamp `json:"rotated_at"`
+	SessionID             pgtype.UUID      `json:"session_id"`
+	SessionStartedAt      pgtype.Timestamp `json:"session_started_at"`
+	UserAgent             pgtype.Text      `json:"user_agent"`
+	IpAddress             pgtype.Text      `json:"ip_address"`
 }
@@ -11,40 +11,135 @@ import (
 )
 
 type Querier interface {
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (OidcLoginState, error)
+	CountOverlappingBookings(ctx context.Context, arg CountOverlappingBookingsParams) (int64, error)
+	CountServicesByOwner(ctx context.Context, ownerID pgtype.UUID) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountUpcomingBookingsByUser(ctx context.Context, arg CountUpcomingBookingsByUserParams) (int64, error)
 	CountUserTokens(ctx context.Context, userID pgtype.UUID) (int64, error)
 	CreateBooking(ctx context.Context, arg CreateBookingParams) (Booking, error)
+	CreateBookingStatusHistory(ctx context.Context, arg CreateBookingStatusHistoryParams) (BookingStatusHistory, error)
+	CreateCalendarFeed(ctx context.Context, arg CreateCalendarFeedParams) (CalendarFeed, error)
+	CreateEmailVerificationToken(ctx context.Context, arg CreateEmailVerificationTokenParams) (EmailVerificationToken, error)
+	CreateFeedBlackout(ctx context.Context, arg CreateFeedBlackoutParams) (ScheduleBlackout, error)
+	CreateInvoice(ctx context.Context, arg CreateInvoiceParams) (Invoice, error)
+	CreateOIDCLoginState(ctx context.Context, arg CreateOIDCLoginStateParams) error
+	CreatePasswordResetToken(ctx context.Context, arg CreatePasswordResetTokenParams) (PasswordResetToken, error)
+	CreatePayment(ctx context.Context, arg CreatePaymentParams) (Payment, error)
+	CreatePaymentOperation(ctx context.Context, arg CreatePaymentOperationParams) (PaymentOperation, error)
+	CreatePricingRule(ctx context.Context, arg CreatePricingRuleParams) (PricingRule, error)
+	CreateRecoveryCode(ctx context.Context, arg CreateRecoveryCodeParams) error
 	CreateSchedule(ctx context.Context, arg CreateScheduleParams) (Schedule, error)
+	CreateScheduleBlackout(ctx context.Context, arg CreateScheduleBlackoutParams) (ScheduleBlackout, error)
+	CreateScheduleRule(ctx context.Context, arg CreateScheduleRuleParams) (ScheduleRule, error)
+	CreateScheduleRuleException(ctx context.Context, arg CreateScheduleRuleExceptionParams) (ScheduleRuleException, error)
 	CreateService(ctx context.Context, arg CreateServiceParams) (Service, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateUserIdentity(ctx context.Context, arg CreateUserIdentityParams) (UserIdentity, error)
 	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error)
 	DeleteBooking(ctx context.Context, id pgtype.UUID) error
+	DeleteCalendarFeed(ctx context.Context, id pgtype.UUID) error
+	DeleteEmailVerificationTokensByUser(ctx context.Context, userID pgtype.UUID) error
+	DeleteExchangeRatesByBase(ctx context.Context, base string) error
+	DeleteExpiredOIDCLoginStates(ctx context.Context) error
 	DeleteExpiredTokens(ctx context.Context) error
+	DeleteFeedBlackouts(ctx context.Context, feedID pgtype.UUID) error
+	DeleteOtherUserSessions(ctx context.Context, arg DeleteOtherUserSessionsParams) error
+	DeletePasswordResetTokensByUser(ctx context.Context, userID pgtype.UUID) error
+	DeletePricingRule(ctx context.Context, id pgtype.UUID) error
+	DeleteRecoveryCodes(ctx context.Context, userID pgtype.UUID) error
 	DeleteSchedule(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleBlackout(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleRule(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleRuleException(ctx context.Context, arg DeleteScheduleRuleExceptionParams) error
 	DeleteService(ctx context.Context, id pgtype.UUID) error
 	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	DeleteUserIdentities(ctx context.Context, userID pgtype.UUID) error
+	DeleteUserIdentity(ctx context.Context, arg DeleteUserIdentityParams) (int64, error)
+	DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) (int64, error)
 	DeleteUserToken(ctx context.Context, id pgtype.UUID) error
 	DeleteUserTokensByUserID(ctx context.Context, userID pgtype.UUID) error
+	DisableUserTOTP(ctx context.Context, id pgtype.UUID) error
+	EnableUserTOTP(ctx context.Context, arg EnableUserTOTPParams) (User, error)
+	EraseUser(ctx context.Context, arg EraseUserParams) (User, error)
+	GetActivePaymentForUpdate(ctx context.Context, bookingID pgtype.UUID) (Payment, error)
 	GetBooking(ctx context.Context, id pgtype.UUID) (Booking, error)
+	GetBookingForUpdate(ctx context.Context, id pgtype.UUID) (Booking, error)
+	GetCalendarFeed(ctx context.Context, id pgtype.UUID) (CalendarFeed, error)
+	GetEmailVerificationToken(ctx context.Context, tokenHash string) (EmailVerificationToken, error)
+	GetInvoiceByBooking(ctx context.Context, bookingID pgtype.UUID) (Invoice, error)
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (PasswordResetToken, error)
+	GetPayment(ctx context.Context, id pgtype.UUID) (Payment, error)
+	GetPaymentByProviderIDForUpdate(ctx context.Context, arg GetPaymentByProviderIDForUpdateParams) (Payment, error)
+	GetPricingRule(ctx context.Context, id pgtype.UUID) (PricingRule, error)
+	GetScheduleBlackout(ctx context.Context, id pgtype.UUID) (ScheduleBlackout, error)
 	GetScheduleByID(ctx context.Context, id pgtype.UUID) (Schedule, error)
+	GetScheduleRule(ctx context.Context, id pgtype.UUID) (ScheduleRule, error)
 	GetService(ctx context.Context, id pgtype.UUID) (Service, error)
 	GetUser(ctx context.Context, id pgtype.UUID) (User, error)
+	GetUserByCalendarToken(ctx context.Context, calendarTokenHash pgtype.Text) (User, error)
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 	GetUserByUsername(ctx context.Context, username string) (User, error)
+	GetUserIdentity(ctx context.Context, arg GetUserIdentityParams) (UserIdentity, error)
 	GetUserTokenByID(ctx context.Context, id pgtype.UUID) (UserToken, error)
 	GetUserTokenByRefreshToken(ctx context.Context, refreshToken string) (UserToken, error)
 	GetUserTokensByUserID(ctx context.Context, userID pgtype.UUID) ([]UserToken, error)
+	ListActiveBookingsByService(ctx context.Context, arg ListActiveBookingsByServiceParams) ([]Booking, error)
+	ListBookingStatusHistory(ctx context.Context, bookingID pgtype.UUID) ([]BookingStatusHistory, error)
 	ListBookings(ctx context.Context) ([]Booking, error)
+	ListBookingsByOwner(ctx context.Context, arg ListBookingsByOwnerParams) ([]Booking, error)
+	ListBookingsByService(ctx context.Context, serviceID pgtype.UUID) ([]Booking, error)
 	ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]Booking, error)
+	ListCalendarFeeds(ctx context.Context) ([]CalendarFeed, error)
+	ListCalendarFeedsByService(ctx context.Context, serviceID pgtype.UUID) ([]CalendarFeed, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListPaymentsByBooking(ctx context.Context, bookingID pgtype.UUID) ([]Payment, error)
+	ListPendingPaymentOperations(ctx context.Context, arg ListPendingPaymentOperationsParams) ([]PaymentOperation, error)
+	ListPendingPaymentOperationsByBooking(ctx context.Context, bookingID pgtype.UUID) ([]PaymentOperation, error)
+	ListPricingRulesByService(ctx context.Context, serviceID pgtype.UUID) ([]PricingRule, error)
+	ListPricingRulesByServices(ctx context.Context, serviceIds []pgtype.UUID) ([]PricingRule, error)
+	ListScheduleBlackoutsByService(ctx context.Context, serviceID pgtype.UUID) ([]ScheduleBlackout, error)
+	ListScheduleBlackoutsInWindow(ctx context.Context, arg ListScheduleBlackoutsInWindowParams) ([]ScheduleBlackout, error)
+	ListScheduleRuleExceptions(ctx context.Context, ruleIds []pgtype.UUID) ([]ScheduleRuleException, error)
+	ListScheduleRulesByService(ctx context.Context, serviceID pgtype.UUID) ([]ScheduleRule, error)
+	ListScheduleRulesByServices(ctx context.Context, serviceIds []pgtype.UUID) ([]ScheduleRule, error)
 	ListSchedules(ctx context.Context) ([]Schedule, error)
 	ListSchedulesByService(ctx context.Context, serviceID pgtype.UUID) ([]Schedule, error)
+	ListSchedulesInWindow(ctx context.Context, arg ListSchedulesInWindowParams) ([]Schedule, error)
 	ListServices(ctx context.Context) ([]Service, error)
+	ListServicesByOwner(ctx context.Context, ownerID pgtype.UUID) ([]Service, error)
+	ListUserIdentities(ctx context.Context, userID pgtype.UUID) ([]UserIdentity, error)
+	ListUserSessions(ctx context.Context, userID pgtype.UUID) ([]UserToken, error)
 	ListUsers(ctx context.Context, arg ListUsersParams) ([]User, error)
+	LockServiceForBooking(ctx context.Context, id pgtype.UUID) (pgtype.UUID, error)
+	LockUser(ctx context.Context, arg LockUserParams) error
+	MarkUserEmailUnverified(ctx context.Context, id pgtype.UUID) error
+	MarkUserEmailVerified(ctx context.Context, id pgtype.UUID) (User, error)
+	NextInvoiceNumber(ctx context.Context, providerID pgtype.UUID) (int32, error)
+	PruneUserSessions(ctx context.Context, arg PruneUserSessionsParams) error
+	RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (User, error)
+	RecordPaymentEvent(ctx context.Context, arg RecordPaymentEventParams) (int64, error)
+	RotateUserToken(ctx context.Context, id pgtype.UUID) (UserToken, error)
+	SearchAvailableServices(ctx context.Context, arg SearchAvailableServicesParams) ([]Service, error)
+	SetUserCalendarToken(ctx context.Context, arg SetUserCalendarTokenParams) error
+	SetUserTOTPSecret(ctx context.Context, arg SetUserTOTPSecretParams) error
+	UnlockUser(ctx context.Context, id pgtype.UUID) (User, error)
 	UpdateBooking(ctx context.Context, arg UpdateBookingParams) (Booking, error)
+	UpdateBookingStatus(ctx context.Context, arg UpdateBookingStatusParams) (Booking, error)
+	UpdateCalendarFeedSyncStatus(ctx context.Context, arg UpdateCalendarFeedSyncStatusParams) (CalendarFeed, error)
+	UpdatePaymentOperationStatus(ctx context.Context, arg UpdatePaymentOperationStatusParams) error
+	UpdatePaymentStatus(ctx context.Context, arg UpdatePaymentStatusParams) (Payment, error)
 	UpdateSchedule(ctx context.Context, arg UpdateScheduleParams) (Schedule, error)
 	UpdateService(ctx context.Context, arg UpdateServiceParams) (Service, error)
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
 	UpdateUserToken(ctx context.Context, arg UpdateUserTokenParams) (UserToken, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) error
+	UseEmailVerificationToken(ctx context.Context, id pgtype.UUID) (EmailVerificationToken, error)
+	UsePasswordResetToken(ctx context.Context, id pgtype.UUID) (PasswordResetToken, error)
+	UseRecoveryCode(ctx context.Context, arg UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg UseTOTPStepParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -145,6 +145,47 @@ func (q *Queries) ListSchedulesByService(ctx context.Context, serviceID pgtype.U
 	return items, nil
 }
 
+const listSchedulesInWindow = `-- name: ListSchedulesInWindow :many
+SELECT id, service_id, date, time_start, time_end, status FROM schedules
+WHERE service_id = ANY($1::uuid[])
+  AND date >= $2
+  AND date < $3
+ORDER BY date, time_start
+`
+
+type ListSchedulesInWindowParams struct {
+	ServiceIds  []pgtype.UUID `json:"service_ids"`
+	WindowStart pgtype.Date   `json:"window_start"`
+	WindowEnd   pgtype.Date   `json:"window_end"`
+}
+
+func (q *Queries) ListSchedulesInWindow(ctx context.Context, arg ListSchedulesInWindowParams) ([]Schedule, error) {
+	rows, err := q.db.Query(ctx, listSchedulesInWindow, arg.ServiceIds, arg.WindowStart, arg.WindowEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Schedule{}
+	for rows.Next() {
+		var i Schedule
+		if err := rows.Scan(
+			&i.ID,
+			&i.ServiceID,
+			&i.Date,
+			&i.TimeStart,
+			&i.TimeEnd,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateSchedule = `-- name: UpdateSchedule :one
 UPDATE schedules
 SET 
@@ -16,17 +16,25 @@ INSERT INTO services (
     name,
     description,
     location, 
-    price
+    price,
+    owner_id,
+    max_guests,
+    cancellation_policy,
+    currency
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, name, description, location, price
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING id, name, description, location, price, owner_id, max_guests, cancellation_policy, currency
 `
 
 type CreateServiceParams struct {
-	Name        string         `json:"name"`
-	Description pgtype.Text    `json:"description"`
-	Location    string         `json:"location"`
-	Price       pgtype.Numeric `json:"price"`
+	Name               string         `json:"name"`
+	Description        pgtype.Text    `json:"description"`
+	Location           string         `json:"location"`
+	Price              pgtype.Numeric `json:"price"`
+	OwnerID            pgtype.UUID    `json:"owner_id"`
+	MaxGuests          int32          `json:"max_guests"`
+	CancellationPolicy []byte         `json:"cancellation_policy"`
+	Currency           string         `json:"currency"`
 }
 
 func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (Service, error) {
@@ -35,6 +43,10 @@ func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (S
 		arg.Description,
 		arg.Location,
 		arg.Price,
+		arg.OwnerID,
+		arg.MaxGuests,
+		arg.CancellationPolicy,
+		arg.Currency,
 	)
 	var i Service
 	err := row.Scan(
@@ -43,6 +55,10 @@ func (q *Queries) CreateService(ctx context.Context, arg CreateServiceParams) (S
 		&i.Description,
 		&i.Location,
 		&i.Price,
+		&i.OwnerID,
+		&i.MaxGuests,
+		&i.CancellationPolicy,
+		&i.Currency,
 	)
 	return i, err
 }
@@ -58,7 +74,7 @@ func (q *Queries) DeleteService(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getService = `-- name: GetService :one
-SELECT id, name, description, location, price FROM services
+SELECT id, name, description, location, price, owner_id, max_guests, cancellation_policy, currency FROM services
 WHERE id = $1
 `
 
@@ -71,12 +87,16 @@ func (q *Queries) GetService(ctx context.Context, id pgtype.UUID) (Service, erro
 		&i.Description,
 		&i.Location,
 		&i.Price,
+		&i.OwnerID,
+		&i.MaxGuests,
+		&i.CancellationPolicy,
+		&i.Currency,
 	)
 	return i, err
 }
 
 const listServices = `-- name: ListServices :many
-SELECT id, name, description, location, price FROM services
+SELECT id, name, description, location, price, owner_id, max_guests, cancellation_policy, currency FROM services
 ORDER BY name
 `
 
@@ -95,6 +115,105 @@ func (q *Queries) ListServices(ctx context.Context) ([]Service, error) {
 			&i.Description,
 			&i.Location,
 			&i.Price,
+			&i.OwnerID,
+			&i.MaxGuests,
+			&i.CancellationPolicy,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listServicesByOwner = `-- name: ListServicesByOwner :many
+SELECT id, name, description, location, price, owner_id, max_guests, cancellation_policy, currency FROM services
+WHERE owner_id = $1
+ORDER BY name
+`
+
+func (q *Queries) ListServicesByOwner(ctx context.Context, ownerID pgtype.UUID) ([]Service, error) {
+	rows, err := q.db.Query(ctx, listServicesByOwner, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Service{}
+	for rows.Next() {
+		var i Service
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Location,
+			&i.Price,
+			&i.OwnerID,
+			&i.MaxGuests,
+			&i.CancellationPolicy,
+			&i.Currency,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchAvailableServices = `-- name: SearchAvailableServices :many
+SELECT s.id, s.name, s.description, s.location, s.price, s.owner_id, s.max_guests, s.cancellation_policy, s.currency
+FROM services s
+WHERE ($1::text IS NULL OR s.location ILIKE '%' || $1 || '%')
+  AND s.max_guests >= $2::int
+  AND NOT EXISTS (
+    SELECT 1 FROM bookings b
+    WHERE b.service_id = s.id
+      AND b.check_in < $3
+      AND b.check_out > $4
+      AND b.status <> ALL($5::text[])
+  )
+ORDER BY s.name, s.id
+`
+
+type SearchAvailableServicesParams struct {
+	Location         pgtype.Text `json:"location"`
+	Guests           int32       `json:"guests"`
+	CheckOut         pgtype.Date `json:"check_out"`
+	CheckIn          pgtype.Date `json:"check_in"`
+	InactiveStatuses []string    `json:"inactive_statuses"`
+}
+
+func (q *Queries) SearchAvailableServices(ctx context.Context, arg SearchAvailableServicesParams) ([]Service, error) {
+	rows, err := q.db.Query(ctx, searchAvailableServices,
+		arg.Location,
+		arg.Guests,
+		arg.CheckOut,
+		arg.CheckIn,
+		arg.InactiveStatuses,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Service{}
+	for rows.Next() {
+		var i Service
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.Location,
+			&i.Price,
+			&i.OwnerID,
+			&i.MaxGuests,
+			&i.CancellationPolicy,
+			&i.Currency,
 		); err != nil {
 			return nil, err
 		}
@@ -111,17 +230,23 @@ UPDATE services
 SET name = $2,
     description = $3,
     location = $4,
-    price = $5
+    price = $5,
+    max_guests = $6,
+    cancellation_policy = $7,
+    currency = $8
 WHERE id = $1
-RETURNING id, name, description, location, price
+RETURNING id, name, description, location, price, owner_id, max_guests, cancellation_policy, currency
 `
 
 type UpdateServiceParams struct {
-	ID          pgtype.UUID    `json:"id"`
-	Name        string         `json:"name"`
-	Description pgtype.Text    `json:"description"`
-	Location    string         `json:"location"`
-	Price       pgtype.Numeric `json:"price"`
+	ID                 pgtype.UUID    `json:"id"`
+	Name               string         `json:"name"`
+	Description        pgtype.Text    `json:"description"`
+	Location           string         `json:"location"`
+	Price              pgtype.Numeric `json:"price"`
+	MaxGuests          int32          `json:"max_guests"`
+	CancellationPolicy []byte         `json:"cancellation_policy"`
+	Currency           string         `json:"currency"`
 }
 
 func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (Service, error) {
@@ -131,6 +256,9 @@ func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (S
 		arg.Description,
 		arg.Location,
 		arg.Price,
+		arg.MaxGuests,
+		arg.CancellationPolicy,
+		arg.Currency,
 	)
 	var i Service
 	err := row.Scan(
@@ -139,6 +267,10 @@ func (q *Queries) UpdateService(ctx context.Context, arg UpdateServiceParams) (S
 		&i.Description,
 		&i.Location,
 		&i.Price,
+		&i.OwnerID,
+		&i.MaxGuests,
+		&i.CancellationPolicy,
+		&i.Currency,
 	)
 	return i, err
 }
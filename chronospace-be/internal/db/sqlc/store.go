@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Store provides all queries along with transaction support.
+type Store interface {
+	Querier
+	ExecTx(ctx context.Context, fn func(*Queries) error) error
+}
+
+type SQLStore struct {
+	*Queries
+	connPool *pgxpool.Pool
+}
+
+func NewStore(connPool *pgxpool.Pool) Store {
+	return &SQLStore{
+		Queries:  New(connPool),
+		connPool: connPool,
+	}
+}
+
+// ExecTx runs fn inside a database transaction, committing when fn succeeds
+// and rolling back otherwise.
+func (store *SQLStore) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.connPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(New(tx)); err != nil {
+		if rbErr := tx.Rollback(ctx); rbErr != nil {
+			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+		}
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -27,20 +27,36 @@ const createUserToken = `-- name: CreateUserToken :one
 INSERT INTO user_tokens (
     user_id,
     refresh_token,
-    refresh_token_expires_at
+    refresh_token_expires_at,
+    session_id,
+    session_started_at,
+    user_agent,
+    ip_address
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, user_id, refresh_token, refresh_token_expires_at, created_at
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address
 `
 
 type CreateUserTokenParams struct {
 	UserID                pgtype.UUID      `json:"user_id"`
 	RefreshToken          string           `json:"refresh_token"`
 	RefreshTokenExpiresAt pgtype.Timestamp `json:"refresh_token_expires_at"`
+	SessionID             pgtype.UUID      `json:"session_id"`
+	SessionStartedAt      pgtype.Timestamp `json:"session_started_at"`
+	UserAgent             pgtype.Text      `json:"user_agent"`
+	IpAddress             pgtype.Text      `json:"ip_address"`
 }
 
 func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams) (UserToken, error) {
-	row := q.db.QueryRow(ctx, createUserToken, arg.UserID, arg.RefreshToken, arg.RefreshTokenExpiresAt)
+	row := q.db.QueryRow(ctx, createUserToken,
+		arg.UserID,
+		arg.RefreshToken,
+		arg.RefreshTokenExpiresAt,
+		arg.SessionID,
+		arg.SessionStartedAt,
+		arg.UserAgent,
+		arg.IpAddress,
+	)
 	var i UserToken
 	err := row.Scan(
 		&i.ID,
@@ -48,6 +64,11 @@ func (q *Queries) CreateUserToken(ctx context.Context, arg CreateUserTokenParams
 		&i.RefreshToken,
 		&i.RefreshTokenExpiresAt,
 		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.SessionID,
+		&i.SessionStartedAt,
+		&i.UserAgent,
+		&i.IpAddress,
 	)
 	return i, err
 }
@@ -62,6 +83,39 @@ func (q *Queries) DeleteExpiredTokens(ctx context.Context) error {
 	return err
 }
 
+const deleteOtherUserSessions = `-- name: DeleteOtherUserSessions :exec
+DELETE FROM user_tokens
+WHERE user_id = $1 AND session_id <> $2
+`
+
+type DeleteOtherUserSessionsParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	SessionID pgtype.UUID `json:"session_id"`
+}
+
+func (q *Queries) DeleteOtherUserSessions(ctx context.Context, arg DeleteOtherUserSessionsParams) error {
+	_, err := q.db.Exec(ctx, deleteOtherUserSessions, arg.UserID, arg.SessionID)
+	return err
+}
+
+const deleteUserSession = `-- name: DeleteUserSession :execrows
+DELETE FROM user_tokens
+WHERE user_id = $1 AND session_id = $2
+`
+
+type DeleteUserSessionParams struct {
+	UserID    pgtype.UUID `json:"user_id"`
+	SessionID pgtype.UUID `json:"session_id"`
+}
+
+func (q *Queries) DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserSession, arg.UserID, arg.SessionID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const deleteUserToken = `-- name: DeleteUserToken :exec
 DELETE FROM user_tokens
 WHERE id = $1
@@ -83,7 +137,7 @@ func (q *Queries) DeleteUserTokensByUserID(ctx context.Context, userID pgtype.UU
 }
 
 const getUserTokenByID = `-- name: GetUserTokenByID :one
-SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at FROM user_tokens
+SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address FROM user_tokens
 WHERE id = $1
 `
 
@@ -96,12 +150,17 @@ func (q *Queries) GetUserTokenByID(ctx context.Context, id pgtype.UUID) (UserTok
 		&i.RefreshToken,
 		&i.RefreshTokenExpiresAt,
 		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.SessionID,
+		&i.SessionStartedAt,
+		&i.UserAgent,
+		&i.IpAddress,
 	)
 	return i, err
 }
 
 const getUserTokenByRefreshToken = `-- name: GetUserTokenByRefreshToken :one
-SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at FROM user_tokens
+SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address FROM user_tokens
 WHERE refresh_token = $1
 `
 
@@ -114,12 +173,17 @@ func (q *Queries) GetUserTokenByRefreshToken(ctx context.Context, refreshToken s
 		&i.RefreshToken,
 		&i.RefreshTokenExpiresAt,
 		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.SessionID,
+		&i.SessionStartedAt,
+		&i.UserAgent,
+		&i.IpAddress,
 	)
 	return i, err
 }
 
 const getUserTokensByUserID = `-- name: GetUserTokensByUserID :many
-SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at FROM user_tokens
+SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address FROM user_tokens
 WHERE user_id = $1
 `
 
@@ -138,6 +202,50 @@ func (q *Queries) GetUserTokensByUserID(ctx context.Context, userID pgtype.UUID)
 			&i.RefreshToken,
 			&i.RefreshTokenExpiresAt,
 			&i.CreatedAt,
+			&i.RotatedAt,
+			&i.SessionID,
+			&i.SessionStartedAt,
+			&i.UserAgent,
+			&i.IpAddress,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserSessions = `-- name: ListUserSessions :many
+SELECT id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address FROM user_tokens
+WHERE user_id = $1
+    AND rotated_at IS NULL
+    AND refresh_token_expires_at > CURRENT_TIMESTAMP
+ORDER BY created_at DESC
+`
+
+func (q *Queries) ListUserSessions(ctx context.Context, userID pgtype.UUID) ([]UserToken, error) {
+	rows, err := q.db.Query(ctx, listUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []UserToken{}
+	for rows.Next() {
+		var i UserToken
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.RefreshToken,
+			&i.RefreshTokenExpiresAt,
+			&i.CreatedAt,
+			&i.RotatedAt,
+			&i.SessionID,
+			&i.SessionStartedAt,
+			&i.UserAgent,
+			&i.IpAddress,
 		); err != nil {
 			return nil, err
 		}
@@ -149,13 +257,61 @@ func (q *Queries) GetUserTokensByUserID(ctx context.Context, userID pgtype.UUID)
 	return items, nil
 }
 
+const pruneUserSessions = `-- name: PruneUserSessions :exec
+DELETE FROM user_tokens
+WHERE user_id = $1
+    AND session_id IN (
+        SELECT session_id FROM user_tokens
+        WHERE user_id = $1
+            AND rotated_at IS NULL
+            AND refresh_token_expires_at > CURRENT_TIMESTAMP
+        ORDER BY created_at DESC
+        OFFSET $2
+    )
+`
+
+type PruneUserSessionsParams struct {
+	UserID pgtype.UUID `json:"user_id"`
+	Keep   int32       `json:"keep"`
+}
+
+func (q *Queries) PruneUserSessions(ctx context.Context, arg PruneUserSessionsParams) error {
+	_, err := q.db.Exec(ctx, pruneUserSessions, arg.UserID, arg.Keep)
+	return err
+}
+
+const rotateUserToken = `-- name: RotateUserToken :one
+UPDATE user_tokens
+SET rotated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND rotated_at IS NULL
+RETURNING id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address
+`
+
+func (q *Queries) RotateUserToken(ctx context.Context, id pgtype.UUID) (UserToken, error) {
+	row := q.db.QueryRow(ctx, rotateUserToken, id)
+	var i UserToken
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.RefreshToken,
+		&i.RefreshTokenExpiresAt,
+		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.SessionID,
+		&i.SessionStartedAt,
+		&i.UserAgent,
+		&i.IpAddress,
+	)
+	return i, err
+}
+
 const updateUserToken = `-- name: UpdateUserToken :one
 UPDATE user_tokens
 SET 
     refresh_token = $2,
     refresh_token_expires_at = $3
 WHERE id = $1
-RETURNING id, user_id, refresh_token, refresh_token_expires_at, created_at
+RETURNING id, user_id, refresh_token, refresh_token_expires_at, created_at, rotated_at, session_id, session_started_at, user_agent, ip_address
 `
 
 type UpdateUserTokenParams struct {
@@ -173,6 +329,11 @@ func (q *Queries) UpdateUserToken(ctx context.Context, arg UpdateUserTokenParams
 		&i.RefreshToken,
 		&i.RefreshTokenExpiresAt,
 		&i.CreatedAt,
+		&i.RotatedAt,
+		&i.SessionID,
+		&i.SessionStartedAt,
+		&i.UserAgent,
+		&i.IpAddress,
 	)
 	return i, err
 }
@@ -16,10 +16,11 @@ INSERT INTO users (
     username,
     full_name,
     email,
-    password
+    password,
+    role
 ) VALUES (
-    $1, $2, $3, $4
-) RETURNING id, username, full_name, email, password, created_at
+    $1, $2, $3, $4, $5
+) RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
 `
 
 type CreateUserParams struct {
@@ -27,6 +28,7 @@ type CreateUserParams struct {
 	FullName string `json:"full_name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Role     string `json:"role"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -35,6 +37,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.FullName,
 		arg.Email,
 		arg.Password,
+		arg.Role,
 	)
 	var i User
 	err := row.Scan(
@@ -44,6 +47,16 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -59,7 +72,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id pgtype.UUID) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, username, full_name, email, password, created_at FROM users
+SELECT id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at FROM users
 WHERE id = $1
 `
 
@@ -73,12 +86,22 @@ func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, full_name, email, password, created_at FROM users
+SELECT id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at FROM users
 WHERE email = $1
 `
 
@@ -92,12 +115,22 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, full_name, email, password, created_at FROM users
+SELECT id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at FROM users
 WHERE username = $1
 `
 
@@ -111,12 +144,22 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
 
 const listUsers = `-- name: ListUsers :many
-SELECT id, username, full_name, email, password, created_at FROM users
+SELECT id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at FROM users
 ORDER BY created_at
 LIMIT $1
 OFFSET $2
@@ -143,6 +186,16 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 			&i.Email,
 			&i.Password,
 			&i.CreatedAt,
+			&i.Role,
+			&i.CalendarTokenHash,
+			&i.EmailVerifiedAt,
+			&i.FailedLoginAttempts,
+			&i.LastFailedLoginAt,
+			&i.LockedUntil,
+			&i.TotpSecret,
+			&i.TotpEnabledAt,
+			&i.TotpLastStep,
+			&i.ErasedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -154,15 +207,101 @@ func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]User, e
 	return items, nil
 }
 
+const lockUser = `-- name: LockUser :exec
+UPDATE users
+SET failed_login_attempts = 0,
+    locked_until = $2
+WHERE id = $1
+`
+
+type LockUserParams struct {
+	ID          pgtype.UUID      `json:"id"`
+	LockedUntil pgtype.Timestamp `json:"locked_until"`
+}
+
+func (q *Queries) LockUser(ctx context.Context, arg LockUserParams) error {
+	_, err := q.db.Exec(ctx, lockUser, arg.ID, arg.LockedUntil)
+	return err
+}
+
+const recordFailedLogin = `-- name: RecordFailedLogin :one
+UPDATE users
+SET failed_login_attempts = failed_login_attempts + 1,
+    last_failed_login_at = $2
+WHERE id = $1
+RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
+`
+
+type RecordFailedLoginParams struct {
+	ID                pgtype.UUID      `json:"id"`
+	LastFailedLoginAt pgtype.Timestamp `json:"last_failed_login_at"`
+}
+
+func (q *Queries) RecordFailedLogin(ctx context.Context, arg RecordFailedLoginParams) (User, error) {
+	row := q.db.QueryRow(ctx, recordFailedLogin, arg.ID, arg.LastFailedLoginAt)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const unlockUser = `-- name: UnlockUser :one
+UPDATE users
+SET failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL
+WHERE id = $1
+RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
+`
+
+func (q *Queries) UnlockUser(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, unlockUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
 const updateUser = `-- name: UpdateUser :one
 UPDATE users
 SET 
     username = COALESCE($2, username),
     full_name = COALESCE($3, full_name),
-    email = COALESCE($4, email),
-    password = COALESCE($5, password)
+    email = COALESCE($4, email)
 WHERE id = $1
-RETURNING id, username, full_name, email, password, created_at
+RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
 `
 
 type UpdateUserParams struct {
@@ -170,7 +309,6 @@ type UpdateUserParams struct {
 	Username string      `json:"username"`
 	FullName string      `json:"full_name"`
 	Email    string      `json:"email"`
-	Password string      `json:"password"`
 }
 
 func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error) {
@@ -179,7 +317,6 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		arg.Username,
 		arg.FullName,
 		arg.Email,
-		arg.Password,
 	)
 	var i User
 	err := row.Scan(
@@ -189,6 +326,16 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -197,7 +344,7 @@ const updateUserPassword = `-- name: UpdateUserPassword :one
 UPDATE users
 SET password = $2
 WHERE id = $1
-RETURNING id, username, full_name, email, password, created_at
+RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
 `
 
 type UpdateUserPasswordParams struct {
@@ -215,6 +362,52 @@ func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPassword
 		&i.Email,
 		&i.Password,
 		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
+	)
+	return i, err
+}
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2
+WHERE id = $1
+RETURNING id, username, full_name, email, password, created_at, role, calendar_token_hash, email_verified_at, failed_login_attempts, last_failed_login_at, locked_until, totp_secret, totp_enabled_at, totp_last_step, erased_at
+`
+
+type UpdateUserRoleParams struct {
+	ID   pgtype.UUID `json:"id"`
+	Role string      `json:"role"`
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.FullName,
+		&i.Email,
+		&i.Password,
+		&i.CreatedAt,
+		&i.Role,
+		&i.CalendarTokenHash,
+		&i.EmailVerifiedAt,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastStep,
+		&i.ErasedAt,
 	)
 	return i, err
 }
@@ -0,0 +1,285 @@
+// Package invoice lays out booking invoices as PDF documents. Amounts are
+// integers in the minor unit of the invoice currency and include tax, like
+// the prices guests are quoted.
+package invoice
+
+import (
+	"chronospace-be/internal/currency"
+	"chronospace-be/internal/pdf"
+	"chronospace-be/internal/pricing"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Party is the seller or the buyer named on an invoice.
+type Party struct {
+	Name  string
+	Email string
+}
+
+// Line is an invoiced item. Lines are stored with the invoice so reprints
+// match the original.
+type Line struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	Amount      int64  `json:"amount"`
+}
+
+// Payment is a movement of money shown in the receipt part of an invoice.
+// Refunds have negative amounts.
+type Payment struct {
+	Date        time.Time
+	Description string
+	Amount      int64
+}
+
+// Invoice is everything printed on an invoice.
+type Invoice struct {
+	Number    string
+	IssuedAt  time.Time
+	Currency  string
+	Seller    Party
+	Buyer     Party
+	BookingID string
+	Service   string
+	Location  string
+	CheckIn   time.Time
+	CheckOut  time.Time
+	Lines     []Line
+	Total     int64
+	TaxRate   *big.Rat
+	TaxAmount int64
+	Payments  []Payment
+}
+
+// FormatNumber formats the sequence number of an invoice.
+func FormatNumber(number int32) string {
+	return fmt.Sprintf("INV-%06d", number)
+}
+
+// Lines turns the price breakdown of a stay into invoice lines. Nights with
+// the same price and adjustments are listed together, followed by discounts.
+func Lines(quote pricing.Quote) []Line {
+	var lines []Line
+	index := map[string]int{}
+	for _, night := range quote.Nights {
+		description := "Night"
+		for _, adjustment := range night.Adjustments {
+			description += ", " + adjustment.Description
+		}
+
+		key := fmt.Sprintf("%s/%d", description, night.Amount)
+		if i, ok := index[key]; ok {
+			lines[i].Quantity++
+			lines[i].Amount += night.Amount
+			continue
+		}
+		index[key] = len(lines)
+		lines = append(lines, Line{Description: description, Quantity: 1, UnitAmount: night.Amount, Amount: night.Amount})
+	}
+
+	for _, discount := range quote.Discounts {
+		lines = append(lines, Line{
+			Description: "Discount, " + discount.Description,
+			Quantity:    1,
+			UnitAmount:  discount.Amount,
+			Amount:      discount.Amount,
+		})
+	}
+	return lines
+}
+
+// IncludedTax returns the tax contained in a gross amount at ratePercent,
+// rounded half away from zero.
+func IncludedTax(gross int64, ratePercent *big.Rat) int64 {
+	if ratePercent.Sign() == 0 {
+		return 0
+	}
+
+	// gross * rate / (100 + rate)
+	tax := new(big.Rat).SetInt64(gross)
+	tax.Mul(tax, ratePercent)
+	tax.Quo(tax, new(big.Rat).Add(big.NewRat(100, 1), ratePercent))
+	return currency.Round(tax)
+}
+
+// FormatAmount formats minor units with thousands separators and the
+// currency code, e.g. "1,234.50 EUR".
+func FormatAmount(amount int64, code string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := currency.Digits(code)
+	s := fmt.Sprintf("%0*d", digits+1, amount)
+	whole, fraction := s[:len(s)-int(digits)], s[len(s)-int(digits):]
+
+	var grouped strings.Builder
+	for i, c := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(c)
+	}
+	if fraction != "" {
+		return fmt.Sprintf("%s%s.%s %s", sign, grouped.String(), fraction, code)
+	}
+	return fmt.Sprintf("%s%s %s", sign, grouped.String(), code)
+}
+
+// formatRate formats a tax rate without trailing zeros, e.g. "7.7".
+func formatRate(rate *big.Rat) string {
+	s := rate.FloatString(2)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+const (
+	dateLayout = "2006-01-02"
+
+	margin = 50.0
+	right  = pdf.PageWidth - margin
+	bottom = pdf.PageHeight - 70
+
+	// Columns of the line table, by their right edge
+	colQuantity = 360.0
+	colUnit     = 455.0
+)
+
+// Render lays out an invoice on as many A4 pages as its lines need.
+func Render(inv Invoice) ([]byte, error) {
+	doc := pdf.New("Invoice " + inv.Number)
+	r := &renderer{doc: doc}
+	r.newPage()
+
+	page := r.page
+	page.Text(margin, 70, pdf.Bold, 22, "Invoice")
+	page.TextRight(right, 70, pdf.Bold, 12, "Chronospace")
+
+	r.y = 100
+	r.field("Invoice number", inv.Number)
+	r.field("Issue date", inv.IssuedAt.Format(dateLayout))
+	r.field("Booking", inv.BookingID)
+
+	// Seller and buyer side by side
+	r.y += 14
+	top := r.y
+	r.party("From", inv.Seller, margin)
+	sellerEnd := r.y
+	r.y = top
+	r.party("Bill to", inv.Buyer, 320)
+	r.y = max(r.y, sellerEnd)
+
+	r.y += 14
+	r.page.Text(margin, r.y, pdf.Bold, 10, "Stay")
+	r.y += 14
+	r.page.Text(margin, r.y, pdf.Regular, 10, inv.Service)
+	r.y += 13
+	if inv.Location != "" {
+		r.page.Text(margin, r.y, pdf.Regular, 10, inv.Location)
+		r.y += 13
+	}
+	r.page.Text(margin, r.y, pdf.Regular, 10,
+		fmt.Sprintf("%s to %s", inv.CheckIn.Format(dateLayout), inv.CheckOut.Format(dateLayout)))
+	r.y += 28
+
+	r.tableHeader()
+	for _, line := range inv.Lines {
+		r.ensureSpace(16)
+		r.page.Text(margin, r.y, pdf.Regular, 10, line.Description)
+		r.page.TextRight(colQuantity, r.y, pdf.Regular, 10, fmt.Sprint(line.Quantity))
+		r.page.TextRight(colUnit, r.y, pdf.Regular, 10, FormatAmount(line.UnitAmount, inv.Currency))
+		r.page.TextRight(right, r.y, pdf.Regular, 10, FormatAmount(line.Amount, inv.Currency))
+		r.y += 16
+	}
+
+	r.ensureSpace(70)
+	r.page.Line(margin, r.y-8, right, r.y-8, 0.5)
+	r.y += 6
+	r.total("Net amount", FormatAmount(inv.Total-inv.TaxAmount, inv.Currency), pdf.Regular)
+	r.total(fmt.Sprintf("VAT %s%%", formatRate(inv.TaxRate)), FormatAmount(inv.TaxAmount, inv.Currency), pdf.Regular)
+	r.total("Total", FormatAmount(inv.Total, inv.Currency), pdf.Bold)
+
+	// The receipt part: what has been paid so far
+	r.y += 20
+	r.ensureSpace(60)
+	r.page.Text(margin, r.y, pdf.Bold, 10, "Payments")
+	r.y += 16
+	paid := int64(0)
+	if len(inv.Payments) == 0 {
+		r.page.Text(margin, r.y, pdf.Regular, 10, "No payments received")
+		r.y += 16
+	}
+	for _, payment := range inv.Payments {
+		r.ensureSpace(16)
+		r.page.Text(margin, r.y, pdf.Regular, 10, payment.Date.Format(dateLayout))
+		r.page.Text(130, r.y, pdf.Regular, 10, payment.Description)
+		r.page.TextRight(right, r.y, pdf.Regular, 10, FormatAmount(payment.Amount, inv.Currency))
+		r.y += 16
+		paid += payment.Amount
+	}
+	r.ensureSpace(40)
+	r.y += 4
+	r.total("Amount paid", FormatAmount(paid, inv.Currency), pdf.Regular)
+	r.total("Balance due", FormatAmount(max(inv.Total-paid, 0), inv.Currency), pdf.Bold)
+
+	return doc.Bytes()
+}
+
+// renderer keeps track of the current page and the vertical position on it.
+type renderer struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+func (r *renderer) newPage() {
+	r.page = r.doc.AddPage()
+	r.y = 70
+	r.page.Text(margin, pdf.PageHeight-40, pdf.Regular, 8, "All amounts include VAT.")
+}
+
+// ensureSpace starts a new page when height does not fit on the current one.
+func (r *renderer) ensureSpace(height float64) {
+	if r.y+height > bottom {
+		r.newPage()
+		r.tableHeader()
+	}
+}
+
+func (r *renderer) tableHeader() {
+	r.page.Text(margin, r.y, pdf.Bold, 10, "Description")
+	r.page.TextRight(colQuantity, r.y, pdf.Bold, 10, "Qty")
+	r.page.TextRight(colUnit, r.y, pdf.Bold, 10, "Unit price")
+	r.page.TextRight(right, r.y, pdf.Bold, 10, "Amount")
+	r.page.Line(margin, r.y+6, right, r.y+6, 0.5)
+	r.y += 22
+}
+
+func (r *renderer) field(label, value string) {
+	r.page.Text(margin, r.y, pdf.Bold, 10, label)
+	r.page.Text(150, r.y, pdf.Regular, 10, value)
+	r.y += 14
+}
+
+func (r *renderer) party(label string, party Party, x float64) {
+	r.page.Text(x, r.y, pdf.Bold, 10, label)
+	r.y += 14
+	r.page.Text(x, r.y, pdf.Regular, 10, party.Name)
+	r.y += 13
+	if party.Email != "" {
+		r.page.Text(x, r.y, pdf.Regular, 10, party.Email)
+		r.y += 13
+	}
+}
+
+func (r *renderer) total(label, amount string, font pdf.Font) {
+	r.page.Text(colQuantity, r.y, font, 10, label)
+	r.page.TextRight(right, r.y, font, 10, amount)
+	r.y += 16
+}
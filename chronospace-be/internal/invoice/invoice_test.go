@@ -0,0 +1,33 @@
+package invoice
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestIncludedTax(t *testing.T) {
+	tests := []struct {
+		name  string
+		gross int64
+		rate  *big.Rat
+		want  int64
+	}{
+		{name: "exact", gross: 11900, rate: big.NewRat(19, 1), want: 1900},
+		{name: "rounds up", gross: 100, rate: big.NewRat(19, 1), want: 16},
+		{name: "rounds down", gross: 1000, rate: big.NewRat(77, 10), want: 71},
+		{name: "half rounds away from zero", gross: 3, rate: big.NewRat(100, 1), want: 2},
+		{name: "negative half rounds away from zero", gross: -3, rate: big.NewRat(100, 1), want: -2},
+		{name: "refund", gross: -11900, rate: big.NewRat(19, 1), want: -1900},
+		{name: "fractional rate", gross: 10810, rate: big.NewRat(81, 10), want: 810},
+		{name: "zero rate", gross: 12345, rate: new(big.Rat), want: 0},
+		{name: "zero amount", gross: 0, rate: big.NewRat(20, 1), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IncludedTax(tt.gross, tt.rate); got != tt.want {
+				t.Fatalf("IncludedTax(%d, %s) = %d, want %d", tt.gross, tt.rate.RatString(), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+// Package mailer sends transactional email through a configurable backend.
+package mailer
+
+import (
+	"chronospace-be/internal/config"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+const (
+	BackendSMTP = "smtp"
+	BackendFile = "file"
+	BackendLog  = "log"
+)
+
+// New returns the mailer selected by MAIL_BACKEND. The log backend is used
+// when nothing is configured so that development setups work out of the box.
+func New(cfg *config.Config) (Mailer, error) {
+	switch strings.ToLower(cfg.MailBackend) {
+	case BackendSMTP:
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case BackendFile:
+		return NewFileMailer(cfg.MailDir, cfg.MailFrom), nil
+	case BackendLog, "":
+		return NewLogMailer(cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("unknown mail backend %q", cfg.MailBackend)
+	}
+}
+
+// format renders msg as an RFC 5322 message.
+func format(from string, msg Message) []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "From: %s\r\n", from)
+	fmt.Fprintf(&sb, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&sb, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&sb, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(sb.String())
+}
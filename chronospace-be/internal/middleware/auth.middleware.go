@@ -1,22 +1,25 @@
 package middleware
 
 import (
+	"chronospace-be/internal/token"
+	"errors"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
 type JWTConfig struct {
-	SecretKey string
+	Tokens *token.Manager
 }
 
-func NewJWTMiddleware(secretKey string) *JWTConfig {
+func NewJWTMiddleware(tokens *token.Manager) *JWTConfig {
 	return &JWTConfig{
-		SecretKey: secretKey,
+		Tokens: tokens,
 	}
 }
 
+// ValidateJWT authenticates requests by their bearer access token and stores
+// its claims in the context for utils.GetClaimsFromContext.
 func (j *JWTConfig) ValidateJWT() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -33,53 +36,24 @@ func (j *JWTConfig) ValidateJWT() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(bearerToken[1], func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
-			}
-			return []byte(j.SecretKey), nil
-		})
-
+		// Refresh and MFA tokens are signed with the same keys but must not
+		// grant access
+		claims, err := j.Tokens.Parse(bearerToken[1], token.TypeAccess)
 		if err != nil {
 			switch {
-			case err == jwt.ErrSignatureInvalid:
-				c.JSON(401, gin.H{"error": "Invalid token signature"})
-			case strings.Contains(err.Error(), "token is expired"):
+			case errors.Is(err, token.ErrExpiredToken):
 				c.JSON(401, gin.H{"error": "Token has expired"})
-			case strings.Contains(err.Error(), "token is not valid"):
-				c.JSON(401, gin.H{"error": "Token structure is invalid"})
+			case errors.Is(err, token.ErrTokenType):
+				c.JSON(401, gin.H{"error": "Invalid token type"})
 			default:
-				c.JSON(401, gin.H{"error": "Invalid token: " + err.Error()})
+				c.JSON(401, gin.H{"error": "Invalid token"})
 			}
 			c.Abort()
 			return
 		}
 
-		if !token.Valid {
-			c.JSON(401, gin.H{"error": "Invalid token"})
-			c.Abort()
-			return
-		}
+		c.Set(token.ContextKey, claims)
 
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			c.JSON(401, gin.H{"error": "Invalid token claims"})
-			c.Abort()
-			return
-		}
-
-		c.Set("claims", claims)
 		c.Next()
 	}
 }
-
-// Helper function to get claims from gin context
-func GetClaims(c *gin.Context) (jwt.MapClaims, bool) {
-	claims, exists := c.Get("claims")
-	if !exists {
-		return nil, false
-	}
-	mapClaims, ok := claims.(jwt.MapClaims)
-	return mapClaims, ok
-}
-
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"chronospace-be/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRoles only lets requests through whose token carries one of the
+// given roles. It must run after ValidateJWT.
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, ok := utils.GetClaimsFromContext(c)
+		for _, allowed := range roles {
+			if ok && claims.Role == allowed {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(403, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"chronospace-be/internal/utils"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ipFreeAttempts is how many failed sign-ins an address gets before delays
+// kick in. It is higher than the per-account allowance because several
+// users may share an address.
+const ipFreeAttempts = 10
+
+// LoginThrottle limits failed sign-ins per client IP. Failures are taken from
+// the response: a 401 counts as a failure. Successful sign-ins do not clear
+// the address, as anyone can succeed with an account of their own; failures
+// are forgotten once the address has been quiet for the lockout duration.
+// State is kept in memory, so every instance of the server throttles on its
+// own.
+type LoginThrottle struct {
+	mu          sync.Mutex
+	clients     map[string]*loginAttempts
+	maxAttempts int
+	lockout     time.Duration
+	lastSweep   time.Time
+}
+
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	lockedUntil time.Time
+}
+
+func NewLoginThrottle(maxAttempts int, lockout time.Duration) *LoginThrottle {
+	return &LoginThrottle{
+		clients:     make(map[string]*loginAttempts),
+		maxAttempts: maxAttempts,
+		lockout:     lockout,
+	}
+}
+
+func (t *LoginThrottle) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := c.ClientIP()
+
+		if wait := t.retryAfter(ip, time.Now()); wait > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error": "too many failed login attempts from this address, try again later",
+				"code":  "ip_throttled",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+
+		if c.Writer.Status() == http.StatusUnauthorized {
+			t.fail(ip, time.Now())
+		}
+	}
+}
+
+func (t *LoginThrottle) retryAfter(ip string, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	attempts, ok := t.clients[ip]
+	if !ok {
+		return 0
+	}
+
+	if now.Before(attempts.lockedUntil) {
+		return attempts.lockedUntil.Sub(now)
+	}
+
+	retryAt := attempts.lastFailure.Add(utils.LoginBackoff(attempts.failures, ipFreeAttempts))
+	if now.Before(retryAt) {
+		return retryAt.Sub(now)
+	}
+	return 0
+}
+
+func (t *LoginThrottle) fail(ip string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(now)
+
+	attempts, ok := t.clients[ip]
+	if !ok {
+		attempts = &loginAttempts{}
+		t.clients[ip] = attempts
+	}
+
+	attempts.failures++
+	attempts.lastFailure = now
+	if t.maxAttempts > 0 && attempts.failures >= t.maxAttempts {
+		attempts.failures = 0
+		attempts.lockedUntil = now.Add(t.lockout)
+	}
+}
+
+// sweep forgets addresses that are neither locked nor within the backoff
+// window anymore, so the map does not grow without bound.
+func (t *LoginThrottle) sweep(now time.Time) {
+	if now.Sub(t.lastSweep) < time.Minute {
+		return
+	}
+	t.lastSweep = now
+
+	for ip, attempts := range t.clients {
+		idle := now.Sub(attempts.lastFailure) > t.lockout
+		if idle && now.After(attempts.lockedUntil) {
+			delete(t.clients, ip)
+		}
+	}
+}
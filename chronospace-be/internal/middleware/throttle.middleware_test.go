@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testMaxAttempts = 3
+
+// newThrottledLogin serves a login endpoint that succeeds only with the
+// right password, behind a throttle that trusts the given proxies.
+func newThrottledLogin(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	if err := engine.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+
+	throttle := NewLoginThrottle(testMaxAttempts, time.Hour)
+	engine.POST("/login", throttle.Limit(), func(c *gin.Context) {
+		if c.GetHeader("X-Password") == "right" {
+			c.Status(http.StatusOK)
+			return
+		}
+		c.Status(http.StatusUnauthorized)
+	})
+	return engine
+}
+
+func login(engine *gin.Engine, remoteAddr, forwardedFor, password string) int {
+	req := httptest.NewRequest(http.MethodPost, "/login", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	req.Header.Set("X-Password", password)
+
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestLoginThrottleForwardedFor(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           int
+	}{
+		{
+			name:       "spoofed header from a client",
+			remoteAddr: "203.0.113.7:51234",
+			want:       http.StatusTooManyRequests,
+		},
+		{
+			name:           "header set by a trusted proxy",
+			trustedProxies: []string{"10.0.0.0/8"},
+			remoteAddr:     "10.0.0.2:443",
+			want:           http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newThrottledLogin(t, tt.trustedProxies)
+
+			// Every attempt claims to come from another address
+			for i := 0; i < testMaxAttempts; i++ {
+				if code := login(engine, tt.remoteAddr, "198.51.100."+strconv.Itoa(i), "wrong"); code != http.StatusUnauthorized {
+					t.Fatalf("attempt %d = %d, want %d", i+1, code, http.StatusUnauthorized)
+				}
+			}
+
+			if code := login(engine, tt.remoteAddr, "198.51.100.99", "wrong"); code != tt.want {
+				t.Fatalf("next attempt = %d, want %d", code, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoginThrottleSuccessDoesNotReset(t *testing.T) {
+	engine := newThrottledLogin(t, nil)
+	const client = "203.0.113.7:51234"
+
+	for i := 0; i < testMaxAttempts-1; i++ {
+		if code := login(engine, client, "", "wrong"); code != http.StatusUnauthorized {
+			t.Fatalf("attempt %d = %d, want %d", i+1, code, http.StatusUnauthorized)
+		}
+	}
+
+	// A sign-in to an account the client controls must not clear its record
+	if code := login(engine, client, "", "right"); code != http.StatusOK {
+		t.Fatalf("successful attempt = %d, want %d", code, http.StatusOK)
+	}
+	if code := login(engine, client, "", "wrong"); code != http.StatusUnauthorized {
+		t.Fatalf("last free attempt = %d, want %d", code, http.StatusUnauthorized)
+	}
+	if code := login(engine, client, "", "right"); code != http.StatusTooManyRequests {
+		t.Fatalf("attempt after lockout = %d, want %d", code, http.StatusTooManyRequests)
+	}
+}
@@ -1,29 +1,84 @@
 package models
 
 import (
+	"chronospace-be/internal/cancellation"
+	"chronospace-be/internal/pricing"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Booking struct {
-	ID        pgtype.UUID `json:"id"`
-	UserID    pgtype.UUID `json:"user_id"`
-	ServiceID pgtype.UUID `json:"service_id"`
-	Date      pgtype.Date `json:"date"`
-	Time      pgtype.Time `json:"time"`
-	Status    string      `json:"status"`
+	ID           pgtype.UUID `json:"id"`
+	UserID       pgtype.UUID `json:"user_id"`
+	ServiceID    pgtype.UUID `json:"service_id"`
+	CheckIn      pgtype.Date `json:"check_in"`
+	CheckOut     pgtype.Date `json:"check_out"`
+	CheckInTime  pgtype.Time `json:"check_in_time"`
+	CheckOutTime pgtype.Time `json:"check_out_time"`
+	Nights       int         `json:"nights"`
+	Status       string      `json:"status"`
+
+	// TotalAmount is the price locked in when the booking was made or its
+	// dates last changed, in the minor unit of Currency. Bookings made
+	// before pricing rules existed have no breakdown.
+	TotalAmount    int64          `json:"total_amount"`
+	Currency       string         `json:"currency"`
+	PriceBreakdown *pricing.Quote `json:"price_breakdown,omitempty"`
+
+	// CancellationPolicy is the service's policy when the booking was made
+	CancellationPolicy cancellation.Policy `json:"cancellation_policy"`
 }
 
 type CreateBookingParams struct {
-	UserID    pgtype.UUID `json:"user_id"`
-	ServiceID pgtype.UUID `json:"service_id"`
-	Date      pgtype.Date `json:"date"`
-	Time      pgtype.Time `json:"time"`
-	Status    string      `json:"status"`
+	UserID       pgtype.UUID `json:"user_id"`
+	ServiceID    pgtype.UUID `json:"service_id"`
+	CheckIn      pgtype.Date `json:"check_in"`
+	CheckOut     pgtype.Date `json:"check_out"`
+	CheckInTime  pgtype.Time `json:"check_in_time"`
+	CheckOutTime pgtype.Time `json:"check_out_time"`
 }
 
 type UpdateBookingParams struct {
-	ID        pgtype.UUID `json:"id"`
-	Date      pgtype.Date `json:"date"`
-	Time      pgtype.Time `json:"time"`
-	Status    string      `json:"status"`
+	ID           pgtype.UUID `json:"id"`
+	CheckIn      pgtype.Date `json:"check_in"`
+	CheckOut     pgtype.Date `json:"check_out"`
+	CheckInTime  pgtype.Time `json:"check_in_time"`
+	CheckOutTime pgtype.Time `json:"check_out_time"`
+}
+
+type BookingStatusChangeRequest struct {
+	Reason string `json:"reason"`
+}
+
+type BookingStatusChangeParams struct {
+	ID     pgtype.UUID
+	Actor  Actor
+	Reason string
+}
+
+type BookingStatusChange struct {
+	ID         pgtype.UUID      `json:"id"`
+	BookingID  pgtype.UUID      `json:"booking_id"`
+	FromStatus string           `json:"from_status"`
+	ToStatus   string           `json:"to_status"`
+	ChangedBy  pgtype.UUID      `json:"changed_by"`
+	Reason     string           `json:"reason"`
+	ChangedAt  pgtype.Timestamp `json:"changed_at"`
+}
+
+type ProviderBookingsQuery struct {
+	Status string `form:"status" example:"Requested"`
+}
+
+// CancellationQuote tells what a guest would get back when cancelling now.
+// Amounts are in the minor unit of the currency; they are zero while nothing
+// has been charged, since cancelling then only releases the authorization.
+type CancellationQuote struct {
+	Cancelable        bool                `json:"cancelable"`
+	Policy            cancellation.Policy `json:"policy"`
+	DaysBeforeCheckIn int                 `json:"days_before_check_in"`
+	RefundPercent     int                 `json:"refund_percent"`
+	PaidAmount        int64               `json:"paid_amount"`
+	RefundAmount      int64               `json:"refund_amount"`
+	Currency          string              `json:"currency,omitempty"`
 }
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreateCalendarFeedRequest struct {
+	ServiceID pgtype.UUID `json:"service_id" binding:"required"`
+	URL       string      `json:"url" binding:"required" example:"https://www.airbnb.com/calendar/ical/123.ics"`
+}
+
+type CalendarFeedResponse struct {
+	ID           pgtype.UUID      `json:"id"`
+	ServiceID    pgtype.UUID      `json:"service_id"`
+	URL          string           `json:"url"`
+	LastSyncedAt pgtype.Timestamp `json:"last_synced_at"`
+	LastError    string           `json:"last_error"`
+}
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
@@ -17,7 +17,88 @@ var (
 	ErrDeletingUser          = errors.New("cannot to delete user")
 	ErrListingUsers          = errors.New("listing users currently not possible")
 	ErrUserNotFound          = errors.New("no user")
+	ErrInvalidRefreshToken   = errors.New("invalid refresh token")
+	ErrRefreshTokenReused    = errors.New("refresh token reuse detected")
+	ErrInvalidRole           = errors.New("invalid role")
+	ErrForbidden             = errors.New("you are not allowed to perform this action")
 
-	ErrBookingInvalidInput     = errors.New("invalid input")
-	ErrBookingInvalidDateRange = errors.New("invalid date range")
+	ErrEmailNotVerified         = errors.New("email address has not been verified")
+	ErrInvalidVerificationToken = errors.New("invalid or expired verification token")
+	ErrInvalidResetToken        = errors.New("invalid or expired password reset token")
+
+	ErrWrongPassword            = errors.New("current password is incorrect")
+	ErrPasswordUnchanged        = errors.New("new password must differ from the current one")
+	ErrPasswordTooLong          = errors.New("password must be at most 72 bytes")
+	ErrPasswordTooWeak          = errors.New("password must contain at least one letter and one digit")
+	ErrPasswordContainsIdentity = errors.New("password must not contain your username or email")
+
+	ErrSessionNotFound = errors.New("session not found")
+
+	ErrAccountLocked  = errors.New("account is temporarily locked after too many failed login attempts")
+	ErrLoginThrottled = errors.New("too many failed login attempts, try again later")
+
+	ErrInvalidMFAToken   = errors.New("invalid or expired MFA token")
+	ErrInvalidMFACode    = errors.New("invalid authentication code")
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled     = errors.New("two-factor authentication is not enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor enrollment has not been started")
+
+	ErrOIDCProviderNotFound = errors.New("identity provider not found")
+	ErrOIDCInvalidState     = errors.New("invalid or expired sign-in state")
+	ErrOIDCLoginFailed      = errors.New("sign-in with the identity provider failed")
+	ErrOIDCEmailNotVerified = errors.New("the identity provider has not verified your email address")
+	ErrIdentityNotFound     = errors.New("linked identity not found")
+
+	ErrAccountOwnsServices        = errors.New("account still owns services, delete them first")
+	ErrAccountHasUpcomingBookings = errors.New("account has upcoming bookings, cancel them first")
+
+	ErrPaymentRequired          = errors.New("booking has no authorized payment")
+	ErrPaymentDeclined          = errors.New("payment was declined")
+	ErrPaymentAlreadyAuthorized = errors.New("booking is already paid")
+	ErrBookingNotPayable        = errors.New("only requested bookings can be paid")
+	ErrPaymentProviderFailed    = errors.New("payment provider request failed")
+	ErrPaymentProviderNotFound  = errors.New("payment provider not found")
+	ErrInvalidPaymentWebhook    = errors.New("invalid payment webhook")
+
+	ErrInvalidCancellationPolicy = errors.New("invalid cancellation policy")
+
+	ErrPricingRuleNotFound = errors.New("pricing rule not found")
+	ErrInvalidPricingRule  = errors.New("invalid pricing rule")
+	ErrMinimumStayNotMet   = errors.New("stay is shorter than the minimum stay")
+	ErrTooManyGuests       = errors.New("service cannot host that many guests")
+
+	ErrUnknownCurrency      = errors.New("unknown currency")
+	ErrInvalidPrice         = errors.New("invalid price")
+	ErrInvalidExchangeRate  = errors.New("invalid exchange rate")
+	ErrExchangeRateNotFound = errors.New("no exchange rate for the requested currency")
+
+	ErrInvoiceNotAvailable = errors.New("invoices are only issued for accepted bookings")
+
+	ErrBookingInvalidInput       = errors.New("invalid input")
+	ErrBookingInvalidDateRange   = errors.New("invalid date range")
+	ErrBookingConflict           = errors.New("the requested slot is already booked")
+	ErrBookingSlotUnavailable    = errors.New("the requested slot is not available")
+	ErrBookingNotFound           = errors.New("booking not found")
+	ErrBookingNotEditable        = errors.New("only requested bookings can be changed")
+	ErrBookingInvalidTransition  = errors.New("booking status change is not allowed")
+	ErrBookingCancellationClosed = errors.New("booking can no longer be canceled")
+	ErrBookingNotEnded           = errors.New("booking has not ended yet")
+	ErrBookingInvalidStatus      = errors.New("invalid booking status")
+	ErrBookingHasPayments        = errors.New("bookings with payments cannot be deleted, cancel them instead")
+	ErrBookingHasInvoice         = errors.New("invoiced bookings cannot be deleted, cancel them instead")
+
+	ErrServiceNotFound = errors.New("service not found")
+
+	ErrScheduleInvalidStatus     = errors.New("invalid schedule status")
+	ErrScheduleInvalidTimeRange  = errors.New("schedule end time must be after start time")
+	ErrScheduleInvalidWindow     = errors.New("invalid schedule window")
+	ErrScheduleRuleNotFound      = errors.New("schedule rule not found")
+	ErrScheduleRuleStartTooEarly = errors.New("schedule rule cannot start more than a year ago")
+	ErrScheduleBlackoutNotFound  = errors.New("blackout period not found")
+	ErrScheduleInvalidBlackout   = errors.New("blackout period must end on or after its start")
+
+	ErrCalendarFeedNotFound = errors.New("calendar feed not found")
+	ErrCalendarFeedURL      = errors.New("calendar feed URL must be a public http, https or webcal address")
+	ErrCalendarFeedFetch    = errors.New("calendar feed could not be fetched")
+	ErrInvalidCalendarToken = errors.New("invalid calendar token")
 )
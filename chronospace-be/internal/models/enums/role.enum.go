@@ -0,0 +1,7 @@
+package enums
+
+var (
+	GuestRole    = "guest"
+	ProviderRole = "provider"
+	AdminRole    = "admin"
+)
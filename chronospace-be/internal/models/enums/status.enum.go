@@ -3,5 +3,33 @@ package enums
 var (
 	RequestedStatus = "Requested"
 	AcceptedStatus  = "Accepted"
+	RejectedStatus  = "Rejected"
+	CompletedStatus = "Completed"
 	CanceledStatus  = "Canceled"
 )
+
+var (
+	ScheduleAvailableStatus = "Available"
+	ScheduleBlockedStatus   = "Blocked"
+)
+
+var (
+	PaymentAuthorizedStatus        = "authorized"
+	PaymentCapturedStatus          = "captured"
+	PaymentPartiallyRefundedStatus = "partially_refunded"
+	PaymentRefundedStatus          = "refunded"
+	PaymentVoidedStatus            = "voided"
+	PaymentFailedStatus            = "failed"
+)
+
+var (
+	PaymentOperationCapture = "capture"
+	PaymentOperationRefund  = "refund"
+	PaymentOperationRelease = "release"
+)
+
+var (
+	PaymentOperationPendingStatus   = "pending"
+	PaymentOperationSucceededStatus = "succeeded"
+	PaymentOperationFailedStatus    = "failed"
+)
@@ -0,0 +1,8 @@
+package models
+
+// InvoiceDocument is a rendered invoice.
+type InvoiceDocument struct {
+	Number   string
+	FileName string
+	Content  []byte
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+type OIDCProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// IdentityResponse is an external account linked to the user.
+type IdentityResponse struct {
+	Provider  string    `json:"provider"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Payment amounts are in the minor unit of the currency (cents for EUR).
+// Status is authorized, captured, partially_refunded, refunded, voided or
+// failed.
+type Payment struct {
+	ID             pgtype.UUID `json:"id"`
+	BookingID      pgtype.UUID `json:"booking_id"`
+	Provider       string      `json:"provider"`
+	Amount         int64       `json:"amount"`
+	Currency       string      `json:"currency"`
+	Status         string      `json:"status"`
+	CapturedAmount int64       `json:"captured_amount"`
+	RefundedAmount int64       `json:"refunded_amount"`
+	FailureReason  string      `json:"failure_reason,omitempty"`
+	CreatedAt      time.Time   `json:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at"`
+}
+
+type AuthorizePaymentRequest struct {
+	// PaymentMethod is the provider token for the card or account the client
+	// collected.
+	PaymentMethod string `json:"payment_method" binding:"required"`
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"chronospace-be/internal/pricing"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type CreatePricingRuleRequest struct {
+	Kind     string      `json:"kind" binding:"required,oneof=season weekday min_stay length_discount" example:"weekday"`
+	StartsOn pgtype.Date `json:"starts_on"`
+	EndsOn   pgtype.Date `json:"ends_on"`
+	// DaysOfWeek run from 0 (Sunday) to 6 (Saturday)
+	DaysOfWeek        []int32        `json:"days_of_week" example:"5,6"`
+	NightlyPrice      pgtype.Numeric `json:"nightly_price"`
+	AdjustmentPercent int32          `json:"adjustment_percent" example:"20"`
+	MinNights         int32          `json:"min_nights"`
+}
+
+type PricingRuleResponse struct {
+	ID                pgtype.UUID    `json:"id"`
+	ServiceID         pgtype.UUID    `json:"service_id"`
+	Kind              string         `json:"kind"`
+	StartsOn          pgtype.Date    `json:"starts_on"`
+	EndsOn            pgtype.Date    `json:"ends_on"`
+	DaysOfWeek        []int32        `json:"days_of_week,omitempty"`
+	NightlyPrice      pgtype.Numeric `json:"nightly_price"`
+	AdjustmentPercent int32          `json:"adjustment_percent,omitempty"`
+	MinNights         int32          `json:"min_nights,omitempty"`
+}
+
+type PriceQuoteQuery struct {
+	From   time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To     time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	Guests int32     `form:"guests,default=1" binding:"min=1"`
+	// Currency converts the quote for display
+	Currency string `form:"currency" example:"USD"`
+}
+
+// PriceQuote is the itemized price of a stay. Amounts are in the minor unit
+// of the currency (cents for EUR). Converted quotes name the currency the
+// service is paid in and the rate they were converted at.
+type PriceQuote struct {
+	ServiceID       pgtype.UUID    `json:"service_id"`
+	CheckIn         pgtype.Date    `json:"check_in"`
+	CheckOut        pgtype.Date    `json:"check_out"`
+	Guests          int32          `json:"guests"`
+	Currency        string         `json:"currency"`
+	ServiceCurrency string         `json:"service_currency,omitempty"`
+	ExchangeRate    pgtype.Numeric `json:"exchange_rate,omitempty"`
+	pricing.Quote
+}
+
+// Money is a decimal amount in a currency.
+type Money struct {
+	Amount   pgtype.Numeric `json:"amount"`
+	Currency string         `json:"currency" example:"EUR"`
+}
+
+// ExchangeRatesRequest replaces the rates of a base currency. Rates are how
+// many units of the quote currency one unit of base is worth; they are read
+// as exact decimals, quoted or not. EXCHANGE_RATES_FILE uses the same format.
+type ExchangeRatesRequest struct {
+	Base  string                 `json:"base" binding:"required" example:"EUR"`
+	Rates map[string]json.Number `json:"rates" binding:"required" swaggertype:"object,string" example:"USD:1.0832,GBP:0.8561"`
+}
+
+type ExchangeRateResponse struct {
+	Base      string         `json:"base"`
+	Quote     string         `json:"quote"`
+	Rate      pgtype.Numeric `json:"rate"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
@@ -2,6 +2,9 @@ package models
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	// Code is a stable, machine-readable reason for errors clients are
+	// expected to handle specifically.
+	Code string `json:"code,omitempty"`
 }
 
 type SuccessResponse struct {
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -13,18 +15,82 @@ type Schedule struct {
 }
 
 type CreateScheduleRequest struct {
+	ServiceID pgtype.UUID `json:"service_id" binding:"required"`
+	Date      pgtype.Date `json:"date" binding:"required"`
 	StartTime pgtype.Time `json:"start_time" binding:"required"`
 	EndTime   pgtype.Time `json:"end_time" binding:"required"`
+	Status    string      `json:"status"` // "Available" (default) or "Blocked"
 }
 
 type UpdateScheduleRequest struct {
+	Date      pgtype.Date `json:"date"`
 	StartTime pgtype.Time `json:"start_time"`
 	EndTime   pgtype.Time `json:"end_time"`
+	Status    string      `json:"status"`
 }
 
 type ScheduleResponse struct {
 	ID        pgtype.UUID `json:"id"`
+	ServiceID pgtype.UUID `json:"service_id"`
+	Date      pgtype.Date `json:"date"`
 	TimeStart pgtype.Time `json:"time_start"`
 	TimeEnd   pgtype.Time `json:"time_end"`
 	Status    string      `json:"status"`
 }
+
+type CreateScheduleRuleRequest struct {
+	ServiceID pgtype.UUID   `json:"service_id" binding:"required"`
+	StartsOn  pgtype.Date   `json:"starts_on" binding:"required"`
+	StartTime pgtype.Time   `json:"start_time" binding:"required"`
+	EndTime   pgtype.Time   `json:"end_time" binding:"required"`
+	RRule     string        `json:"rrule" binding:"required" example:"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"`
+	ExDates   []pgtype.Date `json:"exdates"`
+	Status    string        `json:"status"` // "Available" (default) or "Blocked"
+}
+
+type ScheduleRuleResponse struct {
+	ID        pgtype.UUID   `json:"id"`
+	ServiceID pgtype.UUID   `json:"service_id"`
+	StartsOn  pgtype.Date   `json:"starts_on"`
+	TimeStart pgtype.Time   `json:"time_start"`
+	TimeEnd   pgtype.Time   `json:"time_end"`
+	RRule     string        `json:"rrule"`
+	ExDates   []pgtype.Date `json:"exdates"`
+	Status    string        `json:"status"`
+}
+
+type ScheduleExceptionRequest struct {
+	Date pgtype.Date `json:"date" binding:"required"`
+}
+
+type CreateScheduleBlackoutRequest struct {
+	ServiceID pgtype.UUID `json:"service_id" binding:"required"`
+	StartsOn  pgtype.Date `json:"starts_on" binding:"required"`
+	EndsOn    pgtype.Date `json:"ends_on" binding:"required"`
+	Reason    string      `json:"reason"`
+}
+
+type ScheduleBlackoutResponse struct {
+	ID        pgtype.UUID `json:"id"`
+	ServiceID pgtype.UUID `json:"service_id"`
+	StartsOn  pgtype.Date `json:"starts_on"`
+	EndsOn    pgtype.Date `json:"ends_on"`
+	Reason    string      `json:"reason"`
+}
+
+type ScheduleSlotsQuery struct {
+	From time.Time `form:"from" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	To   time.Time `form:"to" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+}
+
+// ScheduleSlot is a concrete slot, either stored as a schedule row or
+// generated from a recurring rule.
+type ScheduleSlot struct {
+	ServiceID  pgtype.UUID `json:"service_id"`
+	ScheduleID pgtype.UUID `json:"schedule_id"`
+	RuleID     pgtype.UUID `json:"rule_id"`
+	Date       pgtype.Date `json:"date"`
+	TimeStart  pgtype.Time `json:"time_start"`
+	TimeEnd    pgtype.Time `json:"time_end"`
+	Status     string      `json:"status"`
+}
@@ -1,18 +1,22 @@
 package models
 
 import (
+	"chronospace-be/internal/cancellation"
+	"time"
+
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Service struct {
-	ID          pgtype.UUID `json:"id"`
-	Name        string      `json:"name"`
-	Description string      `json:"description"`
-	Price       float64     `json:"price"`
-	Type        string      `json:"type"` // "hotel" or "apartment"
-	Location    string      `json:"location"`
-	CreatedAt   pgtype.Time `json:"created_at"`
-	UpdatedAt   pgtype.Time `json:"updated_at"`
+	ID          pgtype.UUID    `json:"id"`
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Price       pgtype.Numeric `json:"price"`
+	Currency    string         `json:"currency"`
+	Type        string         `json:"type"` // "hotel" or "apartment"
+	Location    string         `json:"location"`
+	CreatedAt   pgtype.Time    `json:"created_at"`
+	UpdatedAt   pgtype.Time    `json:"updated_at"`
 }
 
 type CreateServiceRequest struct {
@@ -21,6 +25,11 @@ type CreateServiceRequest struct {
 	Price       pgtype.Numeric `json:"price" binding:"required"`
 	Type        string         `json:"type" binding:"required"`
 	Location    string         `json:"location" binding:"required"`
+	MaxGuests   int32          `json:"max_guests" binding:"omitempty,min=1"`
+	// Currency of the price, defaults to DEFAULT_CURRENCY
+	Currency string `json:"currency" example:"EUR"`
+	// CancellationPolicy defaults to the flexible policy
+	CancellationPolicy *cancellation.Policy `json:"cancellation_policy"`
 }
 
 type UpdateServiceRequest struct {
@@ -29,6 +38,10 @@ type UpdateServiceRequest struct {
 	Price       pgtype.Numeric `json:"price"`
 	Type        string         `json:"type"`
 	Location    string         `json:"location"`
+	MaxGuests   int32          `json:"max_guests" binding:"omitempty,min=1"`
+	Currency    string         `json:"currency" example:"EUR"`
+	// CancellationPolicy applies to bookings made after the update
+	CancellationPolicy *cancellation.Policy `json:"cancellation_policy"`
 }
 
 type ServiceResponse struct {
@@ -38,4 +51,33 @@ type ServiceResponse struct {
 	Price       pgtype.Numeric `json:"price"`
 	Type        string         `json:"type"`
 	Location    string         `json:"location"`
+	OwnerID     pgtype.UUID    `json:"owner_id"`
+	MaxGuests   int32          `json:"max_guests"`
+	Currency    string         `json:"currency"`
+	// DisplayPrice is the price in the currency the caller asked for
+	DisplayPrice *Money `json:"display_price,omitempty"`
+
+	CancellationPolicy cancellation.Policy `json:"cancellation_policy"`
+}
+
+type AvailabilitySearchQuery struct {
+	Location string    `form:"location" example:"Berlin"`
+	CheckIn  time.Time `form:"check_in" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	CheckOut time.Time `form:"check_out" binding:"required" time_format:"2006-01-02" time_utc:"1"`
+	Guests   int32     `form:"guests,default=1" binding:"min=1"`
+	MinPrice string    `form:"min_price" binding:"omitempty,numeric"`
+	MaxPrice string    `form:"max_price" binding:"omitempty,numeric"`
+	Limit    int32     `form:"limit,default=20" binding:"min=1,max=100"`
+	Offset   int32     `form:"offset" binding:"min=0"`
+	// Currency converts prices for display. Price bounds are in this
+	// currency, or the default one, and results are sorted in it.
+	Currency string `form:"currency" example:"USD"`
+}
+
+type AvailableServiceResponse struct {
+	ServiceResponse
+	Nights     int            `json:"nights"`
+	TotalPrice pgtype.Numeric `json:"total_price"`
+	// DisplayTotalPrice is the total price in the requested currency
+	DisplayTotalPrice *Money `json:"display_total_price,omitempty"`
 }
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Device describes the client a session was opened from.
+type Device struct {
+	UserAgent string
+	IPAddress string
+}
+
+// SessionResponse is a signed-in device. LastUsedAt is the last time the
+// session refreshed its tokens.
+type SessionResponse struct {
+	ID         pgtype.UUID `json:"id"`
+	UserAgent  string      `json:"user_agent"`
+	IPAddress  string      `json:"ip_address"`
+	CreatedAt  time.Time   `json:"created_at"`
+	LastUsedAt time.Time   `json:"last_used_at"`
+	ExpiresAt  time.Time   `json:"expires_at"`
+	Current    bool        `json:"current"`
+}
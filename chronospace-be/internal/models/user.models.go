@@ -2,6 +2,8 @@ package models
 
 import (
 	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models/enums"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
@@ -11,21 +13,35 @@ type CreateUserParams struct {
 	FullName string `json:"full_name"`
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	Role     string `json:"role"` // "guest" (default) or "provider"
 }
 
 type UserCreatedResponse struct {
 	Message string `json:"message"`
 }
 
+// LoginResponse carries either a token pair or, for accounts with
+// two-factor authentication, an MFA token to exchange at /users/login/mfa.
 type LoginResponse struct {
-	AccessToken string `json:"access_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
 }
 
 type UpdateUserParams struct {
-	Username string
-	FullName string
-	Email    string
-	Password string
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
 }
 
 type UserResponse struct {
@@ -33,6 +49,67 @@ type UserResponse struct {
 	Username string      `json:"username"`
 	FullName string      `json:"full_name"`
 	Email    string      `json:"email"`
+	Role     string      `json:"role"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+	MFAEnabled      bool       `json:"mfa_enabled"`
+	ErasedAt        *time.Time `json:"erased_at,omitempty"`
+}
+
+// UserDataExport is everything stored about a user, as handed out on a data
+// access request.
+type UserDataExport struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	Profile    UserResponse       `json:"profile"`
+	Bookings   []BookingExport    `json:"bookings"`
+	Sessions   []SessionResponse  `json:"sessions"`
+	Identities []IdentityResponse `json:"identities"`
+}
+
+type BookingExport struct {
+	Booking
+	StatusHistory []BookingStatusChange `json:"status_history"`
+}
+
+type ExportQuery struct {
+	Format string `form:"format,default=json" binding:"oneof=json zip"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// Actor identifies the authenticated user a request is made on behalf of.
+type Actor struct {
+	UserID pgtype.UUID
+	Role   string
+}
+
+func (a Actor) IsAdmin() bool {
+	return a.Role == enums.AdminRole
+}
+
+// CanManage reports whether the actor may modify a resource owned by ownerID.
+func (a Actor) CanManage(ownerID pgtype.UUID) bool {
+	return a.IsAdmin() || (ownerID.Valid && ownerID == a.UserID)
 }
 
 type LoginRequest struct {
@@ -0,0 +1,172 @@
+// Package oidctest provides an in-process OpenID provider for tests. It
+// serves discovery, a key set and a token endpoint that checks PKCE, and
+// issues ID tokens with whatever claims a test asks for.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"chronospace-be/internal/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const keyID = "test-key"
+
+// Server is a running test provider. Its issuer is its URL.
+type Server struct {
+	*httptest.Server
+
+	ClientID string
+	key      *rsa.PrivateKey
+
+	mu     sync.Mutex
+	grants map[string]grant
+}
+
+// grant is an authorization code waiting to be redeemed.
+type grant struct {
+	challenge string
+	claims    jwt.MapClaims
+}
+
+// NewServer starts a provider for the given client; it is closed when the
+// test ends.
+func NewServer(t testing.TB, clientID string) *Server {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating key: %v", err)
+	}
+
+	s := &Server{ClientID: clientID, key: key, grants: make(map[string]grant)}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("GET /jwks", s.jwks)
+	mux.HandleFunc("POST /token", s.token)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Issuer returns the issuer identifier of the provider.
+func (s *Server) Issuer() string {
+	return s.URL
+}
+
+// Claims returns valid ID token claims for subject, issued now to the client.
+func (s *Server) Claims(subject string) jwt.MapClaims {
+	now := time.Now()
+	return jwt.MapClaims{
+		"iss": s.Issuer(),
+		"aud": s.ClientID,
+		"sub": subject,
+		"iat": now.Unix(),
+		"exp": now.Add(time.Hour).Unix(),
+	}
+}
+
+// Sign returns claims as an ID token signed with the provider's key.
+func (s *Server) Sign(claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// Authorize plays the user signing in at the authorization URL and returns
+// the code the provider would redirect back with. The ID token carries
+// claims, plus the request's nonce unless claims set one.
+func (s *Server) Authorize(authURL string, claims jwt.MapClaims) string {
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		panic(err)
+	}
+	query := parsed.Query()
+
+	issued := jwt.MapClaims{"nonce": query.Get("nonce")}
+	for name, value := range claims {
+		issued[name] = value
+	}
+
+	code, err := oidc.NewState()
+	if err != nil {
+		panic(err)
+	}
+	s.mu.Lock()
+	s.grants[code] = grant{challenge: query.Get("code_challenge"), claims: issued}
+	s.mu.Unlock()
+	return code
+}
+
+func (s *Server) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{
+		"issuer":                 s.Issuer(),
+		"authorization_endpoint": s.URL + "/authorize",
+		"token_endpoint":         s.URL + "/token",
+		"jwks_uri":               s.URL + "/jwks",
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, _ *http.Request) {
+	public := s.key.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}},
+	})
+}
+
+// token redeems a code once, and only with the verifier matching the
+// challenge it was issued for.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	s.mu.Lock()
+	code := r.PostForm.Get("code")
+	g, ok := s.grants[code]
+	delete(s.grants, code)
+	s.mu.Unlock()
+
+	if r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("client_id") != s.ClientID {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_client"})
+		return
+	}
+	if !ok || oidc.CodeChallengeS256(r.PostForm.Get("code_verifier")) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{
+		"token_type": "Bearer",
+		"id_token":   s.Sign(g.claims),
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
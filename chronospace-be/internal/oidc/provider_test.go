@@ -0,0 +1,180 @@
+package oidc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"chronospace-be/internal/oidc"
+	"chronospace-be/internal/oidc/oidctest"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testClientID = "chronospace"
+
+func newTestProvider(t *testing.T) (*oidc.Provider, *oidctest.Server) {
+	t.Helper()
+
+	server := oidctest.NewServer(t, testClientID)
+	provider := oidc.NewProvider(oidc.Config{
+		Name:        "test",
+		Issuer:      server.Issuer(),
+		ClientID:    testClientID,
+		RedirectURL: "https://api.example.com/v1/api/users/oidc/test/callback",
+	}, server.Client())
+	return provider, server
+}
+
+func TestProviderExchange(t *testing.T) {
+	tests := []struct {
+		name         string
+		claims       func(claims jwt.MapClaims)
+		verifier     string
+		wantErr      error
+		wantVerified bool
+	}{
+		{
+			name: "valid token",
+			claims: func(claims jwt.MapClaims) {
+				claims["email"] = "alice@example.com"
+				claims["email_verified"] = true
+			},
+			wantVerified: true,
+		},
+		{
+			name: "email_verified sent as a string",
+			claims: func(claims jwt.MapClaims) {
+				claims["email_verified"] = "true"
+			},
+			wantVerified: true,
+		},
+		{
+			name: "several audiences with our client as authorized party",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{"other-client", testClientID}
+				claims["azp"] = testClientID
+			},
+		},
+		{
+			name:    "nonce mismatch",
+			claims:  func(claims jwt.MapClaims) { claims["nonce"] = "replayed" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "missing nonce",
+			claims:  func(claims jwt.MapClaims) { claims["nonce"] = "" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "issued to another client",
+			claims:  func(claims jwt.MapClaims) { claims["aud"] = "other-client" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "authorized party is another client",
+			claims: func(claims jwt.MapClaims) {
+				claims["aud"] = []string{testClientID, "other-client"}
+				claims["azp"] = "other-client"
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "issued by another provider",
+			claims:  func(claims jwt.MapClaims) { claims["iss"] = "https://evil.example.com" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name: "expired",
+			claims: func(claims jwt.MapClaims) {
+				claims["iat"] = time.Now().Add(-3 * time.Hour).Unix()
+				claims["exp"] = time.Now().Add(-2 * time.Hour).Unix()
+			},
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "without expiry",
+			claims:  func(claims jwt.MapClaims) { delete(claims, "exp") },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:    "without subject",
+			claims:  func(claims jwt.MapClaims) { claims["sub"] = "" },
+			wantErr: oidc.ErrInvalidIDToken,
+		},
+		{
+			name:     "wrong PKCE verifier",
+			verifier: "not-the-verifier-the-challenge-was-derived-from",
+			wantErr:  oidc.ErrProvider,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, server := newTestProvider(t)
+			ctx := context.Background()
+
+			nonce, _ := oidc.NewState()
+			verifier, _ := oidc.NewCodeVerifier()
+			authURL, err := provider.AuthCodeURL(ctx, "state", nonce, verifier)
+			if err != nil {
+				t.Fatalf("AuthCodeURL: %v", err)
+			}
+
+			claims := server.Claims("alice")
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			code := server.Authorize(authURL, claims)
+
+			if tt.verifier != "" {
+				verifier = tt.verifier
+			}
+			token, err := provider.Exchange(ctx, code, verifier, nonce)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if token.Subject != "alice" || token.EmailVerified != tt.wantVerified {
+				t.Fatalf("Exchange() = %+v", token)
+			}
+		})
+	}
+}
+
+func TestProviderExchangeRedeemsCodeOnce(t *testing.T) {
+	provider, server := newTestProvider(t)
+	ctx := context.Background()
+
+	verifier, _ := oidc.NewCodeVerifier()
+	authURL, err := provider.AuthCodeURL(ctx, "state", "nonce", verifier)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code := server.Authorize(authURL, server.Claims("alice"))
+
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce"); err != nil {
+		t.Fatalf("first Exchange() error = %v", err)
+	}
+	if _, err := provider.Exchange(ctx, code, verifier, "nonce"); !errors.Is(err, oidc.ErrProvider) {
+		t.Fatalf("second Exchange() error = %v, want %v", err, oidc.ErrProvider)
+	}
+}
+
+func TestProviderVerifyIDTokenRejectsUnknownKey(t *testing.T) {
+	provider, server := newTestProvider(t)
+	other := oidctest.NewServer(t, testClientID)
+
+	// Valid claims signed with a key the provider does not publish
+	raw := other.Sign(server.Claims("alice"))
+
+	if _, err := provider.VerifyIDToken(context.Background(), raw, ""); !errors.Is(err, oidc.ErrInvalidIDToken) {
+		t.Fatalf("VerifyIDToken() error = %v, want %v", err, oidc.ErrInvalidIDToken)
+	}
+}
@@ -0,0 +1,172 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// Test payment methods understood by FakeProvider. Any other method is
+// authorized.
+const (
+	FakeMethodDeclined = "fake_declined"
+)
+
+// FakeSignatureHeader carries the hex HMAC-SHA256 of a fake webhook body.
+const FakeSignatureHeader = "X-Fake-Signature"
+
+type fakePayment struct {
+	amount   int64
+	captured int64
+	refunded int64
+	released bool
+}
+
+// FakeProvider keeps payments in memory. It is meant for local development
+// and tests: nothing is charged and all state is lost on restart.
+type FakeProvider struct {
+	webhookSecret []byte
+
+	mu       sync.Mutex
+	payments map[string]*fakePayment
+	// applied holds the idempotency keys of the requests already applied
+	applied map[string]bool
+}
+
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		webhookSecret: []byte(webhookSecret),
+		payments:      make(map[string]*fakePayment),
+		applied:       make(map[string]bool),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return ProviderFake
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, req AuthorizeRequest) (Authorization, error) {
+	if req.PaymentMethod == FakeMethodDeclined {
+		return Authorization{}, ErrDeclined
+	}
+	if req.Amount <= 0 {
+		return Authorization{}, fmt.Errorf("invalid amount %d", req.Amount)
+	}
+
+	id, err := fakeID()
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.payments[id] = &fakePayment{amount: req.Amount}
+
+	return Authorization{ID: id}, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, paymentID string, amount int64, idempotencyKey string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.applied[idempotencyKey] {
+		return nil
+	}
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.released || payment.captured > 0 {
+		return fmt.Errorf("payment %s cannot be captured", paymentID)
+	}
+	if amount <= 0 || amount > payment.amount {
+		return fmt.Errorf("invalid capture amount %d", amount)
+	}
+
+	payment.captured = amount
+	p.applied[idempotencyKey] = true
+	return nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, paymentID string, amount int64, idempotencyKey string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.applied[idempotencyKey] {
+		return nil
+	}
+	payment, ok := p.payments[paymentID]
+	if !ok {
+		return ErrUnknownPayment
+	}
+	if payment.released {
+		return fmt.Errorf("payment %s was released", paymentID)
+	}
+
+	// An uncaptured authorization is released as a whole
+	if payment.captured == 0 {
+		payment.released = true
+		p.applied[idempotencyKey] = true
+		return nil
+	}
+
+	if amount <= 0 || payment.refunded+amount > payment.captured {
+		return fmt.Errorf("invalid refund amount %d", amount)
+	}
+	payment.refunded += amount
+	p.applied[idempotencyKey] = true
+	return nil
+}
+
+type fakeEvent struct {
+	ID        string    `json:"id"`
+	Type      EventType `json:"type"`
+	PaymentID string    `json:"payment_id"`
+	Amount    int64     `json:"amount"`
+}
+
+// VerifyWebhook accepts JSON events signed with the webhook secret, see
+// SignWebhook. Without a secret every event is rejected, as anyone could
+// sign it.
+func (p *FakeProvider) VerifyWebhook(header http.Header, body []byte) (Event, error) {
+	if len(p.webhookSecret) == 0 {
+		return Event{}, ErrInvalidWebhook
+	}
+
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || !hmac.Equal(signature, p.sign(body)) {
+		return Event{}, ErrInvalidWebhook
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.ID == "" || event.PaymentID == "" {
+		return Event{}, ErrInvalidWebhook
+	}
+
+	return Event(event), nil
+}
+
+// SignWebhook returns the signature header value for a fake webhook body.
+func (p *FakeProvider) SignWebhook(body []byte) string {
+	return hex.EncodeToString(p.sign(body))
+}
+
+func (p *FakeProvider) sign(body []byte) []byte {
+	mac := hmac.New(sha256.New, p.webhookSecret)
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+func fakeID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "fake_" + hex.EncodeToString(b), nil
+}
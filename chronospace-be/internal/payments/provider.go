@@ -0,0 +1,90 @@
+// Package payments talks to payment service providers. Amounts are integers
+// in the minor unit of their currency (cents for EUR).
+package payments
+
+import (
+	"chronospace-be/internal/config"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrDeclined is returned when the provider refuses to authorize a
+	// payment, e.g. for insufficient funds.
+	ErrDeclined = errors.New("payment declined")
+	// ErrInvalidWebhook is returned for webhook requests whose signature or
+	// payload cannot be verified.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+	// ErrUnknownPayment is returned for payment IDs the provider does not know.
+	ErrUnknownPayment = errors.New("unknown payment")
+)
+
+type AuthorizeRequest struct {
+	// Reference identifies the payment on our side, e.g. the booking ID.
+	Reference string
+	Amount    int64
+	Currency  string
+	// PaymentMethod is the provider's token for the card or account the
+	// client collected.
+	PaymentMethod string
+	Description   string
+}
+
+// Authorization is a hold on the customer's funds that can later be
+// captured or released.
+type Authorization struct {
+	ID string
+}
+
+type EventType string
+
+const (
+	EventAuthorized EventType = "payment.authorized"
+	EventCaptured   EventType = "payment.captured"
+	EventRefunded   EventType = "payment.refunded"
+	EventFailed     EventType = "payment.failed"
+)
+
+// Event is a verified webhook notification about a payment.
+type Event struct {
+	ID        string
+	Type      EventType
+	PaymentID string
+	// Amount is the amount the event refers to: the captured amount, or for
+	// refunds the total refunded so far.
+	Amount int64
+}
+
+// Provider is a payment service provider. Implementations must be safe for
+// concurrent use. Captures and refunds carry an idempotency key: a request
+// with a key the provider has already seen succeeds without moving money
+// again, so failed calls can be retried.
+type Provider interface {
+	Name() string
+	// Authorize places a hold of req.Amount on the payment method.
+	Authorize(ctx context.Context, req AuthorizeRequest) (Authorization, error)
+	// Capture collects amount of an authorized payment.
+	Capture(ctx context.Context, paymentID string, amount int64, idempotencyKey string) error
+	// Refund returns amount of a captured payment, or releases the hold of
+	// an authorized one that was never captured.
+	Refund(ctx context.Context, paymentID string, amount int64, idempotencyKey string) error
+	// VerifyWebhook checks the signature of a webhook request and decodes it.
+	VerifyWebhook(header http.Header, body []byte) (Event, error)
+}
+
+const (
+	ProviderFake = "fake"
+)
+
+// New returns the provider selected by PAYMENT_PROVIDER.
+func New(cfg *config.Config) (Provider, error) {
+	switch strings.ToLower(cfg.PaymentProvider) {
+	case ProviderFake:
+		return NewFakeProvider(cfg.PaymentWebhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
@@ -0,0 +1,221 @@
+// Package pricing computes the price of a stay from a service's base nightly
+// rate and its pricing rules. Amounts are integers in the minor unit of the
+// currency (cents for EUR).
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidRule is returned for rules that are missing the fields their
+	// kind needs or carry values out of range.
+	ErrInvalidRule = errors.New("invalid pricing rule")
+	// ErrInvalidStay is returned for stays without any night.
+	ErrInvalidStay = errors.New("stay must be at least one night")
+	// ErrMinimumStay is returned when a minimum stay rule is not met.
+	ErrMinimumStay = errors.New("stay is shorter than the minimum stay")
+)
+
+const (
+	// Season replaces the nightly rate between StartsOn and EndsOn.
+	Season = "season"
+	// Weekday adds Percent to the nightly rate on the given days of the
+	// week, e.g. a weekend surcharge.
+	Weekday = "weekday"
+	// MinStay requires stays checking in during the rule's period to last
+	// at least MinNights.
+	MinStay = "min_stay"
+	// LengthDiscount takes Percent off stays of at least MinNights, e.g. a
+	// weekly or monthly discount.
+	LengthDiscount = "length_discount"
+)
+
+const dateLayout = "2006-01-02"
+
+// Rule is a pricing rule. StartsOn and EndsOn bound the nights (or for
+// MinStay and LengthDiscount the check-in dates) the rule applies to, both
+// inclusive; zero values leave that side open.
+type Rule struct {
+	Kind         string
+	StartsOn     time.Time
+	EndsOn       time.Time
+	DaysOfWeek   []time.Weekday
+	NightlyPrice int64
+	Percent      int
+	MinNights    int
+}
+
+// Line is a named amount of a quote.
+type Line struct {
+	Description string `json:"description"`
+	Amount      int64  `json:"amount"`
+}
+
+// Night is the price of a single night of a stay.
+type Night struct {
+	Date        string `json:"date"`
+	Rate        int64  `json:"rate"`
+	Adjustments []Line `json:"adjustments,omitempty"`
+	Amount      int64  `json:"amount"`
+}
+
+// Quote is the itemized price of a stay.
+type Quote struct {
+	Nights    []Night `json:"nights"`
+	Subtotal  int64   `json:"subtotal"`
+	Discounts []Line  `json:"discounts,omitempty"`
+	Total     int64   `json:"total"`
+}
+
+// Validate checks that a rule has the fields its kind needs.
+func Validate(rule Rule) error {
+	if !rule.StartsOn.IsZero() && !rule.EndsOn.IsZero() && rule.EndsOn.Before(rule.StartsOn) {
+		return fmt.Errorf("%w: ends_on is before starts_on", ErrInvalidRule)
+	}
+
+	switch rule.Kind {
+	case Season:
+		if rule.StartsOn.IsZero() || rule.EndsOn.IsZero() {
+			return fmt.Errorf("%w: seasons need starts_on and ends_on", ErrInvalidRule)
+		}
+		if rule.NightlyPrice <= 0 {
+			return fmt.Errorf("%w: seasons need a positive nightly_price", ErrInvalidRule)
+		}
+	case Weekday:
+		if len(rule.DaysOfWeek) == 0 {
+			return fmt.Errorf("%w: weekday rules need days_of_week", ErrInvalidRule)
+		}
+		for _, day := range rule.DaysOfWeek {
+			if day < time.Sunday || day > time.Saturday {
+				return fmt.Errorf("%w: days_of_week run from 0 (Sunday) to 6 (Saturday)", ErrInvalidRule)
+			}
+		}
+		if rule.Percent == 0 || rule.Percent < -100 || rule.Percent > 1000 {
+			return fmt.Errorf("%w: weekday rules need a percent between -100 and 1000", ErrInvalidRule)
+		}
+	case MinStay:
+		if rule.MinNights < 1 {
+			return fmt.Errorf("%w: minimum stays need min_nights", ErrInvalidRule)
+		}
+	case LengthDiscount:
+		if rule.MinNights < 2 {
+			return fmt.Errorf("%w: length discounts need min_nights of at least 2", ErrInvalidRule)
+		}
+		if rule.Percent < 1 || rule.Percent > 100 {
+			return fmt.Errorf("%w: length discounts need a percent between 1 and 100", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidRule, rule.Kind)
+	}
+
+	return nil
+}
+
+// Calculate prices the nights from checkIn up to checkOut. Every night costs
+// the base rate, or the rate of the latest starting season covering it, plus
+// all weekday adjustments of that day. The largest length discount the stay
+// qualifies for is then taken off the subtotal.
+func Calculate(baseRate int64, rules []Rule, checkIn, checkOut time.Time) (Quote, error) {
+	checkIn = truncateDay(checkIn)
+	checkOut = truncateDay(checkOut)
+	nights := int(checkOut.Sub(checkIn).Hours() / 24)
+	if nights < 1 {
+		return Quote{}, ErrInvalidStay
+	}
+
+	for _, rule := range rules {
+		if rule.Kind == MinStay && rule.covers(checkIn) && nights < rule.MinNights {
+			return Quote{}, fmt.Errorf("%w of %d nights", ErrMinimumStay, rule.MinNights)
+		}
+	}
+
+	quote := Quote{Nights: make([]Night, 0, nights)}
+	for date := checkIn; date.Before(checkOut); date = date.AddDate(0, 0, 1) {
+		night := Night{Date: date.Format(dateLayout), Rate: baseRate}
+
+		var season *Rule
+		for i := range rules {
+			rule := &rules[i]
+			if rule.Kind == Season && rule.covers(date) && (season == nil || rule.StartsOn.After(season.StartsOn)) {
+				season = rule
+			}
+		}
+		if season != nil {
+			night.Rate = season.NightlyPrice
+		}
+
+		night.Amount = night.Rate
+		for _, rule := range rules {
+			if rule.Kind != Weekday || !rule.covers(date) || !rule.onDay(date.Weekday()) {
+				continue
+			}
+			adjustment := percentOf(night.Rate, rule.Percent)
+			night.Adjustments = append(night.Adjustments, Line{
+				Description: fmt.Sprintf("%s %+d%%", date.Weekday(), rule.Percent),
+				Amount:      adjustment,
+			})
+			night.Amount += adjustment
+		}
+		night.Amount = max(night.Amount, 0)
+
+		quote.Nights = append(quote.Nights, night)
+		quote.Subtotal += night.Amount
+	}
+
+	var discount *Rule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.Kind == LengthDiscount && rule.covers(checkIn) && nights >= rule.MinNights &&
+			(discount == nil || rule.MinNights > discount.MinNights) {
+			discount = rule
+		}
+	}
+
+	quote.Total = quote.Subtotal
+	if discount != nil {
+		amount := percentOf(quote.Subtotal, discount.Percent)
+		quote.Discounts = append(quote.Discounts, Line{
+			Description: fmt.Sprintf("%d+ nights -%d%%", discount.MinNights, discount.Percent),
+			Amount:      -amount,
+		})
+		quote.Total -= amount
+	}
+
+	return quote, nil
+}
+
+// covers reports whether date lies within the rule's period.
+func (r Rule) covers(date time.Time) bool {
+	if !r.StartsOn.IsZero() && date.Before(truncateDay(r.StartsOn)) {
+		return false
+	}
+	if !r.EndsOn.IsZero() && date.After(truncateDay(r.EndsOn)) {
+		return false
+	}
+	return true
+}
+
+func (r Rule) onDay(day time.Weekday) bool {
+	for _, d := range r.DaysOfWeek {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// percentOf returns percent of amount, rounded half away from zero.
+func percentOf(amount int64, percent int) int64 {
+	product := amount * int64(percent)
+	if product < 0 {
+		return (product - 50) / 100
+	}
+	return (product + 50) / 100
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
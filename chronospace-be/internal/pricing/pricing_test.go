@@ -0,0 +1,82 @@
+package pricing
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentOf(t *testing.T) {
+	tests := []struct {
+		amount  int64
+		percent int
+		want    int64
+	}{
+		{amount: 10000, percent: 15, want: 1500},
+		{amount: 999, percent: 10, want: 100},
+		{amount: 994, percent: 10, want: 99},
+		{amount: 995, percent: 10, want: 100},
+		{amount: 5, percent: 10, want: 1},
+		{amount: 4, percent: 10, want: 0},
+		{amount: 995, percent: -10, want: -100},
+		{amount: 994, percent: -10, want: -99},
+		{amount: 12345, percent: 0, want: 0},
+		{amount: 12345, percent: 100, want: 12345},
+		{amount: 333, percent: 1000, want: 3330},
+	}
+
+	for _, tt := range tests {
+		if got := percentOf(tt.amount, tt.percent); got != tt.want {
+			t.Errorf("percentOf(%d, %d) = %d, want %d", tt.amount, tt.percent, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateRounding(t *testing.T) {
+	// 2024-03-01 is a Friday
+	checkIn := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		baseRate int64
+		rules    []Rule
+		nights   int
+		want     int64
+	}{
+		{
+			name:     "weekday surcharge rounds each night",
+			baseRate: 9995,
+			rules:    []Rule{{Kind: Weekday, DaysOfWeek: []time.Weekday{time.Friday, time.Saturday}, Percent: 15}},
+			nights:   2,
+			// 9995 * 15% = 1499.25, so each night costs 11494
+			want: 22988,
+		},
+		{
+			name:     "weekday discount rounds away from zero",
+			baseRate: 1005,
+			rules:    []Rule{{Kind: Weekday, DaysOfWeek: []time.Weekday{time.Friday}, Percent: -10}},
+			nights:   1,
+			// 1005 * -10% = -100.5, taken off as 101
+			want: 904,
+		},
+		{
+			name:     "length discount rounds on the subtotal",
+			baseRate: 3333,
+			rules:    []Rule{{Kind: LengthDiscount, MinNights: 3, Percent: 5}},
+			nights:   3,
+			// 9999 * 5% = 499.95, taken off as 500
+			want: 9499,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quote, err := Calculate(tt.baseRate, tt.rules, checkIn, checkIn.AddDate(0, 0, tt.nights))
+			if err != nil {
+				t.Fatalf("Calculate() error = %v", err)
+			}
+			if quote.Total != tt.want {
+				t.Fatalf("Calculate() total = %d, want %d", quote.Total, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"chronospace-be/internal/config"
 	"chronospace-be/internal/controllers"
 	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
 
 	"github.com/gin-gonic/gin"
 )
@@ -21,17 +22,27 @@ func newBookingRouter(bookingController *controllers.BookingController, config *
 func (br *bookingRouter) setBookingRoutes(rg *gin.RouterGroup) {
 	router := rg.Group("bookings")
 
-	// Public routes
-	router.GET("", br.bookingController.ListBookings)
-	router.GET("/:id", br.bookingController.GetBooking)
-
 	// Protected routes
 	protected := router.Group("")
 	protected.Use(br.jwtMiddleware.ValidateJWT())
 	{
 		protected.POST("", br.bookingController.CreateBooking)
 		protected.GET("/user", br.bookingController.ListUserBookings)
+		protected.GET("/:id", br.bookingController.GetBooking)
 		protected.PUT("/:id", br.bookingController.UpdateBooking)
-		protected.DELETE("/:id", br.bookingController.DeleteBooking)
+		protected.GET("/:id/history", br.bookingController.ListBookingHistory)
+		protected.GET("/:id/cancellation", br.bookingController.QuoteCancellation)
+		protected.POST("/:id/accept", br.bookingController.AcceptBooking)
+		protected.POST("/:id/reject", br.bookingController.RejectBooking)
+		protected.POST("/:id/cancel", br.bookingController.CancelBooking)
+		protected.POST("/:id/complete", br.bookingController.CompleteBooking)
+	}
+
+	// Admin routes
+	admin := router.Group("")
+	admin.Use(br.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.AdminRole))
+	{
+		admin.GET("", br.bookingController.ListBookings)
+		admin.DELETE("/:id", br.bookingController.DeleteBooking)
 	}
 }
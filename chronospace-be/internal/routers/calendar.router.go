@@ -0,0 +1,46 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type calendarRouter struct {
+	calendarController *controllers.CalendarController
+	config             *config.Config
+	jwtMiddleware      *middleware.JWTConfig
+}
+
+func newCalendarRouter(calendarController *controllers.CalendarController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *calendarRouter {
+	return &calendarRouter{calendarController, config, jwtMiddleware}
+}
+
+func (cr *calendarRouter) setCalendarRoutes(rg *gin.RouterGroup) {
+	// Service feeds live next to the other service routes
+	rg.GET("/services/:id/calendar.ics", cr.calendarController.ServiceCalendar)
+
+	router := rg.Group("calendar")
+
+	// Public routes, authorized by the secret token in the URL
+	router.GET("/:token/bookings.ics", cr.calendarController.UserCalendar)
+
+	// Protected routes
+	protected := router.Group("")
+	protected.Use(cr.jwtMiddleware.ValidateJWT())
+	{
+		protected.POST("/token", cr.calendarController.RotateToken)
+	}
+
+	providers := router.Group("")
+	providers.Use(cr.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.ProviderRole, enums.AdminRole))
+	{
+		providers.POST("/feeds", cr.calendarController.CreateFeed)
+		providers.GET("/services/:id/feeds", cr.calendarController.ListFeeds)
+		providers.POST("/feeds/:id/sync", cr.calendarController.SyncFeed)
+		providers.DELETE("/feeds/:id", cr.calendarController.DeleteFeed)
+	}
+}
@@ -0,0 +1,34 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type exchangeRateRouter struct {
+	exchangeRateController *controllers.ExchangeRateController
+	config                 *config.Config
+	jwtMiddleware          *middleware.JWTConfig
+}
+
+func newExchangeRateRouter(exchangeRateController *controllers.ExchangeRateController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *exchangeRateRouter {
+	return &exchangeRateRouter{exchangeRateController, config, jwtMiddleware}
+}
+
+func (er *exchangeRateRouter) setExchangeRateRoutes(rg *gin.RouterGroup) {
+	router := rg.Group("exchange-rates")
+
+	// Public routes
+	router.GET("", er.exchangeRateController.ListRates)
+
+	// Protected routes
+	protected := router.Group("")
+	protected.Use(er.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.AdminRole))
+	{
+		protected.PUT("", er.exchangeRateController.ReplaceRates)
+	}
+}
@@ -0,0 +1,28 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type invoiceRouter struct {
+	invoiceController *controllers.InvoiceController
+	config            *config.Config
+	jwtMiddleware     *middleware.JWTConfig
+}
+
+func newInvoiceRouter(invoiceController *controllers.InvoiceController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *invoiceRouter {
+	return &invoiceRouter{invoiceController, config, jwtMiddleware}
+}
+
+func (ir *invoiceRouter) setInvoiceRoutes(rg *gin.RouterGroup) {
+	// Invoices live next to the other booking routes
+	bookings := rg.Group("bookings")
+	bookings.Use(ir.jwtMiddleware.ValidateJWT())
+	{
+		bookings.GET("/:id/invoice.pdf", ir.invoiceController.GetInvoice)
+	}
+}
@@ -0,0 +1,36 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type oidcRouter struct {
+	oidcController *controllers.OIDCController
+	config         *config.Config
+	jwtMiddleware  *middleware.JWTConfig
+}
+
+func newOIDCRouter(oidcController *controllers.OIDCController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *oidcRouter {
+	return &oidcRouter{oidcController, config, jwtMiddleware}
+}
+
+func (or *oidcRouter) setOIDCRoutes(rg *gin.RouterGroup) {
+	router := rg.Group("users/oidc")
+
+	// Public routes
+	router.GET("/providers", or.oidcController.Providers)
+	router.GET("/:provider/login", or.oidcController.Login)
+	router.GET("/:provider/callback", or.oidcController.Callback)
+
+	// Protected routes
+	protected := rg.Group("users/me/identities")
+	protected.Use(or.jwtMiddleware.ValidateJWT())
+	{
+		protected.GET("", or.oidcController.ListIdentities)
+		protected.DELETE("/:provider", or.oidcController.UnlinkIdentity)
+	}
+}
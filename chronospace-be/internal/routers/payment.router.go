@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+type paymentRouter struct {
+	paymentController *controllers.PaymentController
+	config            *config.Config
+	jwtMiddleware     *middleware.JWTConfig
+}
+
+func newPaymentRouter(paymentController *controllers.PaymentController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *paymentRouter {
+	return &paymentRouter{paymentController, config, jwtMiddleware}
+}
+
+func (pr *paymentRouter) setPaymentRoutes(rg *gin.RouterGroup) {
+	// Booking payments live next to the other booking routes
+	bookings := rg.Group("bookings")
+	bookings.Use(pr.jwtMiddleware.ValidateJWT())
+	{
+		bookings.POST("/:id/payments", pr.paymentController.AuthorizePayment)
+		bookings.GET("/:id/payments", pr.paymentController.ListPayments)
+	}
+
+	// Public routes, authorized by the provider's webhook signature. Without
+	// a secret no signature can be trusted, so webhooks are not served
+	if pr.config.PaymentWebhookSecret == "" {
+		return
+	}
+	router := rg.Group("payments")
+	router.POST("/webhooks/:provider", pr.paymentController.Webhook)
+}
@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pricingRouter struct {
+	pricingController *controllers.PricingController
+	config            *config.Config
+	jwtMiddleware     *middleware.JWTConfig
+}
+
+func newPricingRouter(pricingController *controllers.PricingController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *pricingRouter {
+	return &pricingRouter{pricingController, config, jwtMiddleware}
+}
+
+func (pr *pricingRouter) setPricingRoutes(rg *gin.RouterGroup) {
+	// Pricing lives next to the other service routes
+	router := rg.Group("services")
+
+	// Public routes
+	router.GET("/:id/quote", pr.pricingController.Quote)
+	router.GET("/:id/pricing-rules", pr.pricingController.ListRules)
+
+	// Protected routes
+	protected := router.Group("")
+	protected.Use(pr.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.ProviderRole, enums.AdminRole))
+	{
+		protected.POST("/:id/pricing-rules", pr.pricingController.CreateRule)
+		protected.DELETE("/:id/pricing-rules/:rule_id", pr.pricingController.DeleteRule)
+	}
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"chronospace-be/internal/config"
+	"chronospace-be/internal/controllers"
+	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
+	"github.com/gin-gonic/gin"
+)
+
+type providerRouter struct {
+	providerController *controllers.ProviderController
+	config             *config.Config
+	jwtMiddleware      *middleware.JWTConfig
+}
+
+func newProviderRouter(providerController *controllers.ProviderController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *providerRouter {
+	return &providerRouter{providerController, config, jwtMiddleware}
+}
+
+func (pr *providerRouter) setProviderRoutes(rg *gin.RouterGroup) {
+	router := rg.Group("providers")
+	router.Use(pr.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.ProviderRole, enums.AdminRole))
+	{
+		router.GET("/me/services", pr.providerController.ListMyServices)
+		router.GET("/me/services/:id/bookings", pr.providerController.ListServiceBookings)
+		router.GET("/me/bookings", pr.providerController.ListMyBookings)
+	}
+}
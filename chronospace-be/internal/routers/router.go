@@ -5,6 +5,7 @@ import (
 	"chronospace-be/internal/controllers"
 	"chronospace-be/internal/middleware"
 	"fmt"
+	"log"
 	"net/http"
 
 	"github.com/gin-contrib/cors"
@@ -19,15 +20,27 @@ type Router struct {
 	Gin    *gin.Engine
 	config *config.Config
 
-	authRouter     *userRouter
-	bookingRouter  *bookingRouter
-	scheduleRouter *scheduleRouter
-	serviceRouter  *serviceRouter
-	mapsRouter     *mapsRouter
+	authRouter         *userRouter
+	bookingRouter      *bookingRouter
+	scheduleRouter     *scheduleRouter
+	serviceRouter      *serviceRouter
+	mapsRouter         *mapsRouter
+	providerRouter     *providerRouter
+	calendarRouter     *calendarRouter
+	oidcRouter         *oidcRouter
+	paymentRouter      *paymentRouter
+	pricingRouter      *pricingRouter
+	exchangeRateRouter *exchangeRateRouter
+	invoiceRouter      *invoiceRouter
+
+	keysController *controllers.KeysController
 }
 
 func NewRouter(config *config.Config, controller *controllers.Controller, jwtMiddleware *middleware.JWTConfig) *Router {
-	ginRouter := gin.Default()
+	ginRouter, err := newEngine(config)
+	if err != nil {
+		log.Fatalf("invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	ginRouter.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{config.WebappBaseUrl},
@@ -42,14 +55,33 @@ func NewRouter(config *config.Config, controller *controllers.Controller, jwtMid
 	})
 
 	return &Router{
-		Gin:            ginRouter,
-		config:         config,
-		authRouter:     newUserRouter(controller.UserController, config, jwtMiddleware),
-		bookingRouter:  newBookingRouter(controller.BookingController, config, jwtMiddleware),
-		scheduleRouter: newScheduleRouter(controller.ScheduleController, config, jwtMiddleware),
-		serviceRouter:  newServiceRouter(controller.ServiceController, config, jwtMiddleware),
-		mapsRouter:     newMapsRouter(controller.MapsController, config, jwtMiddleware),
+		Gin:                ginRouter,
+		config:             config,
+		authRouter:         newUserRouter(controller.UserController, config, jwtMiddleware),
+		bookingRouter:      newBookingRouter(controller.BookingController, config, jwtMiddleware),
+		scheduleRouter:     newScheduleRouter(controller.ScheduleController, config, jwtMiddleware),
+		serviceRouter:      newServiceRouter(controller.ServiceController, config, jwtMiddleware),
+		mapsRouter:         newMapsRouter(controller.MapsController, config, jwtMiddleware),
+		providerRouter:     newProviderRouter(controller.ProviderController, config, jwtMiddleware),
+		calendarRouter:     newCalendarRouter(controller.CalendarController, config, jwtMiddleware),
+		oidcRouter:         newOIDCRouter(controller.OIDCController, config, jwtMiddleware),
+		paymentRouter:      newPaymentRouter(controller.PaymentController, config, jwtMiddleware),
+		pricingRouter:      newPricingRouter(controller.PricingController, config, jwtMiddleware),
+		exchangeRateRouter: newExchangeRateRouter(controller.ExchangeRateController, config, jwtMiddleware),
+		invoiceRouter:      newInvoiceRouter(controller.InvoiceController, config, jwtMiddleware),
+		keysController:     controller.KeysController,
+	}
+}
+
+// newEngine returns a gin engine that only believes X-Forwarded-For from the
+// configured proxies. Gin trusts every client by default, which would let
+// them pick the IP that sign-ins are throttled and sessions recorded by.
+func newEngine(config *config.Config) (*gin.Engine, error) {
+	engine := gin.Default()
+	if err := engine.SetTrustedProxies(config.TrustedProxies); err != nil {
+		return nil, err
 	}
+	return engine, nil
 }
 
 func (r *Router) SetRoutes() {
@@ -59,6 +91,16 @@ func (r *Router) SetRoutes() {
 	r.scheduleRouter.setScheduleRoutes(api)
 	r.serviceRouter.setServiceRoutes(api)
 	r.mapsRouter.setMapsRoutes(api)
+	r.providerRouter.setProviderRoutes(api)
+	r.calendarRouter.setCalendarRoutes(api)
+	r.oidcRouter.setOIDCRoutes(api)
+	r.paymentRouter.setPaymentRoutes(api)
+	r.pricingRouter.setPricingRoutes(api)
+	r.exchangeRateRouter.setExchangeRateRoutes(api)
+	r.invoiceRouter.setInvoiceRoutes(api)
+
+	// Public keys for verifying access tokens, at the conventional location
+	r.Gin.GET("/.well-known/jwks.json", r.keysController.JWKS)
 
 	if r.config.EnvType != "prod" {
 		r.Gin.GET("/docs/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
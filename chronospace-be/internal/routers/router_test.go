@@ -0,0 +1,46 @@
+package routers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"chronospace-be/internal/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewEngineClientIP(t *testing.T) {
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		remoteAddr     string
+		want           string
+	}{
+		{name: "no proxy configured", remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "untrusted proxy", trustedProxies: []string{"10.0.0.1"}, remoteAddr: "203.0.113.7:51234", want: "203.0.113.7"},
+		{name: "trusted proxy", trustedProxies: []string{"10.0.0.0/8"}, remoteAddr: "10.0.0.2:443", want: "198.51.100.4"},
+	}
+
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := newEngine(&config.Config{TrustedProxies: tt.trustedProxies})
+			if err != nil {
+				t.Fatalf("newEngine() error = %v", err)
+			}
+
+			var got string
+			engine.GET("/ip", func(c *gin.Context) { got = c.ClientIP() })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "198.51.100.4")
+			engine.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Fatalf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
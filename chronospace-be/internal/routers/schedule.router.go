@@ -4,14 +4,15 @@ import (
 	"chronospace-be/internal/config"
 	"chronospace-be/internal/controllers"
 	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
 
 	"github.com/gin-gonic/gin"
 )
 
 type scheduleRouter struct {
 	scheduleController *controllers.ScheduleController
-	config            *config.Config
-	jwtMiddleware     *middleware.JWTConfig
+	config             *config.Config
+	jwtMiddleware      *middleware.JWTConfig
 }
 
 func newScheduleRouter(scheduleController *controllers.ScheduleController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *scheduleRouter {
@@ -24,13 +25,24 @@ func (sr *scheduleRouter) setScheduleRoutes(rg *gin.RouterGroup) {
 	// Public routes
 	router.GET("", sr.scheduleController.ListSchedules)
 	router.GET("/:id", sr.scheduleController.GetSchedule)
+	router.GET("/services/:id/slots", sr.scheduleController.ListServiceSlots)
+	router.GET("/services/:id/rules", sr.scheduleController.ListServiceRules)
+	router.GET("/services/:id/blackouts", sr.scheduleController.ListServiceBlackouts)
 
 	// Protected routes
 	protected := router.Group("")
-	protected.Use(sr.jwtMiddleware.ValidateJWT())
+	protected.Use(sr.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.ProviderRole, enums.AdminRole))
 	{
 		protected.POST("", sr.scheduleController.CreateSchedule)
 		protected.PUT("/:id", sr.scheduleController.UpdateSchedule)
 		protected.DELETE("/:id", sr.scheduleController.DeleteSchedule)
+
+		protected.POST("/rules", sr.scheduleController.CreateRule)
+		protected.DELETE("/rules/:id", sr.scheduleController.DeleteRule)
+		protected.POST("/rules/:id/exceptions", sr.scheduleController.AddRuleException)
+		protected.DELETE("/rules/:id/exceptions/:date", sr.scheduleController.RemoveRuleException)
+
+		protected.POST("/blackouts", sr.scheduleController.CreateBlackout)
+		protected.DELETE("/blackouts/:id", sr.scheduleController.DeleteBlackout)
 	}
-}
\ No newline at end of file
+}
@@ -4,13 +4,14 @@ import (
 	"chronospace-be/internal/config"
 	"chronospace-be/internal/controllers"
 	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
 	"github.com/gin-gonic/gin"
 )
 
 type serviceRouter struct {
 	serviceController *controllers.ServiceController
-	config           *config.Config
-	jwtMiddleware    *middleware.JWTConfig
+	config            *config.Config
+	jwtMiddleware     *middleware.JWTConfig
 }
 
 func newServiceRouter(serviceController *controllers.ServiceController, config *config.Config, jwtMiddleware *middleware.JWTConfig) *serviceRouter {
@@ -22,14 +23,15 @@ func (sr *serviceRouter) setServiceRoutes(rg *gin.RouterGroup) {
 
 	// Public routes
 	router.GET("", sr.serviceController.ListServices)
+	router.GET("/availability", sr.serviceController.SearchAvailability)
 	router.GET("/:id", sr.serviceController.GetService)
 
 	// Protected routes
 	protected := router.Group("")
-	protected.Use(sr.jwtMiddleware.ValidateJWT())
+	protected.Use(sr.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.ProviderRole, enums.AdminRole))
 	{
 		protected.POST("", sr.serviceController.CreateService)
 		protected.PUT("/:id", sr.serviceController.UpdateService)
 		protected.DELETE("/:id", sr.serviceController.DeleteService)
 	}
-}
\ No newline at end of file
+}
@@ -4,6 +4,7 @@ import (
 	"chronospace-be/internal/config"
 	"chronospace-be/internal/controllers"
 	"chronospace-be/internal/middleware"
+	"chronospace-be/internal/models/enums"
 
 	"github.com/gin-gonic/gin"
 )
@@ -23,16 +24,44 @@ func (ar *userRouter) setUserRoutes(rg *gin.RouterGroup) {
 
 	// Public routes
 	router.POST("/register", ar.userController.Register)
-	router.POST("/login", ar.userController.Login)
+	loginThrottle := middleware.NewLoginThrottle(ar.config.LoginIPMaxAttempts, ar.config.LoginLockoutDuration)
+	router.POST("/login", loginThrottle.Limit(), ar.userController.Login)
+	router.POST("/login/mfa", loginThrottle.Limit(), ar.userController.LoginMFA)
+	router.POST("/refresh", ar.userController.Refresh)
+	router.POST("/verify", ar.userController.VerifyEmail)
+	router.POST("/verify/resend", ar.userController.ResendVerification)
+	router.POST("/password/forgot", ar.userController.ForgotPassword)
+	router.POST("/password/reset", ar.userController.ResetPassword)
 
 	// Protected routes
 	protected := router.Group("")
 	protected.Use(ar.jwtMiddleware.ValidateJWT())
 	{
 		protected.POST("/logout", ar.userController.Logout)
-		protected.GET("/:id", ar.userController.GetUser)
-		protected.PUT("/:id", ar.userController.UpdateUser)
-		protected.DELETE("/:id", ar.userController.DeleteUser)
-		protected.GET("", ar.userController.ListUsers)
+		protected.GET("/me", ar.userController.GetMe)
+		protected.PUT("/me", ar.userController.UpdateMe)
+		protected.DELETE("/me", ar.userController.DeleteMe)
+		protected.GET("/me/export", ar.userController.ExportMe)
+		protected.PUT("/me/password", ar.userController.ChangePassword)
+		protected.GET("/me/mfa", ar.userController.MFAStatus)
+		protected.POST("/me/mfa/totp", ar.userController.EnrollTOTP)
+		protected.POST("/me/mfa/totp/confirm", ar.userController.ConfirmTOTP)
+		protected.DELETE("/me/mfa/totp", ar.userController.DisableTOTP)
+		protected.POST("/me/mfa/recovery-codes", ar.userController.RegenerateRecoveryCodes)
+		protected.GET("/me/sessions", ar.userController.ListSessions)
+		protected.DELETE("/me/sessions", ar.userController.RevokeOtherSessions)
+		protected.DELETE("/me/sessions/:id", ar.userController.RevokeSession)
+	}
+
+	// Admin routes
+	admin := router.Group("")
+	admin.Use(ar.jwtMiddleware.ValidateJWT(), middleware.RequireRoles(enums.AdminRole))
+	{
+		admin.GET("", ar.userController.ListUsers)
+		admin.GET("/:id", ar.userController.GetUser)
+		admin.PUT("/:id", ar.userController.UpdateUser)
+		admin.DELETE("/:id", ar.userController.DeleteUser)
+		admin.PUT("/:id/role", ar.userController.UpdateUserRole)
+		admin.POST("/:id/unlock", ar.userController.UnlockUser)
 	}
 }
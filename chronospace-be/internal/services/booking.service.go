@@ -1,78 +1,117 @@
 package services
 
 import (
+	"chronospace-be/internal/cancellation"
 	db "chronospace-be/internal/db/sqlc"
 	"chronospace-be/internal/models"
+	"chronospace-be/internal/pricing"
+	"chronospace-be/internal/utils"
 	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
 	err2 "chronospace-be/internal/models/enums"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type IBookingRepository interface {
 	CreateBooking(ctx context.Context, arg db.CreateBookingParams) (db.Booking, error)
-	DeleteBooking(ctx context.Context, id pgtype.UUID) error
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
 	GetBooking(ctx context.Context, id pgtype.UUID) (db.Booking, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	ListBookingStatusHistory(ctx context.Context, bookingID pgtype.UUID) ([]db.BookingStatusHistory, error)
 	ListBookings(ctx context.Context) ([]db.Booking, error)
+	ListBookingsByOwner(ctx context.Context, arg db.ListBookingsByOwnerParams) ([]db.Booking, error)
+	ListBookingsByService(ctx context.Context, serviceID pgtype.UUID) ([]db.Booking, error)
 	ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]db.Booking, error)
-	UpdateBooking(ctx context.Context, arg db.UpdateBookingParams) (db.Booking, error)
 }
 
+// bookingTransitions lists the statuses a booking may move to from each
+// status. Statuses without an entry (Rejected, Completed, Canceled) are final.
+var bookingTransitions = map[string][]string{
+	err2.RequestedStatus: {err2.AcceptedStatus, err2.RejectedStatus, err2.CanceledStatus},
+	err2.AcceptedStatus:  {err2.CompletedStatus, err2.CanceledStatus},
+}
+
+// inactiveBookingStatuses are statuses that no longer hold their slot.
+var inactiveBookingStatuses = []string{err2.CanceledStatus, err2.RejectedStatus}
+
 type BookingService struct {
 	bookingRepo IBookingRepository
+	payments    *PaymentService
 }
 
-func NewBookingService(bookingRepository IBookingRepository) *BookingService {
+func NewBookingService(bookingRepository IBookingRepository, payments *PaymentService) *BookingService {
 	return &BookingService{
 		bookingRepo: bookingRepository,
+		payments:    payments,
 	}
 }
 
 func (s *BookingService) CreateBooking(ctx context.Context, params models.CreateBookingParams) (models.Booking, error) {
-	if !params.UserID.Valid || !params.ServiceID.Valid {
+	if !params.UserID.Valid || !params.ServiceID.Valid || !params.CheckIn.Valid || !params.CheckOut.Valid {
 		return models.Booking{}, err2.ErrBookingInvalidInput
 	}
+	if err := validateStay(params.CheckIn, params.CheckOut); err != nil {
+		return models.Booking{}, err
+	}
+
+	var booking db.Booking
+	err := s.bookingRepo.ExecTx(ctx, func(q *db.Queries) error {
+		if err := reserveStay(ctx, q, params.ServiceID, params.CheckIn, params.CheckOut, pgtype.UUID{}); err != nil {
+			return err
+		}
 
-	booking, err := s.bookingRepo.CreateBooking(ctx, db.CreateBookingParams{
-		UserID:    params.UserID,
-		ServiceID: params.ServiceID,
-		Date:      params.Date,
-		Time:      params.Time,
-		Status:    params.Status,
+		price, err := lockInQuote(ctx, q, params.ServiceID, params.CheckIn, params.CheckOut)
+		if err != nil {
+			return err
+		}
+
+		// Every booking starts as a request that the provider has to answer
+		booking, err = q.CreateBooking(ctx, db.CreateBookingParams{
+			UserID:         params.UserID,
+			ServiceID:      params.ServiceID,
+			CheckIn:        params.CheckIn,
+			CheckOut:       params.CheckOut,
+			CheckInTime:    params.CheckInTime,
+			CheckOutTime:   params.CheckOutTime,
+			Status:         err2.RequestedStatus,
+			TotalAmount:    price.Total,
+			Currency:       price.Currency,
+			PriceBreakdown: price.Breakdown,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateBookingStatusHistory(ctx, db.CreateBookingStatusHistoryParams{
+			BookingID: booking.ID,
+			ToStatus:  booking.Status,
+			ChangedBy: params.UserID,
+		})
+		return err
 	})
 	if err != nil {
-		return models.Booking{}, err
+		return models.Booking{}, bookingConflictError(err)
 	}
 
-	return models.Booking{
-		ID:        booking.ID,
-		UserID:    booking.UserID,
-		ServiceID: booking.ServiceID,
-		Date:      booking.Date,
-		Time:      booking.Time,
-		Status:    booking.Status,
-	}, nil
+	return toBooking(booking), nil
 }
 
-func (s *BookingService) GetBooking(ctx context.Context, id pgtype.UUID) (models.Booking, error) {
+func (s *BookingService) GetBooking(ctx context.Context, actor models.Actor, id pgtype.UUID) (models.Booking, error) {
 	if !id.Valid {
 		return models.Booking{}, err2.ErrBookingInvalidInput
 	}
 
-	booking, err := s.bookingRepo.GetBooking(ctx, id)
+	booking, err := s.authorizedBooking(ctx, actor, id)
 	if err != nil {
 		return models.Booking{}, err
 	}
 
-	return models.Booking{
-		ID:        booking.ID,
-		UserID:    booking.UserID,
-		ServiceID: booking.ServiceID,
-		Date:      booking.Date,
-		Time:      booking.Time,
-		Status:    booking.Status,
-	}, nil
+	return toBooking(booking), nil
 }
 
 func (s *BookingService) ListBookings(ctx context.Context) ([]models.Booking, error) {
@@ -81,18 +120,7 @@ func (s *BookingService) ListBookings(ctx context.Context) ([]models.Booking, er
 		return nil, err
 	}
 
-	result := make([]models.Booking, len(bookings))
-	for i, booking := range bookings {
-		result[i] = models.Booking{
-			ID:        booking.ID,
-			UserID:    booking.UserID,
-			ServiceID: booking.ServiceID,
-			Date:      booking.Date,
-			Time:      booking.Time,
-			Status:    booking.Status,
-		}
-	}
-	return result, nil
+	return toBookings(bookings), nil
 }
 
 func (s *BookingService) ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]models.Booking, error) {
@@ -105,49 +133,506 @@ func (s *BookingService) ListBookingsByUser(ctx context.Context, userID pgtype.U
 		return nil, err
 	}
 
-	result := make([]models.Booking, len(bookings))
-	for i, booking := range bookings {
-		result[i] = models.Booking{
-			ID:        booking.ID,
-			UserID:    booking.UserID,
-			ServiceID: booking.ServiceID,
-			Date:      booking.Date,
-			Time:      booking.Time,
-			Status:    booking.Status,
+	return toBookings(bookings), nil
+}
+
+// ListBookingsByProvider returns the bookings made for any service owned by
+// the given provider, optionally filtered by status.
+func (s *BookingService) ListBookingsByProvider(ctx context.Context, ownerID pgtype.UUID, status string) ([]models.Booking, error) {
+	if !ownerID.Valid {
+		return nil, err2.ErrBookingInvalidInput
+	}
+	if status != "" && !isBookingStatus(status) {
+		return nil, err2.ErrBookingInvalidStatus
+	}
+
+	bookings, err := s.bookingRepo.ListBookingsByOwner(ctx, db.ListBookingsByOwnerParams{
+		OwnerID: ownerID,
+		Status:  pgtype.Text{String: status, Valid: status != ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return toBookings(bookings), nil
+}
+
+// ListBookingsByService returns the bookings of a single service. Only the
+// service owner and admins may see them.
+func (s *BookingService) ListBookingsByService(ctx context.Context, actor models.Actor, serviceID pgtype.UUID) ([]models.Booking, error) {
+	if !serviceID.Valid {
+		return nil, err2.ErrBookingInvalidInput
+	}
+
+	service, err := s.bookingRepo.GetService(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err2.ErrServiceNotFound
 		}
+		return nil, err
+	}
+	if !actor.CanManage(service.OwnerID) {
+		return nil, err2.ErrForbidden
 	}
-	return result, nil
+
+	bookings, err := s.bookingRepo.ListBookingsByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBookings(bookings), nil
 }
 
-func (s *BookingService) UpdateBooking(ctx context.Context, params models.UpdateBookingParams) (models.Booking, error) {
+// UpdateBooking moves a booking to new stay dates. Only bookings that have not been
+// answered by the provider yet can be moved; status changes go through the
+// dedicated transition methods.
+func (s *BookingService) UpdateBooking(ctx context.Context, actor models.Actor, params models.UpdateBookingParams) (models.Booking, error) {
 	if !params.ID.Valid {
 		return models.Booking{}, err2.ErrBookingInvalidInput
 	}
 
-	booking, err := s.bookingRepo.UpdateBooking(ctx, db.UpdateBookingParams{
-		ID:     params.ID,
-		Date:   params.Date,
-		Time:   params.Time,
-		Status: params.Status,
+	var booking db.Booking
+	err := s.bookingRepo.ExecTx(ctx, func(q *db.Queries) error {
+		current, err := q.GetBookingForUpdate(ctx, params.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return err2.ErrBookingNotFound
+			}
+			return err
+		}
+		if current.UserID != actor.UserID && !actor.IsAdmin() {
+			return err2.ErrForbidden
+		}
+		if current.Status != err2.RequestedStatus {
+			return err2.ErrBookingNotEditable
+		}
+
+		arg := db.UpdateBookingParams{
+			ID:           params.ID,
+			CheckIn:      params.CheckIn,
+			CheckOut:     params.CheckOut,
+			CheckInTime:  params.CheckInTime,
+			CheckOutTime: params.CheckOutTime,
+		}
+		if !params.CheckIn.Valid {
+			arg.CheckIn = current.CheckIn
+		}
+		if !params.CheckOut.Valid {
+			arg.CheckOut = current.CheckOut
+		}
+		if !params.CheckInTime.Valid {
+			arg.CheckInTime = current.CheckInTime
+		}
+		if !params.CheckOutTime.Valid {
+			arg.CheckOutTime = current.CheckOutTime
+		}
+		if err := validateStay(arg.CheckIn, arg.CheckOut); err != nil {
+			return err
+		}
+		if err := reserveStay(ctx, q, current.ServiceID, arg.CheckIn, arg.CheckOut, current.ID); err != nil {
+			return err
+		}
+
+		arg.TotalAmount, arg.Currency, arg.PriceBreakdown = current.TotalAmount, current.Currency, current.PriceBreakdown
+		if arg.CheckIn != current.CheckIn || arg.CheckOut != current.CheckOut {
+			price, err := lockInQuote(ctx, q, current.ServiceID, arg.CheckIn, arg.CheckOut)
+			if err != nil {
+				return err
+			}
+			arg.TotalAmount, arg.Currency, arg.PriceBreakdown = price.Total, price.Currency, price.Breakdown
+		}
+
+		// An authorization holds the old price; the guest has to start over
+		// rather than have the stay change under a payment
+		if arg.TotalAmount != current.TotalAmount || arg.Currency != current.Currency {
+			if _, err := q.GetActivePaymentForUpdate(ctx, current.ID); err == nil {
+				return err2.ErrPaymentAlreadyAuthorized
+			} else if !errors.Is(err, pgx.ErrNoRows) {
+				return err
+			}
+		}
+
+		booking, err = q.UpdateBooking(ctx, arg)
+		return err
 	})
 	if err != nil {
-		return models.Booking{}, err
+		return models.Booking{}, bookingConflictError(err)
 	}
 
-	return models.Booking{
-		ID:        booking.ID,
-		UserID:    booking.UserID,
-		ServiceID: booking.ServiceID,
-		Date:      booking.Date,
-		Time:      booking.Time,
-		Status:    booking.Status,
-	}, nil
+	return toBooking(booking), nil
 }
 
+// DeleteBooking removes a booking with its history. Bookings that money has
+// moved for are kept for the records; they can be canceled instead.
 func (s *BookingService) DeleteBooking(ctx context.Context, id pgtype.UUID) error {
 	if !id.Valid {
 		return err2.ErrBookingInvalidInput
 	}
 
-	return s.bookingRepo.DeleteBooking(ctx, id)
+	return s.bookingRepo.ExecTx(ctx, func(q *db.Queries) error {
+		// The lock keeps payments and invoices from being added while deleting
+		booking, err := q.GetBookingForUpdate(ctx, id)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return err2.ErrBookingNotFound
+			}
+			return err
+		}
+
+		history, err := q.ListPaymentsByBooking(ctx, booking.ID)
+		if err != nil {
+			return err
+		}
+		if len(history) > 0 {
+			return err2.ErrBookingHasPayments
+		}
+
+		// Issued invoices are kept for the books
+		if _, err := q.GetInvoiceByBooking(ctx, booking.ID); err == nil {
+			return err2.ErrBookingHasInvoice
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		return q.DeleteBooking(ctx, booking.ID)
+	})
+}
+
+// AcceptBooking confirms a requested booking.
+func (s *BookingService) AcceptBooking(ctx context.Context, params models.BookingStatusChangeParams) (models.Booking, error) {
+	return s.changeStatus(ctx, params, err2.AcceptedStatus)
+}
+
+// RejectBooking declines a requested booking.
+func (s *BookingService) RejectBooking(ctx context.Context, params models.BookingStatusChangeParams) (models.Booking, error) {
+	return s.changeStatus(ctx, params, err2.RejectedStatus)
+}
+
+// CancelBooking cancels a booking that has not started yet.
+func (s *BookingService) CancelBooking(ctx context.Context, params models.BookingStatusChangeParams) (models.Booking, error) {
+	return s.changeStatus(ctx, params, err2.CanceledStatus)
+}
+
+// CompleteBooking marks an accepted booking as fulfilled once its stay has ended.
+func (s *BookingService) CompleteBooking(ctx context.Context, params models.BookingStatusChangeParams) (models.Booking, error) {
+	return s.changeStatus(ctx, params, err2.CompletedStatus)
+}
+
+func (s *BookingService) ListBookingStatusHistory(ctx context.Context, actor models.Actor, id pgtype.UUID) ([]models.BookingStatusChange, error) {
+	if !id.Valid {
+		return nil, err2.ErrBookingInvalidInput
+	}
+
+	if _, err := s.authorizedBooking(ctx, actor, id); err != nil {
+		return nil, err
+	}
+
+	history, err := s.bookingRepo.ListBookingStatusHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBookingStatusChanges(history), nil
+}
+
+// QuoteCancellation computes the refund the guest would receive if the
+// booking was canceled now.
+func (s *BookingService) QuoteCancellation(ctx context.Context, actor models.Actor, id pgtype.UUID) (models.CancellationQuote, error) {
+	if !id.Valid {
+		return models.CancellationQuote{}, err2.ErrBookingInvalidInput
+	}
+
+	booking, err := s.authorizedBooking(ctx, actor, id)
+	if err != nil {
+		return models.CancellationQuote{}, err
+	}
+
+	now := time.Now()
+	policy := toCancellationPolicy(booking.CancellationPolicy)
+	days := cancellation.DaysBefore(booking.CheckIn.Time, now)
+	quote := models.CancellationQuote{
+		Cancelable:        checkBookingTransition(booking, err2.CanceledStatus, now) == nil,
+		Policy:            policy,
+		DaysBeforeCheckIn: days,
+		RefundPercent:     policy.RefundPercent(days),
+	}
+
+	payment, err := s.payments.capturedPayment(ctx, booking.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return quote, nil
+		}
+		return models.CancellationQuote{}, err
+	}
+	quote.PaidAmount = payment.CapturedAmount - payment.RefundedAmount
+	quote.RefundAmount = max(policy.Refund(payment.CapturedAmount, days)-payment.RefundedAmount, 0)
+	quote.Currency = payment.Currency
+
+	return quote, nil
+}
+
+// changeStatus moves a booking to the given status if the state machine
+// allows it and records the change in the booking's status history.
+func (s *BookingService) changeStatus(ctx context.Context, params models.BookingStatusChangeParams, to string) (models.Booking, error) {
+	if !params.ID.Valid || !params.Actor.UserID.Valid {
+		return models.Booking{}, err2.ErrBookingInvalidInput
+	}
+
+	var booking db.Booking
+	err := s.bookingRepo.ExecTx(ctx, func(q *db.Queries) error {
+		current, err := q.GetBookingForUpdate(ctx, params.ID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return err2.ErrBookingNotFound
+			}
+			return err
+		}
+
+		service, err := q.GetService(ctx, current.ServiceID)
+		if err != nil {
+			return err
+		}
+
+		// Guests may only withdraw their own bookings; everything else is
+		// decided by the provider
+		isGuest := current.UserID == params.Actor.UserID && to == err2.CanceledStatus
+		if !isGuest && !params.Actor.CanManage(service.OwnerID) {
+			return err2.ErrForbidden
+		}
+
+		now := time.Now()
+		if err := checkBookingTransition(current, to, now); err != nil {
+			return err
+		}
+
+		booking, err = q.UpdateBookingStatus(ctx, db.UpdateBookingStatusParams{
+			ID:     current.ID,
+			Status: to,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreateBookingStatusHistory(ctx, db.CreateBookingStatusHistoryParams{
+			BookingID:  current.ID,
+			FromStatus: pgtype.Text{String: current.Status, Valid: true},
+			ToStatus:   to,
+			ChangedBy:  params.Actor.UserID,
+			Reason:     pgtype.Text{String: params.Reason, Valid: params.Reason != ""},
+		})
+		if err != nil {
+			return err
+		}
+
+		// Guests withdrawing from an accepted stay are refunded under the
+		// policy they booked with; any other cancellation is refunded in full
+		var policy *cancellation.Policy
+		if isGuest && current.Status == err2.AcceptedStatus {
+			booked := toCancellationPolicy(current.CancellationPolicy)
+			policy = &booked
+		}
+
+		return s.payments.settleBooking(ctx, q, current, to, policy, now)
+	})
+	if err != nil {
+		return models.Booking{}, err
+	}
+
+	// The provider is only involved once the change is committed
+	s.payments.processBookingOperations(ctx, booking.ID)
+
+	return toBooking(booking), nil
+}
+
+func (s *BookingService) authorizedBooking(ctx context.Context, actor models.Actor, id pgtype.UUID) (db.Booking, error) {
+	return authorizedBooking(ctx, s.bookingRepo, actor, id)
+}
+
+// bookingLookup is the part of a repository authorizedBooking needs.
+type bookingLookup interface {
+	GetBooking(ctx context.Context, id pgtype.UUID) (db.Booking, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+}
+
+// authorizedBooking loads a booking that the actor is allowed to see: their
+// own bookings and bookings of services they manage.
+func authorizedBooking(ctx context.Context, repo bookingLookup, actor models.Actor, id pgtype.UUID) (db.Booking, error) {
+	booking, err := repo.GetBooking(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.Booking{}, err2.ErrBookingNotFound
+		}
+		return db.Booking{}, err
+	}
+
+	if booking.UserID == actor.UserID || actor.IsAdmin() {
+		return booking, nil
+	}
+
+	service, err := repo.GetService(ctx, booking.ServiceID)
+	if err != nil {
+		return db.Booking{}, err
+	}
+	if !actor.CanManage(service.OwnerID) {
+		return db.Booking{}, err2.ErrForbidden
+	}
+
+	return booking, nil
+}
+
+// reserveStay checks that every night of the stay is open in the service
+// schedule, including recurring rules, and that no other active booking
+// overlaps it. The service row is locked first so concurrent reservations for
+// it are serialized. excludeID skips the booking being rescheduled.
+func reserveStay(ctx context.Context, q *db.Queries, serviceID pgtype.UUID, checkIn, checkOut pgtype.Date, excludeID pgtype.UUID) error {
+	if _, err := q.LockServiceForBooking(ctx, serviceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return err2.ErrServiceNotFound
+		}
+		return err
+	}
+
+	slots, err := expandSlots(ctx, q, []pgtype.UUID{serviceID}, checkIn.Time, checkOut.Time)
+	if err != nil {
+		return err
+	}
+	if !stayIsOpen(slots[serviceID], checkIn.Time, checkOut.Time) {
+		return err2.ErrBookingSlotUnavailable
+	}
+
+	overlapping, err := q.CountOverlappingBookings(ctx, db.CountOverlappingBookingsParams{
+		ServiceID:        serviceID,
+		CheckIn:          checkIn,
+		CheckOut:         checkOut,
+		InactiveStatuses: inactiveBookingStatuses,
+		ExcludeID:        excludeID,
+	})
+	if err != nil {
+		return err
+	}
+	if overlapping > 0 {
+		return err2.ErrBookingConflict
+	}
+
+	return nil
+}
+
+// bookingConflictError translates constraint violations raised by
+// concurrent reservations into ErrBookingConflict.
+func bookingConflictError(err error) error {
+	if utils.IsExclusionViolation(err) || utils.IsUniqueViolation(err) {
+		return err2.ErrBookingConflict
+	}
+	return err
+}
+
+// validateStay requires a check-out date strictly after the check-in date.
+func validateStay(checkIn, checkOut pgtype.Date) error {
+	if !checkIn.Valid || !checkOut.Valid || !checkOut.Time.After(checkIn.Time) {
+		return err2.ErrBookingInvalidDateRange
+	}
+	return nil
+}
+
+// countNights returns the number of nights between check-in and check-out.
+func countNights(checkIn, checkOut pgtype.Date) int {
+	if !checkIn.Valid || !checkOut.Valid {
+		return 0
+	}
+	return int(checkOut.Time.Sub(checkIn.Time).Hours() / 24)
+}
+
+// checkBookingTransition validates a status change against the transition
+// table and the booking's stay: bookings can only be canceled before the stay
+// starts and only completed once the guest has checked out.
+func checkBookingTransition(booking db.Booking, to string, now time.Time) error {
+	allowed := false
+	for _, status := range bookingTransitions[booking.Status] {
+		if status == to {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return err2.ErrBookingInvalidTransition
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	switch to {
+	case err2.CanceledStatus:
+		if booking.Status == err2.AcceptedStatus && !booking.CheckIn.Time.After(today) {
+			return err2.ErrBookingCancellationClosed
+		}
+	case err2.CompletedStatus:
+		if booking.CheckOut.Time.After(today) {
+			return err2.ErrBookingNotEnded
+		}
+	}
+
+	return nil
+}
+
+func isBookingStatus(status string) bool {
+	switch status {
+	case err2.RequestedStatus, err2.AcceptedStatus, err2.RejectedStatus, err2.CompletedStatus, err2.CanceledStatus:
+		return true
+	}
+	return false
+}
+
+func toBooking(booking db.Booking) models.Booking {
+	return models.Booking{
+		ID:           booking.ID,
+		UserID:       booking.UserID,
+		ServiceID:    booking.ServiceID,
+		CheckIn:      booking.CheckIn,
+		CheckOut:     booking.CheckOut,
+		CheckInTime:  booking.CheckInTime,
+		CheckOutTime: booking.CheckOutTime,
+		Nights:       countNights(booking.CheckIn, booking.CheckOut),
+		Status:       booking.Status,
+
+		TotalAmount:    booking.TotalAmount,
+		Currency:       booking.Currency,
+		PriceBreakdown: toPriceBreakdown(booking.PriceBreakdown),
+
+		CancellationPolicy: toCancellationPolicy(booking.CancellationPolicy),
+	}
+}
+
+// toPriceBreakdown decodes the stored quote of a booking, if it has one.
+func toPriceBreakdown(raw []byte) *pricing.Quote {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	var quote pricing.Quote
+	if err := json.Unmarshal(raw, &quote); err != nil {
+		return nil
+	}
+	return &quote
+}
+
+func toBookingStatusChanges(history []db.BookingStatusHistory) []models.BookingStatusChange {
+	result := make([]models.BookingStatusChange, len(history))
+	for i, change := range history {
+		result[i] = models.BookingStatusChange{
+			ID:         change.ID,
+			BookingID:  change.BookingID,
+			FromStatus: change.FromStatus.String,
+			ToStatus:   change.ToStatus,
+			ChangedBy:  change.ChangedBy,
+			Reason:     change.Reason.String,
+			ChangedAt:  change.ChangedAt,
+		}
+	}
+	return result
+}
+
+func toBookings(bookings []db.Booking) []models.Booking {
+	result := make([]models.Booking, len(bookings))
+	for i, booking := range bookings {
+		result[i] = toBooking(booking)
+	}
+	return result
 }
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"bytes"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type ICalendarRepository interface {
+	slotSource
+
+	CreateCalendarFeed(ctx context.Context, arg db.CreateCalendarFeedParams) (db.CalendarFeed, error)
+	DeleteCalendarFeed(ctx context.Context, id pgtype.UUID) error
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetCalendarFeed(ctx context.Context, id pgtype.UUID) (db.CalendarFeed, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	GetUserByCalendarToken(ctx context.Context, calendarTokenHash pgtype.Text) (db.User, error)
+	ListActiveBookingsByService(ctx context.Context, arg db.ListActiveBookingsByServiceParams) ([]db.Booking, error)
+	ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]db.Booking, error)
+	ListCalendarFeeds(ctx context.Context) ([]db.CalendarFeed, error)
+	ListCalendarFeedsByService(ctx context.Context, serviceID pgtype.UUID) ([]db.CalendarFeed, error)
+	ListScheduleBlackoutsByService(ctx context.Context, serviceID pgtype.UUID) ([]db.ScheduleBlackout, error)
+	SetUserCalendarToken(ctx context.Context, arg db.SetUserCalendarTokenParams) error
+	UpdateCalendarFeedSyncStatus(ctx context.Context, arg db.UpdateCalendarFeedSyncStatusParams) (db.CalendarFeed, error)
+}
+
+const (
+	// calendarPastDays is how far back exported feeds reach.
+	calendarPastDays = 30
+	// maxCalendarFeedSize caps the size of imported feeds.
+	maxCalendarFeedSize = 5 << 20
+)
+
+type CalendarService struct {
+	calendarRepo ICalendarRepository
+	httpClient   *http.Client
+}
+
+func NewCalendarService(calendarRepository ICalendarRepository) *CalendarService {
+	return &CalendarService{
+		calendarRepo: calendarRepository,
+		httpClient:   utils.NewPublicHTTPClient(30 * time.Second),
+	}
+}
+
+// ServiceCalendar exports the reserved and blocked periods of a service as
+// an iCalendar feed. Periods imported from other channels are left out so
+// that channels do not echo each other's blocks.
+func (s *CalendarService) ServiceCalendar(ctx context.Context, serviceID pgtype.UUID) ([]byte, error) {
+	service, err := s.calendarRepo.GetService(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err2.ErrServiceNotFound
+		}
+		return nil, err
+	}
+
+	today := dateOnly(time.Now())
+	since := today.AddDate(0, 0, -calendarPastDays)
+	until := today.AddDate(1, 0, 0)
+
+	bookings, err := s.calendarRepo.ListActiveBookingsByService(ctx, db.ListActiveBookingsByServiceParams{
+		ServiceID:        serviceID,
+		Since:            pgtype.Date{Time: since, Valid: true},
+		InactiveStatuses: inactiveBookingStatuses,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var events []utils.ICalEvent
+	for _, booking := range bookings {
+		events = append(events, utils.ICalEvent{
+			UID:     fmt.Sprintf("booking-%x@chronospace", booking.ID.Bytes),
+			Summary: "Reserved",
+			Start:   dateOnly(booking.CheckIn.Time),
+			End:     dateOnly(booking.CheckOut.Time),
+		})
+	}
+
+	slots, err := expandSlots(ctx, s.calendarRepo, []pgtype.UUID{serviceID}, since, until)
+	if err != nil {
+		return nil, err
+	}
+	events = append(events, blockedPeriods(serviceID, slots[serviceID])...)
+
+	blackouts, err := s.calendarRepo.ListScheduleBlackoutsByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, blackout := range blackouts {
+		if blackout.FeedID.Valid || blackout.EndsOn.Time.Before(since) {
+			continue
+		}
+		events = append(events, utils.ICalEvent{
+			UID:     fmt.Sprintf("blackout-%x@chronospace", blackout.ID.Bytes),
+			Summary: "Blocked",
+			Start:   dateOnly(blackout.StartsOn.Time),
+			End:     dateOnly(blackout.EndsOn.Time).AddDate(0, 0, 1),
+		})
+	}
+
+	return writeCalendar(service.Name, events)
+}
+
+// UserCalendar exports the active bookings of the user owning the secret
+// calendar token.
+func (s *CalendarService) UserCalendar(ctx context.Context, token string) ([]byte, error) {
+	user, err := s.calendarRepo.GetUserByCalendarToken(ctx, pgtype.Text{String: utils.HashToken(token), Valid: true})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err2.ErrInvalidCalendarToken
+		}
+		return nil, err
+	}
+
+	bookings, err := s.calendarRepo.ListBookingsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceNames := make(map[pgtype.UUID]string)
+	var events []utils.ICalEvent
+	for _, booking := range bookings {
+		if booking.Status == err2.CanceledStatus || booking.Status == err2.RejectedStatus {
+			continue
+		}
+
+		name, ok := serviceNames[booking.ServiceID]
+		if !ok {
+			service, err := s.calendarRepo.GetService(ctx, booking.ServiceID)
+			if err != nil {
+				return nil, err
+			}
+			name = service.Name
+			serviceNames[booking.ServiceID] = name
+		}
+
+		events = append(events, utils.ICalEvent{
+			UID:     fmt.Sprintf("booking-%x@chronospace", booking.ID.Bytes),
+			Summary: fmt.Sprintf("%s (%s)", name, booking.Status),
+			Start:   dateOnly(booking.CheckIn.Time),
+			End:     dateOnly(booking.CheckOut.Time),
+		})
+	}
+
+	return writeCalendar(user.FullName+" bookings", events)
+}
+
+// RotateUserCalendarToken issues a new secret token for the user's booking
+// feed. Any previous feed URL stops working.
+func (s *CalendarService) RotateUserCalendarToken(ctx context.Context, userID pgtype.UUID) (string, error) {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.calendarRepo.SetUserCalendarToken(ctx, db.SetUserCalendarTokenParams{
+		ID:                userID,
+		CalendarTokenHash: pgtype.Text{String: utils.HashToken(token), Valid: true},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CreateFeed registers an external iCalendar feed for a service and imports
+// it right away. A failing first import is recorded on the feed rather than
+// rejected, since the remote side may only be temporarily unavailable.
+func (s *CalendarService) CreateFeed(ctx context.Context, actor models.Actor, req models.CreateCalendarFeedRequest) (models.CalendarFeedResponse, error) {
+	if err := s.authorizeService(ctx, actor, req.ServiceID); err != nil {
+		return models.CalendarFeedResponse{}, err
+	}
+
+	feedURL, err := normalizeFeedURL(req.URL)
+	if err != nil {
+		return models.CalendarFeedResponse{}, err
+	}
+
+	feed, err := s.calendarRepo.CreateCalendarFeed(ctx, db.CreateCalendarFeedParams{
+		ServiceID: req.ServiceID,
+		Url:       feedURL,
+	})
+	if err != nil {
+		return models.CalendarFeedResponse{}, err
+	}
+
+	feed, _ = s.syncFeed(ctx, feed)
+	return toCalendarFeedResponse(feed), nil
+}
+
+func (s *CalendarService) ListFeeds(ctx context.Context, actor models.Actor, serviceID pgtype.UUID) ([]models.CalendarFeedResponse, error) {
+	if err := s.authorizeService(ctx, actor, serviceID); err != nil {
+		return nil, err
+	}
+
+	feeds, err := s.calendarRepo.ListCalendarFeedsByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.CalendarFeedResponse, len(feeds))
+	for i, feed := range feeds {
+		response[i] = toCalendarFeedResponse(feed)
+	}
+	return response, nil
+}
+
+// DeleteFeed removes a feed together with the periods it imported.
+func (s *CalendarService) DeleteFeed(ctx context.Context, actor models.Actor, id pgtype.UUID) error {
+	if _, err := s.authorizedFeed(ctx, actor, id); err != nil {
+		return err
+	}
+
+	return s.calendarRepo.DeleteCalendarFeed(ctx, id)
+}
+
+// SyncFeed imports a feed immediately instead of waiting for the next
+// background run.
+func (s *CalendarService) SyncFeed(ctx context.Context, actor models.Actor, id pgtype.UUID) (models.CalendarFeedResponse, error) {
+	feed, err := s.authorizedFeed(ctx, actor, id)
+	if err != nil {
+		return models.CalendarFeedResponse{}, err
+	}
+
+	feed, err = s.syncFeed(ctx, feed)
+	if err != nil {
+		return toCalendarFeedResponse(feed), err
+	}
+	return toCalendarFeedResponse(feed), nil
+}
+
+// RunSync imports all registered feeds every interval until ctx is done.
+// A non-positive interval disables the background import.
+func (s *CalendarService) RunSync(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.SyncAll(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll imports every registered feed, logging the ones that fail.
+func (s *CalendarService) SyncAll(ctx context.Context) {
+	feeds, err := s.calendarRepo.ListCalendarFeeds(ctx)
+	if err != nil {
+		log.Printf("calendar sync: failed to list feeds: %v", err)
+		return
+	}
+
+	for _, feed := range feeds {
+		if ctx.Err() != nil {
+			return
+		}
+		if _, err := s.syncFeed(ctx, feed); err != nil {
+			log.Printf("calendar sync: feed %x: %v", feed.ID.Bytes, err)
+		}
+	}
+}
+
+// syncFeed fetches a feed and replaces the periods previously imported from
+// it. On failure the old periods are kept and the error is stored on the feed.
+func (s *CalendarService) syncFeed(ctx context.Context, feed db.CalendarFeed) (db.CalendarFeed, error) {
+	syncErr := s.importFeed(ctx, feed)
+
+	status := db.UpdateCalendarFeedSyncStatusParams{ID: feed.ID}
+	if syncErr != nil {
+		status.LastError = pgtype.Text{String: syncErr.Error(), Valid: true}
+	}
+
+	updated, err := s.calendarRepo.UpdateCalendarFeedSyncStatus(ctx, status)
+	if err != nil {
+		return feed, err
+	}
+	return updated, syncErr
+}
+
+func (s *CalendarService) importFeed(ctx context.Context, feed db.CalendarFeed) error {
+	events, err := s.fetchFeed(ctx, feed.Url)
+	if err != nil {
+		return err
+	}
+
+	today := dateOnly(time.Now())
+	return s.calendarRepo.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteFeedBlackouts(ctx, feed.ID); err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			// Past events cannot block anything anymore
+			if !event.End.After(today) {
+				continue
+			}
+
+			_, err := q.CreateFeedBlackout(ctx, db.CreateFeedBlackoutParams{
+				ServiceID:   feed.ServiceID,
+				StartsOn:    pgtype.Date{Time: event.Start, Valid: true},
+				EndsOn:      pgtype.Date{Time: event.End.AddDate(0, 0, -1), Valid: true},
+				Reason:      pgtype.Text{String: event.Summary, Valid: event.Summary != ""},
+				FeedID:      feed.ID,
+				ExternalUid: pgtype.Text{String: event.UID, Valid: event.UID != ""},
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *CalendarService) fetchFeed(ctx context.Context, feedURL string) ([]utils.ICalEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", err2.ErrCalendarFeedFetch, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", err2.ErrCalendarFeedFetch, resp.StatusCode)
+	}
+
+	return utils.ParseICalendar(io.LimitReader(resp.Body, maxCalendarFeedSize))
+}
+
+func (s *CalendarService) authorizedFeed(ctx context.Context, actor models.Actor, id pgtype.UUID) (db.CalendarFeed, error) {
+	feed, err := s.calendarRepo.GetCalendarFeed(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.CalendarFeed{}, err2.ErrCalendarFeedNotFound
+		}
+		return db.CalendarFeed{}, err
+	}
+
+	if err := s.authorizeService(ctx, actor, feed.ServiceID); err != nil {
+		return db.CalendarFeed{}, err
+	}
+
+	return feed, nil
+}
+
+func (s *CalendarService) authorizeService(ctx context.Context, actor models.Actor, serviceID pgtype.UUID) error {
+	service, err := s.calendarRepo.GetService(ctx, serviceID)
+	if err != nil {
+		return err2.ErrServiceNotFound
+	}
+
+	if !actor.CanManage(service.OwnerID) {
+		return err2.ErrForbidden
+	}
+
+	return nil
+}
+
+// normalizeFeedURL accepts http(s) URLs of public hosts and rewrites
+// webcal:// to https://.
+func normalizeFeedURL(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || parsed.Host == "" {
+		return "", err2.ErrCalendarFeedURL
+	}
+
+	switch strings.ToLower(parsed.Scheme) {
+	case "http", "https":
+	case "webcal":
+		parsed.Scheme = "https"
+	default:
+		return "", err2.ErrCalendarFeedURL
+	}
+
+	// Internal hosts are refused up front; names resolving to them are
+	// refused by the HTTP client when it connects
+	host := strings.ToLower(parsed.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return "", err2.ErrCalendarFeedURL
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !utils.IsPublicIP(ip) {
+		return "", err2.ErrCalendarFeedURL
+	}
+
+	return parsed.String(), nil
+}
+
+// blockedPeriods merges consecutive dates with a blocked slot into events.
+func blockedPeriods(serviceID pgtype.UUID, slots []models.ScheduleSlot) []utils.ICalEvent {
+	var dates []time.Time
+	seen := make(map[time.Time]bool)
+	for _, slot := range slots {
+		date := dateOnly(slot.Date.Time)
+		if slot.Status == err2.ScheduleBlockedStatus && !seen[date] {
+			seen[date] = true
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	var events []utils.ICalEvent
+	for i := 0; i < len(dates); {
+		start, end := dates[i], dates[i].AddDate(0, 0, 1)
+		for i++; i < len(dates) && dates[i].Equal(end); i++ {
+			end = end.AddDate(0, 0, 1)
+		}
+		events = append(events, utils.ICalEvent{
+			UID:     fmt.Sprintf("blocked-%x-%s@chronospace", serviceID.Bytes, start.Format("20060102")),
+			Summary: "Blocked",
+			Start:   start,
+			End:     end,
+		})
+	}
+	return events
+}
+
+func writeCalendar(name string, events []utils.ICalEvent) ([]byte, error) {
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+
+	var buf bytes.Buffer
+	if err := utils.WriteICalendar(&buf, name, events); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func toCalendarFeedResponse(feed db.CalendarFeed) models.CalendarFeedResponse {
+	return models.CalendarFeedResponse{
+		ID:           feed.ID,
+		ServiceID:    feed.ServiceID,
+		URL:          feed.Url,
+		LastSyncedAt: feed.LastSyncedAt,
+		LastError:    feed.LastError.String,
+	}
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"chronospace-be/internal/currency"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/utils"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IExchangeRateRepository interface {
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	ListExchangeRates(ctx context.Context) ([]db.ExchangeRate, error)
+}
+
+// ExchangeRateService keeps the exchange rates used to show prices in the
+// caller's preferred currency. Payments are always made in the currency of
+// the service.
+type ExchangeRateService struct {
+	repo IExchangeRateRepository
+}
+
+func NewExchangeRateService(repo IExchangeRateRepository) *ExchangeRateService {
+	return &ExchangeRateService{
+		repo: repo,
+	}
+}
+
+func (s *ExchangeRateService) ListRates(ctx context.Context) ([]models.ExchangeRateResponse, error) {
+	rates, err := s.repo.ListExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.ExchangeRateResponse, len(rates))
+	for i, rate := range rates {
+		response[i] = models.ExchangeRateResponse{
+			Base:      rate.Base,
+			Quote:     rate.Quote,
+			Rate:      rate.Rate,
+			UpdatedAt: rate.UpdatedAt.Time,
+		}
+	}
+	return response, nil
+}
+
+// ReplaceRates stores the rates of a base currency, dropping rates of that
+// base that are not part of the request.
+func (s *ExchangeRateService) ReplaceRates(ctx context.Context, req models.ExchangeRatesRequest) ([]models.ExchangeRateResponse, error) {
+	base, err := currency.Normalize(req.Base)
+	if err != nil {
+		return nil, utils.ReplaceError(err, currency.ErrUnknownCurrency, err2.ErrUnknownCurrency)
+	}
+	if len(req.Rates) == 0 {
+		return nil, fmt.Errorf("%w: no rates given", err2.ErrInvalidExchangeRate)
+	}
+
+	params := make([]db.UpsertExchangeRateParams, 0, len(req.Rates))
+	for code, value := range req.Rates {
+		quote, err := currency.Normalize(code)
+		if err != nil {
+			return nil, utils.ReplaceError(err, currency.ErrUnknownCurrency, err2.ErrUnknownCurrency)
+		}
+		if quote == base {
+			return nil, fmt.Errorf("%w: %s is the base currency", err2.ErrInvalidExchangeRate, quote)
+		}
+
+		// Rates are stored with 10 fraction digits, which must not round
+		// them down to zero
+		rate, ok := new(big.Rat).SetString(value.String())
+		if ok {
+			rate, ok = new(big.Rat).SetString(rate.FloatString(10))
+		}
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: %s rate %q", err2.ErrInvalidExchangeRate, quote, value)
+		}
+		var numeric pgtype.Numeric
+		if err := numeric.Scan(rate.FloatString(10)); err != nil {
+			return nil, fmt.Errorf("%w: %s rate %q", err2.ErrInvalidExchangeRate, quote, value)
+		}
+
+		params = append(params, db.UpsertExchangeRateParams{Base: base, Quote: quote, Rate: numeric})
+	}
+
+	err = s.repo.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DeleteExchangeRatesByBase(ctx, base); err != nil {
+			return err
+		}
+		for _, arg := range params {
+			if err := q.UpsertExchangeRate(ctx, arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.ListRates(ctx)
+}
+
+// LoadRatesFile replaces the rates of the base currency named in a JSON file
+// in the format of models.ExchangeRatesRequest.
+func (s *ExchangeRateService) LoadRatesFile(ctx context.Context, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var req models.ExchangeRatesRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	_, err = s.ReplaceRates(ctx, req)
+	return err
+}
+
+// Rates returns all stored exchange rates for conversions.
+func (s *ExchangeRateService) Rates(ctx context.Context) (currency.Rates, error) {
+	stored, err := s.repo.ListExchangeRates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(currency.Rates, len(stored))
+	for _, row := range stored {
+		rate, err := currency.NumericToRat(row.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("exchange rate %s/%s: %w", row.Base, row.Quote, err)
+		}
+		rates[currency.Pair{Base: row.Base, Quote: row.Quote}] = rate
+	}
+	return rates, nil
+}
+
+// displayRates returns the rates needed to show prices in the requested
+// currency, or nil when prices are shown as they are.
+func (s *ExchangeRateService) displayRates(ctx context.Context, requested string) (string, currency.Rates, error) {
+	if requested == "" {
+		return "", nil, nil
+	}
+
+	code, err := currency.Normalize(requested)
+	if err != nil {
+		return "", nil, utils.ReplaceError(err, currency.ErrUnknownCurrency, err2.ErrUnknownCurrency)
+	}
+
+	rates, err := s.Rates(ctx)
+	if err != nil {
+		return "", nil, err
+	}
+	return code, rates, nil
+}
+
+// convertMoney converts a decimal amount of one currency into another.
+func convertMoney(rates currency.Rates, amount pgtype.Numeric, from, to string) (*models.Money, error) {
+	minor, err := currency.ToMinor(amount, from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", err2.ErrInvalidPrice, err)
+	}
+
+	converted, err := rates.Convert(minor, from, to)
+	if err != nil {
+		return nil, utils.ReplaceError(err, currency.ErrNoRate, err2.ErrExchangeRateNotFound)
+	}
+
+	return &models.Money{Amount: currency.FromMinor(converted, to), Currency: to}, nil
+}
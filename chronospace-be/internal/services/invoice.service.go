@@ -0,0 +1,243 @@
+package services
+
+import (
+	"chronospace-be/internal/currency"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/invoice"
+	"chronospace-be/internal/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IInvoiceRepository interface {
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetBooking(ctx context.Context, id pgtype.UUID) (db.Booking, error)
+	GetInvoiceByBooking(ctx context.Context, bookingID pgtype.UUID) (db.Invoice, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	GetUser(ctx context.Context, id pgtype.UUID) (db.User, error)
+	ListPaymentsByBooking(ctx context.Context, bookingID pgtype.UUID) ([]db.Payment, error)
+}
+
+// InvoiceService issues invoices for bookings. Each provider numbers their
+// invoices in sequence; an invoice is issued the first time it is requested
+// and reprinted with the same number and amounts afterwards.
+type InvoiceService struct {
+	repo    IInvoiceRepository
+	taxRate *big.Rat
+}
+
+func NewInvoiceService(repo IInvoiceRepository, taxRatePercent float64) *InvoiceService {
+	taxRate, ok := new(big.Rat).SetString(strconv.FormatFloat(taxRatePercent, 'f', -1, 64))
+	if !ok || taxRate.Sign() < 0 {
+		taxRate = new(big.Rat)
+	}
+	return &InvoiceService{
+		repo:    repo,
+		taxRate: taxRate,
+	}
+}
+
+// Invoice renders the invoice of a booking for its guest, its provider or an
+// admin, issuing it first if needed.
+func (s *InvoiceService) Invoice(ctx context.Context, actor models.Actor, bookingID pgtype.UUID) (models.InvoiceDocument, error) {
+	booking, err := authorizedBooking(ctx, s.repo, actor, bookingID)
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+
+	issued, err := s.repo.GetInvoiceByBooking(ctx, booking.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		issued, err = s.issue(ctx, booking.ID)
+	}
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+
+	return s.render(ctx, booking, issued)
+}
+
+// issue numbers and stores the invoice of an accepted booking. The booking
+// row is locked so concurrent requests issue a single invoice.
+func (s *InvoiceService) issue(ctx context.Context, bookingID pgtype.UUID) (db.Invoice, error) {
+	var issued db.Invoice
+	err := s.repo.ExecTx(ctx, func(q *db.Queries) error {
+		booking, err := q.GetBookingForUpdate(ctx, bookingID)
+		if err != nil {
+			return err
+		}
+
+		issued, err = q.GetInvoiceByBooking(ctx, booking.ID)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if booking.Status != err2.AcceptedStatus && booking.Status != err2.CompletedStatus {
+			return err2.ErrInvoiceNotAvailable
+		}
+
+		service, err := q.GetService(ctx, booking.ServiceID)
+		if err != nil {
+			return err
+		}
+		if !service.OwnerID.Valid {
+			return fmt.Errorf("%w: the service has no provider", err2.ErrInvoiceNotAvailable)
+		}
+
+		lines, err := json.Marshal(invoiceLines(booking))
+		if err != nil {
+			return err
+		}
+		// The tax is computed from the rate as stored, so the two match
+		var taxRate pgtype.Numeric
+		if err := taxRate.Scan(s.taxRate.FloatString(2)); err != nil {
+			return err
+		}
+		storedRate, err := currency.NumericToRat(taxRate)
+		if err != nil {
+			return err
+		}
+
+		number, err := q.NextInvoiceNumber(ctx, service.OwnerID)
+		if err != nil {
+			return err
+		}
+
+		issued, err = q.CreateInvoice(ctx, db.CreateInvoiceParams{
+			BookingID:   booking.ID,
+			ProviderID:  service.OwnerID,
+			Number:      number,
+			Currency:    booking.Currency,
+			TotalAmount: booking.TotalAmount,
+			TaxRate:     taxRate,
+			TaxAmount:   invoice.IncludedTax(booking.TotalAmount, storedRate),
+			Lines:       lines,
+		})
+		return err
+	})
+	return issued, err
+}
+
+// render lays out an issued invoice with the current names of the parties
+// and the payments made so far.
+func (s *InvoiceService) render(ctx context.Context, booking db.Booking, issued db.Invoice) (models.InvoiceDocument, error) {
+	service, err := s.repo.GetService(ctx, booking.ServiceID)
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+	seller, err := s.repo.GetUser(ctx, issued.ProviderID)
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+	buyer, err := s.repo.GetUser(ctx, booking.UserID)
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+	history, err := s.repo.ListPaymentsByBooking(ctx, booking.ID)
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+
+	var lines []invoice.Line
+	if err := json.Unmarshal(issued.Lines, &lines); err != nil {
+		return models.InvoiceDocument{}, fmt.Errorf("invoice %x lines: %w", issued.ID.Bytes, err)
+	}
+	taxRate, err := currency.NumericToRat(issued.TaxRate)
+	if err != nil {
+		return models.InvoiceDocument{}, fmt.Errorf("invoice %x tax rate: %w", issued.ID.Bytes, err)
+	}
+
+	// Print the booking ID the way the API shows it
+	bookingID, err := booking.ID.Value()
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+
+	number := invoice.FormatNumber(issued.Number)
+	content, err := invoice.Render(invoice.Invoice{
+		Number:    number,
+		IssuedAt:  issued.IssuedAt.Time,
+		Currency:  issued.Currency,
+		Seller:    toInvoiceParty(seller),
+		Buyer:     toInvoiceParty(buyer),
+		BookingID: fmt.Sprint(bookingID),
+		Service:   service.Name,
+		Location:  service.Location,
+		CheckIn:   booking.CheckIn.Time,
+		CheckOut:  booking.CheckOut.Time,
+		Lines:     lines,
+		Total:     issued.TotalAmount,
+		TaxRate:   taxRate,
+		TaxAmount: issued.TaxAmount,
+		Payments:  toInvoicePayments(history, issued.Currency),
+	})
+	if err != nil {
+		return models.InvoiceDocument{}, err
+	}
+
+	return models.InvoiceDocument{
+		Number:   number,
+		FileName: number + ".pdf",
+		Content:  content,
+	}, nil
+}
+
+// invoiceLines itemizes the locked-in price of a booking. Bookings made
+// before pricing rules existed are invoiced as a single line.
+func invoiceLines(booking db.Booking) []invoice.Line {
+	if quote := toPriceBreakdown(booking.PriceBreakdown); quote != nil {
+		return invoice.Lines(*quote)
+	}
+
+	return []invoice.Line{{
+		Description: fmt.Sprintf("Stay of %d nights", countNights(booking.CheckIn, booking.CheckOut)),
+		Quantity:    1,
+		UnitAmount:  booking.TotalAmount,
+		Amount:      booking.TotalAmount,
+	}}
+}
+
+func toInvoiceParty(user db.User) invoice.Party {
+	name := user.FullName
+	if name == "" {
+		name = user.Username
+	}
+	return invoice.Party{Name: name, Email: user.Email}
+}
+
+// toInvoicePayments lists the captured and refunded amounts of a booking's
+// payments in the invoice currency.
+func toInvoicePayments(history []db.Payment, code string) []invoice.Payment {
+	var list []invoice.Payment
+	for _, payment := range history {
+		if payment.Currency != code {
+			continue
+		}
+		if payment.CapturedAmount > 0 {
+			list = append(list, invoice.Payment{
+				Date:        payment.CreatedAt.Time,
+				Description: "Payment via " + payment.Provider,
+				Amount:      payment.CapturedAmount,
+			})
+		}
+		if payment.RefundedAmount > 0 {
+			list = append(list, invoice.Payment{
+				Date:        payment.UpdatedAt.Time,
+				Description: "Refund via " + payment.Provider,
+				Amount:      -payment.RefundedAmount,
+			})
+		}
+	}
+	return list
+}
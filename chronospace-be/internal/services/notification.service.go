@@ -1,9 +1,49 @@
 package services
 
+import (
+	"chronospace-be/internal/mailer"
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
 type NotificationService struct {
-	
+	mailer     mailer.Mailer
+	webappBase string
+}
+
+func NewNotificationService(mail mailer.Mailer, webappBaseURL string) *NotificationService {
+	return &NotificationService{
+		mailer:     mail,
+		webappBase: strings.TrimRight(webappBaseURL, "/"),
+	}
 }
 
-func NewNotificationService() *NotificationService {
-	return &NotificationService{}
+// SendEmailVerification mails the link that confirms ownership of an address.
+func (s *NotificationService) SendEmailVerification(ctx context.Context, to, name, token string) error {
+	link := s.webappBase + "/verify-email?token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: "Confirm your Chronospace email address",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"please confirm your email address by opening the link below:\n\n%s\n\n"+
+			"The link is valid for %d hours. If you did not create an account, you can ignore this message.\n",
+			name, link, int(emailVerificationTTL.Hours())),
+	})
+}
+
+// SendPasswordReset mails the link that lets the user choose a new password.
+func (s *NotificationService) SendPasswordReset(ctx context.Context, to, name, token string) error {
+	link := s.webappBase + "/reset-password?token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      to,
+		Subject: "Reset your Chronospace password",
+		Body: fmt.Sprintf("Hi %s,\n\n"+
+			"we received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\n"+
+			"The link is valid for %d minutes and can be used once. If you did not ask for a reset, you can ignore this message.\n",
+			name, link, int(passwordResetTTL.Minutes())),
+	})
 }
@@ -0,0 +1,383 @@
+package services
+
+import (
+	"chronospace-be/internal/config"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/oidc"
+	"chronospace-be/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+
+	err2 "chronospace-be/internal/models/enums"
+)
+
+type IOIDCRepository interface {
+	ConsumeOIDCLoginState(ctx context.Context, stateHash string) (db.OidcLoginState, error)
+	CreateOIDCLoginState(ctx context.Context, arg db.CreateOIDCLoginStateParams) error
+	CreateUserIdentity(ctx context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error)
+	DeleteExpiredOIDCLoginStates(ctx context.Context) error
+	DeleteUserIdentity(ctx context.Context, arg db.DeleteUserIdentityParams) (int64, error)
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetUser(ctx context.Context, id pgtype.UUID) (db.User, error)
+	GetUserByEmail(ctx context.Context, email string) (db.User, error)
+	GetUserByUsername(ctx context.Context, username string) (db.User, error)
+	GetUserIdentity(ctx context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error)
+	ListUserIdentities(ctx context.Context, userID pgtype.UUID) ([]db.UserIdentity, error)
+}
+
+// oidcLoginTTL is how long a user may take to sign in at the provider.
+const oidcLoginTTL = 10 * time.Minute
+
+// OIDCService signs users in through external OpenID Connect providers.
+// External identities are linked to local accounts, which then get regular
+// Chronospace sessions.
+type OIDCService struct {
+	repo        IOIDCRepository
+	users       *UserService
+	providers   map[string]*oidc.Provider
+	callbackURL string
+}
+
+func NewOIDCService(repo IOIDCRepository, users *UserService, cfg *config.Config) *OIDCService {
+	callbackBase := strings.TrimRight(cfg.PublicBaseURL, "/") + "/v1/api/users/oidc/"
+
+	providers := make(map[string]*oidc.Provider, len(cfg.OIDCProviders))
+	for _, p := range cfg.OIDCProviders {
+		providers[p.Name] = oidc.NewProvider(oidc.Config{
+			Name:         p.Name,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  callbackBase + p.Name + "/callback",
+		}, nil)
+	}
+
+	return &OIDCService{
+		repo:        repo,
+		users:       users,
+		providers:   providers,
+		callbackURL: strings.TrimRight(cfg.WebappBaseUrl, "/") + "/oidc/callback",
+	}
+}
+
+// WebappCallbackURL returns the webapp page that receives the outcome of a
+// sign-in. The values travel in the fragment so tokens stay out of server
+// logs and Referer headers.
+func (s *OIDCService) WebappCallbackURL(values url.Values) string {
+	return s.callbackURL + "#" + values.Encode()
+}
+
+// Providers returns the names of the configured identity providers.
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin remembers a new sign-in attempt and returns the URL of the
+// provider's authorization endpoint to send the user to.
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (string, error) {
+	if ctx == nil {
+		return "", err2.ErrInvalidContex
+	}
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", err2.ErrOIDCProviderNotFound
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return "", err2.ErrGeneratingToken
+	}
+	nonce, err := oidc.NewState()
+	if err != nil {
+		return "", err2.ErrGeneratingToken
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", err2.ErrGeneratingToken
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	// Abandoned attempts are cleaned up whenever a new one starts
+	if err := s.repo.DeleteExpiredOIDCLoginStates(ctx); err != nil {
+		return "", fmt.Errorf("error deleting expired sign-in states: %w", err)
+	}
+
+	err = s.repo.CreateOIDCLoginState(ctx, db.CreateOIDCLoginStateParams{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    pgtype.Timestamp{Time: time.Now().Add(oidcLoginTTL), Valid: true},
+	})
+	if err != nil {
+		return "", fmt.Errorf("error storing sign-in state: %w", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", providerName, err)
+		return "", err2.ErrOIDCLoginFailed
+	}
+
+	return authURL, nil
+}
+
+// CompleteLogin handles the provider's callback: it redeems the
+// authorization code, finds or creates the linked user and signs them in.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, state, code string, device models.Device) (models.LoginResponse, error) {
+	if ctx == nil {
+		return models.LoginResponse{}, err2.ErrInvalidContex
+	}
+
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return models.LoginResponse{}, err2.ErrOIDCProviderNotFound
+	}
+	if state == "" || code == "" {
+		return models.LoginResponse{}, err2.ErrOIDCInvalidState
+	}
+
+	// The provider round trip gets its own budget on top of the database work
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	// A state can be used once, and only with the provider it was issued for
+	loginState, err := s.repo.ConsumeOIDCLoginState(ctx, utils.HashToken(state))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.LoginResponse{}, err2.ErrOIDCInvalidState
+		}
+		return models.LoginResponse{}, fmt.Errorf("error reading sign-in state: %w", err)
+	}
+	if loginState.Provider != providerName || time.Now().After(loginState.ExpiresAt.Time) {
+		return models.LoginResponse{}, err2.ErrOIDCInvalidState
+	}
+
+	idToken, err := provider.Exchange(ctx, code, loginState.CodeVerifier, loginState.Nonce)
+	if err != nil {
+		log.Printf("oidc provider %s: %v", providerName, err)
+		return models.LoginResponse{}, err2.ErrOIDCLoginFailed
+	}
+
+	user, err := s.resolveUser(ctx, providerName, idToken)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return s.users.completeLogin(ctx, user, device)
+}
+
+// resolveUser returns the user linked to the external identity. Unknown
+// identities are linked to the account with the same email address, or to a
+// new guest account when there is none. Both require an address the
+// provider has verified.
+func (s *OIDCService) resolveUser(ctx context.Context, providerName string, idToken *oidc.IDToken) (db.User, error) {
+	identity, err := s.repo.GetUserIdentity(ctx, db.GetUserIdentityParams{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+	})
+	if err == nil {
+		user, err := s.repo.GetUser(ctx, identity.UserID)
+		if err != nil {
+			return db.User{}, fmt.Errorf("error reading user: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.User{}, fmt.Errorf("error reading identity: %w", err)
+	}
+
+	if idToken.Email == "" || !idToken.EmailVerified {
+		return db.User{}, err2.ErrOIDCEmailNotVerified
+	}
+	email, err := normalizeEmail(idToken.Email)
+	if err != nil {
+		return db.User{}, err2.ErrOIDCEmailNotVerified
+	}
+
+	identityParams := db.CreateUserIdentityParams{
+		Provider: providerName,
+		Subject:  idToken.Subject,
+		Email:    pgtype.Text{String: email, Valid: true},
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, email)
+	if err == nil {
+		// Whoever registered an unverified address may not own it; linking
+		// would hand them the provider's account
+		if !user.EmailVerifiedAt.Valid {
+			return db.User{}, err2.ErrEmailAlreadyExists
+		}
+		identityParams.UserID = user.ID
+		if _, err := s.repo.CreateUserIdentity(ctx, identityParams); err != nil {
+			return db.User{}, fmt.Errorf("error linking identity: %w", err)
+		}
+		return user, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return db.User{}, fmt.Errorf("error reading user: %w", err)
+	}
+
+	return s.createUser(ctx, email, idToken.Name, identityParams)
+}
+
+// createUser registers a guest for a new external identity. The account
+// gets a random password; a real one can be set through a password reset.
+func (s *OIDCService) createUser(ctx context.Context, email, name string, identityParams db.CreateUserIdentityParams) (db.User, error) {
+	username, err := s.availableUsername(ctx, email)
+	if err != nil {
+		return db.User{}, err
+	}
+	if name == "" {
+		name = username
+	}
+
+	password, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return db.User{}, err2.ErrGeneratingToken
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost+2)
+	if err != nil {
+		return db.User{}, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	var user db.User
+	err = s.repo.ExecTx(ctx, func(q *db.Queries) error {
+		created, err := q.CreateUser(ctx, db.CreateUserParams{
+			Username: username,
+			FullName: name,
+			Email:    email,
+			Password: string(hashedPassword),
+			Role:     err2.GuestRole,
+		})
+		if err != nil {
+			return err
+		}
+
+		// The provider has already verified the address
+		if user, err = q.MarkUserEmailVerified(ctx, created.ID); err != nil {
+			return err
+		}
+
+		identityParams.UserID = user.ID
+		_, err = q.CreateUserIdentity(ctx, identityParams)
+		return err
+	})
+	if err != nil {
+		return db.User{}, fmt.Errorf("error creating user: %w", err)
+	}
+
+	return user, nil
+}
+
+// availableUsername derives an unused username from the local part of an
+// email address.
+func (s *OIDCService) availableUsername(ctx context.Context, email string) (string, error) {
+	local, _, _ := strings.Cut(email, "@")
+	base := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return -1
+		}
+	}, local)
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 5; i++ {
+		_, err := s.repo.GetUserByUsername(ctx, candidate)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("error reading user: %w", err)
+		}
+
+		suffix, err := utils.GenerateRandomToken(3)
+		if err != nil {
+			return "", err2.ErrGeneratingToken
+		}
+		candidate = base + "-" + suffix
+	}
+
+	return "", err2.ErrUsernameAlreadyExists
+}
+
+// ListIdentities returns the external identities linked to the user.
+func (s *OIDCService) ListIdentities(ctx context.Context, userID pgtype.UUID) ([]models.IdentityResponse, error) {
+	if ctx == nil {
+		return nil, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	identities, err := s.repo.ListUserIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing identities: %w", err)
+	}
+
+	return toIdentityResponses(identities), nil
+}
+
+// UnlinkIdentity removes the link to the user's account at a provider.
+func (s *OIDCService) UnlinkIdentity(ctx context.Context, userID pgtype.UUID, providerName string) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	deleted, err := s.repo.DeleteUserIdentity(ctx, db.DeleteUserIdentityParams{
+		UserID:   userID,
+		Provider: providerName,
+	})
+	if err != nil {
+		return fmt.Errorf("error unlinking identity: %w", err)
+	}
+	if deleted == 0 {
+		return err2.ErrIdentityNotFound
+	}
+
+	return nil
+}
+
+func toIdentityResponses(identities []db.UserIdentity) []models.IdentityResponse {
+	response := make([]models.IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		response = append(response, models.IdentityResponse{
+			Provider:  identity.Provider,
+			Email:     identity.Email.String,
+			CreatedAt: identity.CreatedAt.Time,
+		})
+	}
+	return response
+}
@@ -0,0 +1,202 @@
+package services
+
+import (
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/oidc"
+	"chronospace-be/internal/oidc/oidctest"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeOIDCRepository keeps sign-in states, users and identities in memory.
+type fakeOIDCRepository struct {
+	states     map[string]db.OidcLoginState
+	users      []db.User
+	identities []db.UserIdentity
+}
+
+func (r *fakeOIDCRepository) ConsumeOIDCLoginState(_ context.Context, stateHash string) (db.OidcLoginState, error) {
+	state, ok := r.states[stateHash]
+	if !ok {
+		return db.OidcLoginState{}, pgx.ErrNoRows
+	}
+	delete(r.states, stateHash)
+	return state, nil
+}
+
+func (r *fakeOIDCRepository) CreateOIDCLoginState(_ context.Context, arg db.CreateOIDCLoginStateParams) error {
+	r.states[arg.StateHash] = db.OidcLoginState{
+		StateHash:    arg.StateHash,
+		Provider:     arg.Provider,
+		Nonce:        arg.Nonce,
+		CodeVerifier: arg.CodeVerifier,
+		ExpiresAt:    arg.ExpiresAt,
+	}
+	return nil
+}
+
+func (r *fakeOIDCRepository) CreateUserIdentity(_ context.Context, arg db.CreateUserIdentityParams) (db.UserIdentity, error) {
+	identity := db.UserIdentity{UserID: arg.UserID, Provider: arg.Provider, Subject: arg.Subject, Email: arg.Email}
+	r.identities = append(r.identities, identity)
+	return identity, nil
+}
+
+func (r *fakeOIDCRepository) DeleteExpiredOIDCLoginStates(context.Context) error {
+	return nil
+}
+
+func (r *fakeOIDCRepository) DeleteUserIdentity(context.Context, db.DeleteUserIdentityParams) (int64, error) {
+	return 0, errors.New("not implemented")
+}
+
+func (r *fakeOIDCRepository) ExecTx(context.Context, func(*db.Queries) error) error {
+	return errors.New("not implemented")
+}
+
+func (r *fakeOIDCRepository) GetUser(_ context.Context, id pgtype.UUID) (db.User, error) {
+	for _, user := range r.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (r *fakeOIDCRepository) GetUserByEmail(_ context.Context, email string) (db.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (r *fakeOIDCRepository) GetUserByUsername(context.Context, string) (db.User, error) {
+	return db.User{}, pgx.ErrNoRows
+}
+
+func (r *fakeOIDCRepository) GetUserIdentity(_ context.Context, arg db.GetUserIdentityParams) (db.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if identity.Provider == arg.Provider && identity.Subject == arg.Subject {
+			return identity, nil
+		}
+	}
+	return db.UserIdentity{}, pgx.ErrNoRows
+}
+
+func (r *fakeOIDCRepository) ListUserIdentities(context.Context, pgtype.UUID) ([]db.UserIdentity, error) {
+	return nil, errors.New("not implemented")
+}
+
+func TestOIDCServiceCompleteLogin(t *testing.T) {
+	const clientID = "chronospace"
+
+	unverified := db.User{
+		ID:    pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		Email: "mallory@example.com",
+	}
+
+	tests := []struct {
+		name    string
+		claims  func(claims jwt.MapClaims)
+		wantErr error
+	}{
+		{
+			name: "local account with an unverified address is not linked",
+			claims: func(claims jwt.MapClaims) {
+				claims["email"] = "Mallory@example.com"
+				claims["email_verified"] = true
+			},
+			wantErr: err2.ErrEmailAlreadyExists,
+		},
+		{
+			name: "address the provider has not verified",
+			claims: func(claims jwt.MapClaims) {
+				claims["email"] = "bob@example.com"
+				claims["email_verified"] = false
+			},
+			wantErr: err2.ErrOIDCEmailNotVerified,
+		},
+		{
+			name:    "token without an address",
+			wantErr: err2.ErrOIDCEmailNotVerified,
+		},
+		{
+			name:    "token for another nonce",
+			claims:  func(claims jwt.MapClaims) { claims["nonce"] = "replayed" },
+			wantErr: err2.ErrOIDCLoginFailed,
+		},
+		{
+			name:    "expired token",
+			claims:  func(claims jwt.MapClaims) { claims["exp"] = time.Now().Add(-time.Hour).Unix() },
+			wantErr: err2.ErrOIDCLoginFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := oidctest.NewServer(t, clientID)
+			repo := &fakeOIDCRepository{
+				states: make(map[string]db.OidcLoginState),
+				users:  []db.User{unverified},
+			}
+			service := &OIDCService{
+				repo: repo,
+				providers: map[string]*oidc.Provider{
+					"test": oidc.NewProvider(oidc.Config{
+						Name:        "test",
+						Issuer:      server.Issuer(),
+						ClientID:    clientID,
+						RedirectURL: "https://api.example.com/v1/api/users/oidc/test/callback",
+					}, server.Client()),
+				},
+			}
+			ctx := context.Background()
+
+			authURL, err := service.StartLogin(ctx, "test")
+			if err != nil {
+				t.Fatalf("StartLogin() error = %v", err)
+			}
+
+			claims := server.Claims("subject-1")
+			if tt.claims != nil {
+				tt.claims(claims)
+			}
+			code := server.Authorize(authURL, claims)
+			state := queryValue(t, authURL, "state")
+
+			_, err = service.CompleteLogin(ctx, "test", state, code, models.Device{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CompleteLogin() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(repo.identities) != 0 {
+				t.Fatalf("identities = %+v, want none", repo.identities)
+			}
+
+			// The state is spent even though the sign-in failed
+			if _, err := service.CompleteLogin(ctx, "test", state, code, models.Device{}); !errors.Is(err, err2.ErrOIDCInvalidState) {
+				t.Fatalf("repeated CompleteLogin() error = %v, want %v", err, err2.ErrOIDCInvalidState)
+			}
+		})
+	}
+}
+
+func queryValue(t *testing.T, rawURL, name string) string {
+	t.Helper()
+
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parsing %q: %v", rawURL, err)
+	}
+	return parsed.Query().Get(name)
+}
@@ -0,0 +1,492 @@
+package services
+
+import (
+	"chronospace-be/internal/cancellation"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/payments"
+	"chronospace-be/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IPaymentRepository interface {
+	CreatePayment(ctx context.Context, arg db.CreatePaymentParams) (db.Payment, error)
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetBooking(ctx context.Context, id pgtype.UUID) (db.Booking, error)
+	GetPayment(ctx context.Context, id pgtype.UUID) (db.Payment, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	ListPaymentsByBooking(ctx context.Context, bookingID pgtype.UUID) ([]db.Payment, error)
+	ListPendingPaymentOperations(ctx context.Context, arg db.ListPendingPaymentOperationsParams) ([]db.PaymentOperation, error)
+	ListPendingPaymentOperationsByBooking(ctx context.Context, bookingID pgtype.UUID) ([]db.PaymentOperation, error)
+	UpdatePaymentOperationStatus(ctx context.Context, arg db.UpdatePaymentOperationStatusParams) error
+	UpdatePaymentStatus(ctx context.Context, arg db.UpdatePaymentStatusParams) (db.Payment, error)
+}
+
+const (
+	// maxPaymentOperationAttempts is how often a capture or refund is tried
+	// before it is left for manual follow-up.
+	maxPaymentOperationAttempts = 10
+	// paymentOperationRetryDelay keeps the background retry away from
+	// operations that the request recording them is still sending.
+	paymentOperationRetryDelay = time.Minute
+	// paymentOperationBatchSize caps the operations retried per run.
+	paymentOperationBatchSize = 100
+)
+
+// PaymentService collects payment for bookings. Guests authorize the price
+// of their stay up front; the hold is captured when the provider accepts the
+// booking and released or refunded when the booking falls through. Payments
+// are made in the currency the booking was priced in.
+//
+// Captures, refunds and releases are recorded as payment operations together
+// with the booking change and sent to the provider once it is committed.
+// Operations that fail are retried in the background.
+type PaymentService struct {
+	repo     IPaymentRepository
+	provider payments.Provider
+}
+
+func NewPaymentService(repo IPaymentRepository, provider payments.Provider) *PaymentService {
+	return &PaymentService{
+		repo:     repo,
+		provider: provider,
+	}
+}
+
+// AuthorizeBooking places a hold for the price of a requested booking on the
+// guest's payment method.
+func (s *PaymentService) AuthorizeBooking(ctx context.Context, actor models.Actor, bookingID pgtype.UUID, paymentMethod string) (models.Payment, error) {
+	if !bookingID.Valid || !actor.UserID.Valid || paymentMethod == "" {
+		return models.Payment{}, err2.ErrBookingInvalidInput
+	}
+
+	booking, err := s.repo.GetBooking(ctx, bookingID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Payment{}, err2.ErrBookingNotFound
+		}
+		return models.Payment{}, err
+	}
+	if booking.UserID != actor.UserID {
+		return models.Payment{}, err2.ErrForbidden
+	}
+	if booking.Status != err2.RequestedStatus {
+		return models.Payment{}, err2.ErrBookingNotPayable
+	}
+
+	// Guests pay the price locked in with the booking
+	amount := booking.TotalAmount
+	if amount <= 0 {
+		return models.Payment{}, err2.ErrBookingNotPayable
+	}
+
+	existing, err := s.repo.ListPaymentsByBooking(ctx, booking.ID)
+	if err != nil {
+		return models.Payment{}, err
+	}
+	for _, p := range existing {
+		if isActivePayment(p.Status) {
+			return models.Payment{}, err2.ErrPaymentAlreadyAuthorized
+		}
+	}
+
+	authorization, err := s.provider.Authorize(ctx, paymentsAuthorizeRequest(booking, amount, booking.Currency, paymentMethod))
+	if err != nil {
+		if !errors.Is(err, payments.ErrDeclined) {
+			log.Printf("payments: authorizing booking %x: %v", booking.ID.Bytes, err)
+			return models.Payment{}, err2.ErrPaymentProviderFailed
+		}
+
+		// Declines are kept so the guest and provider can see what happened
+		_, err = s.repo.CreatePayment(ctx, db.CreatePaymentParams{
+			BookingID:     booking.ID,
+			Provider:      s.provider.Name(),
+			Amount:        amount,
+			Currency:      booking.Currency,
+			Status:        err2.PaymentFailedStatus,
+			FailureReason: pgtype.Text{String: payments.ErrDeclined.Error(), Valid: true},
+		})
+		if err != nil {
+			return models.Payment{}, err
+		}
+		return models.Payment{}, err2.ErrPaymentDeclined
+	}
+
+	payment, err := s.repo.CreatePayment(ctx, db.CreatePaymentParams{
+		BookingID:         booking.ID,
+		Provider:          s.provider.Name(),
+		ProviderPaymentID: pgtype.Text{String: authorization.ID, Valid: true},
+		Amount:            amount,
+		Currency:          booking.Currency,
+		Status:            err2.PaymentAuthorizedStatus,
+	})
+	if err != nil {
+		// A concurrent request won the race; do not leave a second hold on
+		// the guest's card
+		if releaseErr := s.provider.Refund(ctx, authorization.ID, amount, authorization.ID+"-release"); releaseErr != nil {
+			log.Printf("payments: releasing authorization %s: %v", authorization.ID, releaseErr)
+		}
+		if utils.IsUniqueViolation(err) {
+			return models.Payment{}, err2.ErrPaymentAlreadyAuthorized
+		}
+		return models.Payment{}, err
+	}
+
+	return toPayment(payment), nil
+}
+
+// ListPayments returns all payment attempts of a booking the actor can see.
+func (s *PaymentService) ListPayments(ctx context.Context, actor models.Actor, bookingID pgtype.UUID) ([]models.Payment, error) {
+	booking, err := authorizedBooking(ctx, s.repo, actor, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.repo.ListPaymentsByBooking(ctx, booking.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPayments(history), nil
+}
+
+// HandleWebhook applies a provider notification to the matching payment.
+// Events are recorded by ID so redelivered events are applied only once.
+func (s *PaymentService) HandleWebhook(ctx context.Context, providerName string, header http.Header, body []byte) error {
+	if providerName != s.provider.Name() {
+		return err2.ErrPaymentProviderNotFound
+	}
+
+	event, err := s.provider.VerifyWebhook(header, body)
+	if err != nil {
+		return err2.ErrInvalidPaymentWebhook
+	}
+
+	return s.repo.ExecTx(ctx, func(q *db.Queries) error {
+		recorded, err := q.RecordPaymentEvent(ctx, db.RecordPaymentEventParams{
+			Provider: s.provider.Name(),
+			EventID:  event.ID,
+			Type:     string(event.Type),
+		})
+		if err != nil {
+			return err
+		}
+		if recorded == 0 {
+			return nil
+		}
+
+		payment, err := q.GetPaymentByProviderIDForUpdate(ctx, db.GetPaymentByProviderIDForUpdateParams{
+			Provider:          s.provider.Name(),
+			ProviderPaymentID: pgtype.Text{String: event.PaymentID, Valid: true},
+		})
+		if err != nil {
+			// Payments created outside Chronospace are none of our business
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil
+			}
+			return err
+		}
+
+		update := db.UpdatePaymentStatusParams{
+			ID:             payment.ID,
+			Status:         payment.Status,
+			CapturedAmount: payment.CapturedAmount,
+			RefundedAmount: payment.RefundedAmount,
+			FailureReason:  payment.FailureReason,
+		}
+
+		switch event.Type {
+		case payments.EventFailed:
+			if payment.Status != err2.PaymentAuthorizedStatus {
+				return nil
+			}
+			update.Status = err2.PaymentFailedStatus
+			update.FailureReason = pgtype.Text{String: "authorization failed at provider", Valid: true}
+		case payments.EventCaptured:
+			if payment.Status != err2.PaymentAuthorizedStatus {
+				return nil
+			}
+			update.Status = err2.PaymentCapturedStatus
+			update.CapturedAmount = event.Amount
+		case payments.EventRefunded:
+			// The event carries the total refunded so far, which also
+			// covers refunds we issued ourselves and have already recorded
+			switch payment.Status {
+			case err2.PaymentCapturedStatus, err2.PaymentPartiallyRefundedStatus, err2.PaymentRefundedStatus:
+			default:
+				return nil
+			}
+			update.RefundedAmount = max(payment.RefundedAmount, min(event.Amount, payment.CapturedAmount))
+			if update.RefundedAmount > 0 {
+				update.Status = refundedStatus(update.CapturedAmount, update.RefundedAmount)
+			}
+		default:
+			return nil
+		}
+
+		_, err = q.UpdatePaymentStatus(ctx, update)
+		return err
+	})
+}
+
+// settleBooking records the money to move when a booking changes status.
+// It runs inside the status change transaction and updates the payment to
+// its intended state; the provider is called by processBookingOperations
+// after commit. Captured payments of canceled bookings are refunded
+// according to policy, or in full when policy is nil.
+func (s *PaymentService) settleBooking(ctx context.Context, q *db.Queries, booking db.Booking, to string, policy *cancellation.Policy, now time.Time) error {
+	switch to {
+	case err2.AcceptedStatus, err2.RejectedStatus, err2.CanceledStatus:
+	default:
+		return nil
+	}
+
+	payment, err := q.GetActivePaymentForUpdate(ctx, booking.ID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Free stays are accepted without a payment
+			if to == err2.AcceptedStatus && booking.TotalAmount > 0 {
+				return err2.ErrPaymentRequired
+			}
+			return nil
+		}
+		return err
+	}
+
+	update := db.UpdatePaymentStatusParams{
+		ID:             payment.ID,
+		CapturedAmount: payment.CapturedAmount,
+		RefundedAmount: payment.RefundedAmount,
+		FailureReason:  payment.FailureReason,
+	}
+	var operation db.CreatePaymentOperationParams
+
+	switch {
+	case to == err2.AcceptedStatus:
+		if payment.Status != err2.PaymentAuthorizedStatus {
+			return nil
+		}
+		update.Status = err2.PaymentCapturedStatus
+		update.CapturedAmount = payment.Amount
+		operation = paymentOperation(payment, err2.PaymentOperationCapture, payment.Amount, "capture")
+	case payment.Status == err2.PaymentAuthorizedStatus:
+		update.Status = err2.PaymentVoidedStatus
+		operation = paymentOperation(payment, err2.PaymentOperationRelease, payment.Amount, "release")
+	default:
+		refund := payment.CapturedAmount - payment.RefundedAmount
+		if policy != nil {
+			days := cancellation.DaysBefore(booking.CheckIn.Time, now)
+			refund = max(policy.Refund(payment.CapturedAmount, days)-payment.RefundedAmount, 0)
+		}
+		// Without a refund the provider keeps the payment as captured
+		if refund == 0 {
+			return nil
+		}
+		update.RefundedAmount = payment.RefundedAmount + refund
+		update.Status = refundedStatus(payment.CapturedAmount, update.RefundedAmount)
+		// Each refund is keyed by the total refunded after it
+		operation = paymentOperation(payment, err2.PaymentOperationRefund, refund, fmt.Sprintf("refund-%d", update.RefundedAmount))
+	}
+
+	if _, err := q.UpdatePaymentStatus(ctx, update); err != nil {
+		return err
+	}
+	_, err = q.CreatePaymentOperation(ctx, operation)
+	return err
+}
+
+// processBookingOperations sends the pending payment operations of a booking
+// to the provider. Failures are logged and left to RunOperations.
+func (s *PaymentService) processBookingOperations(ctx context.Context, bookingID pgtype.UUID) {
+	operations, err := s.repo.ListPendingPaymentOperationsByBooking(ctx, bookingID)
+	if err != nil {
+		log.Printf("payments: listing operations of booking %x: %v", bookingID.Bytes, err)
+		return
+	}
+	s.processOperations(ctx, operations)
+}
+
+// RunOperations retries failed payment operations every interval until ctx
+// is canceled. A zero interval disables the retries.
+func (s *PaymentService) RunOperations(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		operations, err := s.repo.ListPendingPaymentOperations(ctx, db.ListPendingPaymentOperationsParams{
+			UpdatedAt: pgtype.Timestamp{Time: time.Now().Add(-paymentOperationRetryDelay), Valid: true},
+			Limit:     paymentOperationBatchSize,
+		})
+		if err != nil {
+			log.Printf("payments: listing pending operations: %v", err)
+		} else {
+			s.processOperations(ctx, operations)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processOperations sends operations in the order they were recorded. Once
+// an operation of a payment fails, the later ones of that payment wait for
+// the next run.
+func (s *PaymentService) processOperations(ctx context.Context, operations []db.PaymentOperation) {
+	blocked := make(map[pgtype.UUID]bool)
+	for _, operation := range operations {
+		if ctx.Err() != nil {
+			return
+		}
+		if blocked[operation.PaymentID] {
+			continue
+		}
+		if err := s.processOperation(ctx, operation); err != nil {
+			log.Printf("payments: %s of payment %x: %v", operation.Kind, operation.PaymentID.Bytes, err)
+			blocked[operation.PaymentID] = true
+		}
+	}
+}
+
+func (s *PaymentService) processOperation(ctx context.Context, operation db.PaymentOperation) error {
+	payment, err := s.repo.GetPayment(ctx, operation.PaymentID)
+	if err != nil {
+		return err
+	}
+
+	providerID := payment.ProviderPaymentID.String
+	switch operation.Kind {
+	case err2.PaymentOperationCapture:
+		err = s.provider.Capture(ctx, providerID, operation.Amount, operation.IdempotencyKey)
+	case err2.PaymentOperationRefund, err2.PaymentOperationRelease:
+		err = s.provider.Refund(ctx, providerID, operation.Amount, operation.IdempotencyKey)
+	default:
+		err = fmt.Errorf("unknown operation %q", operation.Kind)
+	}
+
+	if err == nil {
+		return s.repo.UpdatePaymentOperationStatus(ctx, db.UpdatePaymentOperationStatusParams{
+			ID:     operation.ID,
+			Status: err2.PaymentOperationSucceededStatus,
+		})
+	}
+
+	status := err2.PaymentOperationPendingStatus
+	if operation.Attempts+1 >= maxPaymentOperationAttempts {
+		status = err2.PaymentOperationFailedStatus
+	}
+	updateErr := s.repo.UpdatePaymentOperationStatus(ctx, db.UpdatePaymentOperationStatusParams{
+		ID:        operation.ID,
+		Status:    status,
+		LastError: pgtype.Text{String: err.Error(), Valid: true},
+	})
+	if updateErr != nil {
+		return errors.Join(err, updateErr)
+	}
+
+	// Given up: keep the reason on the payment so it is visible
+	if status == err2.PaymentOperationFailedStatus {
+		_, updateErr = s.repo.UpdatePaymentStatus(ctx, db.UpdatePaymentStatusParams{
+			ID:             payment.ID,
+			Status:         payment.Status,
+			CapturedAmount: payment.CapturedAmount,
+			RefundedAmount: payment.RefundedAmount,
+			FailureReason:  pgtype.Text{String: fmt.Sprintf("%s failed at provider: %v", operation.Kind, err), Valid: true},
+		})
+		if updateErr != nil {
+			return errors.Join(err, updateErr)
+		}
+	}
+	return err
+}
+
+// capturedPayment returns the captured payment of a booking, or
+// pgx.ErrNoRows when nothing has been charged.
+func (s *PaymentService) capturedPayment(ctx context.Context, bookingID pgtype.UUID) (db.Payment, error) {
+	history, err := s.repo.ListPaymentsByBooking(ctx, bookingID)
+	if err != nil {
+		return db.Payment{}, err
+	}
+	for _, payment := range history {
+		if payment.Status == err2.PaymentCapturedStatus {
+			return payment, nil
+		}
+	}
+	return db.Payment{}, pgx.ErrNoRows
+}
+
+// paymentOperation describes a provider call for payment. The idempotency
+// key is derived from the payment ID and step, so every attempt of the same
+// step uses the same key.
+func paymentOperation(payment db.Payment, kind string, amount int64, step string) db.CreatePaymentOperationParams {
+	return db.CreatePaymentOperationParams{
+		PaymentID:      payment.ID,
+		Kind:           kind,
+		Amount:         amount,
+		IdempotencyKey: fmt.Sprintf("%x-%s", payment.ID.Bytes, step),
+	}
+}
+
+func paymentsAuthorizeRequest(booking db.Booking, amount int64, currency, paymentMethod string) payments.AuthorizeRequest {
+	reference := fmt.Sprintf("%x", booking.ID.Bytes)
+	return payments.AuthorizeRequest{
+		Reference:     reference,
+		Amount:        amount,
+		Currency:      currency,
+		PaymentMethod: paymentMethod,
+		Description:   "Chronospace booking " + reference,
+	}
+}
+
+// refundedStatus is the status of a captured payment after refunds totaling
+// refunded.
+func refundedStatus(captured, refunded int64) string {
+	if refunded < captured {
+		return err2.PaymentPartiallyRefundedStatus
+	}
+	return err2.PaymentRefundedStatus
+}
+
+func isActivePayment(status string) bool {
+	return status == err2.PaymentAuthorizedStatus || status == err2.PaymentCapturedStatus
+}
+
+func toPayment(payment db.Payment) models.Payment {
+	return models.Payment{
+		ID:             payment.ID,
+		BookingID:      payment.BookingID,
+		Provider:       payment.Provider,
+		Amount:         payment.Amount,
+		Currency:       payment.Currency,
+		Status:         payment.Status,
+		CapturedAmount: payment.CapturedAmount,
+		RefundedAmount: payment.RefundedAmount,
+		FailureReason:  payment.FailureReason.String,
+		CreatedAt:      payment.CreatedAt.Time,
+		UpdatedAt:      payment.UpdatedAt.Time,
+	}
+}
+
+func toPayments(list []db.Payment) []models.Payment {
+	result := make([]models.Payment, len(list))
+	for i, payment := range list {
+		result[i] = toPayment(payment)
+	}
+	return result
+}
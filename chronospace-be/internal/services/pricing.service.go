@@ -0,0 +1,336 @@
+package services
+
+import (
+	"chronospace-be/internal/currency"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/pricing"
+	"chronospace-be/internal/utils"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+type IPricingRepository interface {
+	CreatePricingRule(ctx context.Context, arg db.CreatePricingRuleParams) (db.PricingRule, error)
+	DeletePricingRule(ctx context.Context, id pgtype.UUID) error
+	GetPricingRule(ctx context.Context, id pgtype.UUID) (db.PricingRule, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	ListPricingRulesByService(ctx context.Context, serviceID pgtype.UUID) ([]db.PricingRule, error)
+}
+
+// pricingRuleSource is the part of a repository quoteStay needs, so quotes
+// can be computed inside booking transactions as well.
+type pricingRuleSource interface {
+	ListPricingRulesByService(ctx context.Context, serviceID pgtype.UUID) ([]db.PricingRule, error)
+}
+
+// PricingService manages the pricing rules of services and quotes stays.
+type PricingService struct {
+	repo          IPricingRepository
+	exchangeRates *ExchangeRateService
+}
+
+func NewPricingService(repo IPricingRepository, exchangeRates *ExchangeRateService) *PricingService {
+	return &PricingService{
+		repo:          repo,
+		exchangeRates: exchangeRates,
+	}
+}
+
+func (s *PricingService) CreateRule(ctx context.Context, actor models.Actor, serviceID pgtype.UUID, req models.CreatePricingRuleRequest) (models.PricingRuleResponse, error) {
+	service, err := s.authorizeService(ctx, actor, serviceID)
+	if err != nil {
+		return models.PricingRuleResponse{}, err
+	}
+
+	arg := db.CreatePricingRuleParams{
+		ServiceID:         serviceID,
+		Kind:              req.Kind,
+		StartsOn:          req.StartsOn,
+		EndsOn:            req.EndsOn,
+		DaysOfWeek:        req.DaysOfWeek,
+		NightlyPrice:      req.NightlyPrice,
+		AdjustmentPercent: pgtype.Int4{Int32: req.AdjustmentPercent, Valid: req.AdjustmentPercent != 0},
+		MinNights:         pgtype.Int4{Int32: req.MinNights, Valid: req.MinNights != 0},
+	}
+
+	// Validate the rule exactly as it will be read back for quotes
+	rule, err := toPricingRule(db.PricingRule{
+		Kind:              arg.Kind,
+		StartsOn:          arg.StartsOn,
+		EndsOn:            arg.EndsOn,
+		DaysOfWeek:        arg.DaysOfWeek,
+		NightlyPrice:      arg.NightlyPrice,
+		AdjustmentPercent: arg.AdjustmentPercent,
+		MinNights:         arg.MinNights,
+	}, service.Currency)
+	if err != nil {
+		return models.PricingRuleResponse{}, err
+	}
+	if err := pricing.Validate(rule); err != nil {
+		return models.PricingRuleResponse{}, pricingError(err)
+	}
+
+	created, err := s.repo.CreatePricingRule(ctx, arg)
+	if err != nil {
+		return models.PricingRuleResponse{}, err
+	}
+
+	return toPricingRuleResponse(created), nil
+}
+
+func (s *PricingService) ListRules(ctx context.Context, serviceID pgtype.UUID) ([]models.PricingRuleResponse, error) {
+	rules, err := s.repo.ListPricingRulesByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.PricingRuleResponse, len(rules))
+	for i, rule := range rules {
+		response[i] = toPricingRuleResponse(rule)
+	}
+	return response, nil
+}
+
+func (s *PricingService) DeleteRule(ctx context.Context, actor models.Actor, serviceID, id pgtype.UUID) error {
+	rule, err := s.repo.GetPricingRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return err2.ErrPricingRuleNotFound
+		}
+		return err
+	}
+	if rule.ServiceID != serviceID {
+		return err2.ErrPricingRuleNotFound
+	}
+
+	if _, err := s.authorizeService(ctx, actor, rule.ServiceID); err != nil {
+		return err
+	}
+
+	return s.repo.DeletePricingRule(ctx, id)
+}
+
+// Quote prices a stay at a service with its current pricing rules. Booking
+// the same stay locks this price in. When another currency is requested the
+// quote is converted for display; bookings are still paid in the currency of
+// the service.
+func (s *PricingService) Quote(ctx context.Context, serviceID pgtype.UUID, query models.PriceQuoteQuery) (models.PriceQuote, error) {
+	checkIn := pgtype.Date{Time: query.From, Valid: true}
+	checkOut := pgtype.Date{Time: query.To, Valid: true}
+	if err := validateStay(checkIn, checkOut); err != nil {
+		return models.PriceQuote{}, err
+	}
+
+	display, rates, err := s.exchangeRates.displayRates(ctx, query.Currency)
+	if err != nil {
+		return models.PriceQuote{}, err
+	}
+
+	service, err := s.repo.GetService(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.PriceQuote{}, err2.ErrServiceNotFound
+		}
+		return models.PriceQuote{}, err
+	}
+	if query.Guests > service.MaxGuests {
+		return models.PriceQuote{}, err2.ErrTooManyGuests
+	}
+
+	quote, err := quoteStay(ctx, s.repo, service, checkIn, checkOut)
+	if err != nil {
+		return models.PriceQuote{}, err
+	}
+
+	response := models.PriceQuote{
+		ServiceID: service.ID,
+		CheckIn:   checkIn,
+		CheckOut:  checkOut,
+		Guests:    query.Guests,
+		Currency:  service.Currency,
+		Quote:     quote,
+	}
+	if rates != nil && display != service.Currency {
+		rate, err := rates.Rate(service.Currency, display)
+		if err != nil {
+			return models.PriceQuote{}, utils.ReplaceError(err, currency.ErrNoRate, err2.ErrExchangeRateNotFound)
+		}
+		if err := response.ExchangeRate.Scan(rate.FloatString(10)); err != nil {
+			return models.PriceQuote{}, err
+		}
+		response.ServiceCurrency = service.Currency
+		response.Currency = display
+		response.Quote = convertQuote(quote, service.Currency, display, rate)
+	}
+
+	return response, nil
+}
+
+// authorizeService checks that the actor may manage the pricing of a service.
+func (s *PricingService) authorizeService(ctx context.Context, actor models.Actor, serviceID pgtype.UUID) (db.Service, error) {
+	service, err := s.repo.GetService(ctx, serviceID)
+	if err != nil {
+		return db.Service{}, err2.ErrServiceNotFound
+	}
+
+	if !actor.CanManage(service.OwnerID) {
+		return db.Service{}, err2.ErrForbidden
+	}
+
+	return service, nil
+}
+
+// quoteStay prices the nights from checkIn to checkOut at a service, in the
+// currency of the service.
+func quoteStay(ctx context.Context, repo pricingRuleSource, service db.Service, checkIn, checkOut pgtype.Date) (pricing.Quote, error) {
+	stored, err := repo.ListPricingRulesByService(ctx, service.ID)
+	if err != nil {
+		return pricing.Quote{}, err
+	}
+	return calculateQuote(service, stored, checkIn, checkOut)
+}
+
+// calculateQuote prices a stay at a service with the given pricing rules of
+// the service.
+func calculateQuote(service db.Service, stored []db.PricingRule, checkIn, checkOut pgtype.Date) (pricing.Quote, error) {
+	baseRate, err := currency.ToMinor(service.Price, service.Currency)
+	if err != nil {
+		return pricing.Quote{}, fmt.Errorf("service %x price: %w", service.ID.Bytes, err)
+	}
+
+	rules := make([]pricing.Rule, 0, len(stored))
+	for _, row := range stored {
+		rule, err := toPricingRule(row, service.Currency)
+		if err != nil {
+			return pricing.Quote{}, err
+		}
+		rules = append(rules, rule)
+	}
+
+	quote, err := pricing.Calculate(baseRate, rules, checkIn.Time, checkOut.Time)
+	if err != nil {
+		return pricing.Quote{}, pricingError(err)
+	}
+	return quote, nil
+}
+
+// lockedQuote is the price of a stay as stored with a booking.
+type lockedQuote struct {
+	Total     int64
+	Currency  string
+	Breakdown []byte
+}
+
+// lockInQuote prices a stay for a booking and encodes the breakdown for
+// storage.
+func lockInQuote(ctx context.Context, q *db.Queries, serviceID pgtype.UUID, checkIn, checkOut pgtype.Date) (lockedQuote, error) {
+	service, err := q.GetService(ctx, serviceID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return lockedQuote{}, err2.ErrServiceNotFound
+		}
+		return lockedQuote{}, err
+	}
+
+	quote, err := quoteStay(ctx, q, service, checkIn, checkOut)
+	if err != nil {
+		return lockedQuote{}, err
+	}
+
+	breakdown, err := json.Marshal(quote)
+	if err != nil {
+		return lockedQuote{}, err
+	}
+	return lockedQuote{Total: quote.Total, Currency: service.Currency, Breakdown: breakdown}, nil
+}
+
+// convertQuote converts every amount of a quote at the given rate. Nights and
+// totals are summed up again from the converted lines so the quote still adds
+// up after rounding.
+func convertQuote(quote pricing.Quote, from, to string, rate *big.Rat) pricing.Quote {
+	converted := pricing.Quote{Nights: make([]pricing.Night, len(quote.Nights))}
+	for i, night := range quote.Nights {
+		out := pricing.Night{Date: night.Date, Rate: currency.ConvertAt(night.Rate, from, to, rate)}
+		out.Amount = out.Rate
+		for _, line := range night.Adjustments {
+			line.Amount = currency.ConvertAt(line.Amount, from, to, rate)
+			out.Adjustments = append(out.Adjustments, line)
+			out.Amount += line.Amount
+		}
+		out.Amount = max(out.Amount, 0)
+
+		converted.Nights[i] = out
+		converted.Subtotal += out.Amount
+	}
+
+	converted.Total = converted.Subtotal
+	for _, line := range quote.Discounts {
+		line.Amount = currency.ConvertAt(line.Amount, from, to, rate)
+		converted.Discounts = append(converted.Discounts, line)
+		converted.Total += line.Amount
+	}
+	return converted
+}
+
+// pricingError translates errors of the pricing package into service errors.
+func pricingError(err error) error {
+	switch {
+	case errors.Is(err, pricing.ErrInvalidRule):
+		return utils.ReplaceError(err, pricing.ErrInvalidRule, err2.ErrInvalidPricingRule)
+	case errors.Is(err, pricing.ErrMinimumStay):
+		return utils.ReplaceError(err, pricing.ErrMinimumStay, err2.ErrMinimumStayNotMet)
+	case errors.Is(err, pricing.ErrInvalidStay):
+		return err2.ErrBookingInvalidDateRange
+	default:
+		return err
+	}
+}
+
+func toPricingRule(row db.PricingRule, code string) (pricing.Rule, error) {
+	rule := pricing.Rule{
+		Kind:      row.Kind,
+		Percent:   int(row.AdjustmentPercent.Int32),
+		MinNights: int(row.MinNights.Int32),
+	}
+	if row.StartsOn.Valid {
+		rule.StartsOn = row.StartsOn.Time
+	}
+	if row.EndsOn.Valid {
+		rule.EndsOn = row.EndsOn.Time
+	}
+	for _, day := range row.DaysOfWeek {
+		rule.DaysOfWeek = append(rule.DaysOfWeek, time.Weekday(day))
+	}
+	if row.NightlyPrice.Valid {
+		price, err := currency.ToMinor(row.NightlyPrice, code)
+		if err != nil {
+			return pricing.Rule{}, fmt.Errorf("%w: nightly_price: %v", err2.ErrInvalidPricingRule, err)
+		}
+		rule.NightlyPrice = price
+	}
+	return rule, nil
+}
+
+func toPricingRuleResponse(rule db.PricingRule) models.PricingRuleResponse {
+	return models.PricingRuleResponse{
+		ID:                rule.ID,
+		ServiceID:         rule.ServiceID,
+		Kind:              rule.Kind,
+		StartsOn:          rule.StartsOn,
+		EndsOn:            rule.EndsOn,
+		DaysOfWeek:        rule.DaysOfWeek,
+		NightlyPrice:      rule.NightlyPrice,
+		AdjustmentPercent: rule.AdjustmentPercent.Int32,
+		MinNights:         rule.MinNights.Int32,
+	}
+}
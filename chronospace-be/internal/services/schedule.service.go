@@ -3,19 +3,56 @@ package services
 import (
 	db "chronospace-be/internal/db/sqlc"
 	"chronospace-be/internal/models"
+	"chronospace-be/internal/utils"
 	"context"
+	"errors"
+	"sort"
+	"time"
 
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type IScheduleRepository interface {
+	slotSource
+
 	CreateSchedule(ctx context.Context, arg db.CreateScheduleParams) (db.Schedule, error)
+	CreateScheduleBlackout(ctx context.Context, arg db.CreateScheduleBlackoutParams) (db.ScheduleBlackout, error)
+	CreateScheduleRule(ctx context.Context, arg db.CreateScheduleRuleParams) (db.ScheduleRule, error)
+	CreateScheduleRuleException(ctx context.Context, arg db.CreateScheduleRuleExceptionParams) (db.ScheduleRuleException, error)
 	DeleteSchedule(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleBlackout(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleRule(ctx context.Context, id pgtype.UUID) error
+	DeleteScheduleRuleException(ctx context.Context, arg db.DeleteScheduleRuleExceptionParams) error
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetScheduleBlackout(ctx context.Context, id pgtype.UUID) (db.ScheduleBlackout, error)
 	GetScheduleByID(ctx context.Context, id pgtype.UUID) (db.Schedule, error)
+	GetScheduleRule(ctx context.Context, id pgtype.UUID) (db.ScheduleRule, error)
+	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	ListScheduleBlackoutsByService(ctx context.Context, serviceID pgtype.UUID) ([]db.ScheduleBlackout, error)
+	ListScheduleRulesByService(ctx context.Context, serviceID pgtype.UUID) ([]db.ScheduleRule, error)
 	ListSchedules(ctx context.Context) ([]db.Schedule, error)
 	UpdateSchedule(ctx context.Context, arg db.UpdateScheduleParams) (db.Schedule, error)
 }
 
+// slotSource provides the rows needed to expand schedules into concrete
+// slots. Both the store and transaction-scoped queries implement it.
+type slotSource interface {
+	ListScheduleBlackoutsInWindow(ctx context.Context, arg db.ListScheduleBlackoutsInWindowParams) ([]db.ScheduleBlackout, error)
+	ListScheduleRuleExceptions(ctx context.Context, ruleIds []pgtype.UUID) ([]db.ScheduleRuleException, error)
+	ListScheduleRulesByServices(ctx context.Context, serviceIds []pgtype.UUID) ([]db.ScheduleRule, error)
+	ListSchedulesInWindow(ctx context.Context, arg db.ListSchedulesInWindowParams) ([]db.Schedule, error)
+}
+
+// maxSlotWindow bounds how far ListSlots expands recurring rules.
+const maxSlotWindow = 366 * 24 * time.Hour
+
+// maxRuleBackdate bounds how far in the past a recurring rule can start.
+// Rules with COUNT are expanded from their start, so this keeps the walk short.
+const maxRuleBackdate = 366 * 24 * time.Hour
+
 type ScheduleService struct {
 	scheduleRepo IScheduleRepository
 }
@@ -26,22 +63,31 @@ func NewScheduleService(scheduleRepository IScheduleRepository) *ScheduleService
 	}
 }
 
-func (s *ScheduleService) CreateSchedule(ctx context.Context, req models.CreateScheduleRequest) (models.ScheduleResponse, error) {
+func (s *ScheduleService) CreateSchedule(ctx context.Context, actor models.Actor, req models.CreateScheduleRequest) (models.ScheduleResponse, error) {
+	if err := s.authorizeService(ctx, actor, req.ServiceID); err != nil {
+		return models.ScheduleResponse{}, err
+	}
+
+	if req.Status == "" {
+		req.Status = err2.ScheduleAvailableStatus
+	}
+	if err := validateSchedule(req.StartTime, req.EndTime, req.Status); err != nil {
+		return models.ScheduleResponse{}, err
+	}
+
 	schedule, err := s.scheduleRepo.CreateSchedule(ctx, db.CreateScheduleParams{
+		ServiceID: req.ServiceID,
+		Date:      req.Date,
 		TimeStart: req.StartTime,
 		TimeEnd:   req.EndTime,
+		Status:    req.Status,
 	})
 
 	if err != nil {
 		return models.ScheduleResponse{}, err
 	}
 
-	return models.ScheduleResponse{
-		ID:        schedule.ID,
-		TimeStart: schedule.TimeStart,
-		TimeEnd:   schedule.TimeEnd,
-		Status:    schedule.Status,
-	}, err
+	return toScheduleResponse(schedule), err
 }
 
 func (s *ScheduleService) GetSchedule(ctx context.Context, id pgtype.UUID) (db.Schedule, error) {
@@ -52,25 +98,476 @@ func (s *ScheduleService) ListSchedules(ctx context.Context) ([]db.Schedule, err
 	return s.scheduleRepo.ListSchedules(ctx)
 }
 
-func (s *ScheduleService) UpdateSchedule(ctx context.Context, id pgtype.UUID, req models.UpdateScheduleRequest) (models.ScheduleResponse, error) {
-	schedule, err := s.scheduleRepo.UpdateSchedule(ctx, db.UpdateScheduleParams{
+func (s *ScheduleService) UpdateSchedule(ctx context.Context, actor models.Actor, id pgtype.UUID, req models.UpdateScheduleRequest) (models.ScheduleResponse, error) {
+	// Get existing schedule to merge with updates
+	existing, err := s.scheduleRepo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return models.ScheduleResponse{}, err
+	}
+
+	if err := s.authorizeService(ctx, actor, existing.ServiceID); err != nil {
+		return models.ScheduleResponse{}, err
+	}
+
+	arg := db.UpdateScheduleParams{
 		ID:        id,
+		ServiceID: existing.ServiceID,
+		Date:      req.Date,
 		TimeStart: req.StartTime,
 		TimeEnd:   req.EndTime,
-	})
+		Status:    req.Status,
+	}
+
+	// If fields are empty, keep existing values
+	if !req.Date.Valid {
+		arg.Date = existing.Date
+	}
+	if !req.StartTime.Valid {
+		arg.TimeStart = existing.TimeStart
+	}
+	if !req.EndTime.Valid {
+		arg.TimeEnd = existing.TimeEnd
+	}
+	if req.Status == "" {
+		arg.Status = existing.Status
+	}
+
+	if err := validateSchedule(arg.TimeStart, arg.TimeEnd, arg.Status); err != nil {
+		return models.ScheduleResponse{}, err
+	}
+
+	schedule, err := s.scheduleRepo.UpdateSchedule(ctx, arg)
 
 	if err != nil {
 		return models.ScheduleResponse{}, err
 	}
 
+	return toScheduleResponse(schedule), err
+}
+
+func (s *ScheduleService) DeleteSchedule(ctx context.Context, actor models.Actor, id pgtype.UUID) error {
+	schedule, err := s.scheduleRepo.GetScheduleByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorizeService(ctx, actor, schedule.ServiceID); err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.DeleteSchedule(ctx, id)
+}
+
+// CreateRule stores a recurring schedule template together with its
+// exception dates.
+func (s *ScheduleService) CreateRule(ctx context.Context, actor models.Actor, req models.CreateScheduleRuleRequest) (models.ScheduleRuleResponse, error) {
+	if err := s.authorizeService(ctx, actor, req.ServiceID); err != nil {
+		return models.ScheduleRuleResponse{}, err
+	}
+
+	if req.Status == "" {
+		req.Status = err2.ScheduleAvailableStatus
+	}
+	if err := validateSchedule(req.StartTime, req.EndTime, req.Status); err != nil {
+		return models.ScheduleRuleResponse{}, err
+	}
+	if _, err := utils.ParseRRule(req.RRule); err != nil {
+		return models.ScheduleRuleResponse{}, err
+	}
+	if req.StartsOn.Time.Before(time.Now().Add(-maxRuleBackdate)) {
+		return models.ScheduleRuleResponse{}, err2.ErrScheduleRuleStartTooEarly
+	}
+
+	var (
+		rule       db.ScheduleRule
+		exceptions []db.ScheduleRuleException
+	)
+	err := s.scheduleRepo.ExecTx(ctx, func(q *db.Queries) error {
+		var err error
+		rule, err = q.CreateScheduleRule(ctx, db.CreateScheduleRuleParams{
+			ServiceID: req.ServiceID,
+			StartsOn:  req.StartsOn,
+			TimeStart: req.StartTime,
+			TimeEnd:   req.EndTime,
+			Rrule:     req.RRule,
+			Status:    req.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, date := range req.ExDates {
+			exception, err := q.CreateScheduleRuleException(ctx, db.CreateScheduleRuleExceptionParams{
+				RuleID: rule.ID,
+				Date:   date,
+			})
+			if err != nil {
+				return err
+			}
+			exceptions = append(exceptions, exception)
+		}
+		return nil
+	})
+	if err != nil {
+		return models.ScheduleRuleResponse{}, err
+	}
+
+	return toScheduleRuleResponse(rule, exceptions), nil
+}
+
+func (s *ScheduleService) ListRules(ctx context.Context, serviceID pgtype.UUID) ([]models.ScheduleRuleResponse, error) {
+	rules, err := s.scheduleRepo.ListScheduleRulesByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleIDs := make([]pgtype.UUID, len(rules))
+	for i, rule := range rules {
+		ruleIDs[i] = rule.ID
+	}
+	exceptions, err := s.scheduleRepo.ListScheduleRuleExceptions(ctx, ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	byRule := make(map[pgtype.UUID][]db.ScheduleRuleException)
+	for _, exception := range exceptions {
+		byRule[exception.RuleID] = append(byRule[exception.RuleID], exception)
+	}
+
+	response := make([]models.ScheduleRuleResponse, len(rules))
+	for i, rule := range rules {
+		response[i] = toScheduleRuleResponse(rule, byRule[rule.ID])
+	}
+	return response, nil
+}
+
+func (s *ScheduleService) DeleteRule(ctx context.Context, actor models.Actor, id pgtype.UUID) error {
+	if _, err := s.authorizedRule(ctx, actor, id); err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.DeleteScheduleRule(ctx, id)
+}
+
+// AddRuleException excludes a single date (EXDATE) from a recurring rule.
+func (s *ScheduleService) AddRuleException(ctx context.Context, actor models.Actor, ruleID pgtype.UUID, date pgtype.Date) error {
+	if _, err := s.authorizedRule(ctx, actor, ruleID); err != nil {
+		return err
+	}
+
+	_, err := s.scheduleRepo.CreateScheduleRuleException(ctx, db.CreateScheduleRuleExceptionParams{
+		RuleID: ruleID,
+		Date:   date,
+	})
+	return err
+}
+
+func (s *ScheduleService) RemoveRuleException(ctx context.Context, actor models.Actor, ruleID pgtype.UUID, date pgtype.Date) error {
+	if _, err := s.authorizedRule(ctx, actor, ruleID); err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.DeleteScheduleRuleException(ctx, db.DeleteScheduleRuleExceptionParams{
+		RuleID: ruleID,
+		Date:   date,
+	})
+}
+
+// CreateBlackout closes a service for every date from StartsOn through EndsOn,
+// overriding both schedule rows and recurring rules.
+func (s *ScheduleService) CreateBlackout(ctx context.Context, actor models.Actor, req models.CreateScheduleBlackoutRequest) (models.ScheduleBlackoutResponse, error) {
+	if err := s.authorizeService(ctx, actor, req.ServiceID); err != nil {
+		return models.ScheduleBlackoutResponse{}, err
+	}
+
+	if req.EndsOn.Time.Before(req.StartsOn.Time) {
+		return models.ScheduleBlackoutResponse{}, err2.ErrScheduleInvalidBlackout
+	}
+
+	blackout, err := s.scheduleRepo.CreateScheduleBlackout(ctx, db.CreateScheduleBlackoutParams{
+		ServiceID: req.ServiceID,
+		StartsOn:  req.StartsOn,
+		EndsOn:    req.EndsOn,
+		Reason:    pgtype.Text{String: req.Reason, Valid: req.Reason != ""},
+	})
+	if err != nil {
+		return models.ScheduleBlackoutResponse{}, err
+	}
+
+	return toScheduleBlackoutResponse(blackout), nil
+}
+
+func (s *ScheduleService) ListBlackouts(ctx context.Context, serviceID pgtype.UUID) ([]models.ScheduleBlackoutResponse, error) {
+	blackouts, err := s.scheduleRepo.ListScheduleBlackoutsByService(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]models.ScheduleBlackoutResponse, len(blackouts))
+	for i, blackout := range blackouts {
+		response[i] = toScheduleBlackoutResponse(blackout)
+	}
+	return response, nil
+}
+
+func (s *ScheduleService) DeleteBlackout(ctx context.Context, actor models.Actor, id pgtype.UUID) error {
+	blackout, err := s.scheduleRepo.GetScheduleBlackout(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return err2.ErrScheduleBlackoutNotFound
+		}
+		return err
+	}
+
+	if err := s.authorizeService(ctx, actor, blackout.ServiceID); err != nil {
+		return err
+	}
+
+	return s.scheduleRepo.DeleteScheduleBlackout(ctx, id)
+}
+
+// ListSlots expands the schedule rows and recurring rules of a service into
+// concrete slots for the window [from, to).
+func (s *ScheduleService) ListSlots(ctx context.Context, serviceID pgtype.UUID, from, to time.Time) ([]models.ScheduleSlot, error) {
+	if !to.After(from) || to.Sub(from) > maxSlotWindow {
+		return nil, err2.ErrScheduleInvalidWindow
+	}
+
+	if _, err := s.scheduleRepo.GetService(ctx, serviceID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, err2.ErrServiceNotFound
+		}
+		return nil, err
+	}
+
+	slots, err := expandSlots(ctx, s.scheduleRepo, []pgtype.UUID{serviceID}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	result := slots[serviceID]
+	if result == nil {
+		result = []models.ScheduleSlot{}
+	}
+	return result, nil
+}
+
+// authorizedRule loads a rule and checks that the actor may manage it.
+func (s *ScheduleService) authorizedRule(ctx context.Context, actor models.Actor, id pgtype.UUID) (db.ScheduleRule, error) {
+	rule, err := s.scheduleRepo.GetScheduleRule(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return db.ScheduleRule{}, err2.ErrScheduleRuleNotFound
+		}
+		return db.ScheduleRule{}, err
+	}
+
+	if err := s.authorizeService(ctx, actor, rule.ServiceID); err != nil {
+		return db.ScheduleRule{}, err
+	}
+
+	return rule, nil
+}
+
+// authorizeService checks that the actor may manage the schedules of a service.
+func (s *ScheduleService) authorizeService(ctx context.Context, actor models.Actor, serviceID pgtype.UUID) error {
+	service, err := s.scheduleRepo.GetService(ctx, serviceID)
+	if err != nil {
+		return err2.ErrServiceNotFound
+	}
+
+	if !actor.CanManage(service.OwnerID) {
+		return err2.ErrForbidden
+	}
+
+	return nil
+}
+
+func validateSchedule(start, end pgtype.Time, status string) error {
+	if status != err2.ScheduleAvailableStatus && status != err2.ScheduleBlockedStatus {
+		return err2.ErrScheduleInvalidStatus
+	}
+	if end.Microseconds <= start.Microseconds {
+		return err2.ErrScheduleInvalidTimeRange
+	}
+	return nil
+}
+
+func toScheduleResponse(schedule db.Schedule) models.ScheduleResponse {
 	return models.ScheduleResponse{
 		ID:        schedule.ID,
+		ServiceID: schedule.ServiceID,
+		Date:      schedule.Date,
 		TimeStart: schedule.TimeStart,
 		TimeEnd:   schedule.TimeEnd,
 		Status:    schedule.Status,
-	}, err
+	}
 }
 
-func (s *ScheduleService) DeleteSchedule(ctx context.Context, id pgtype.UUID) error {
-	return s.scheduleRepo.DeleteSchedule(ctx, id)
+func toScheduleRuleResponse(rule db.ScheduleRule, exceptions []db.ScheduleRuleException) models.ScheduleRuleResponse {
+	exDates := make([]pgtype.Date, len(exceptions))
+	for i, exception := range exceptions {
+		exDates[i] = exception.Date
+	}
+
+	return models.ScheduleRuleResponse{
+		ID:        rule.ID,
+		ServiceID: rule.ServiceID,
+		StartsOn:  rule.StartsOn,
+		TimeStart: rule.TimeStart,
+		TimeEnd:   rule.TimeEnd,
+		RRule:     rule.Rrule,
+		ExDates:   exDates,
+		Status:    rule.Status,
+	}
+}
+
+func toScheduleBlackoutResponse(blackout db.ScheduleBlackout) models.ScheduleBlackoutResponse {
+	return models.ScheduleBlackoutResponse{
+		ID:        blackout.ID,
+		ServiceID: blackout.ServiceID,
+		StartsOn:  blackout.StartsOn,
+		EndsOn:    blackout.EndsOn,
+		Reason:    blackout.Reason.String,
+	}
+}
+
+// expandSlots returns the slots of the given services in the window
+// [from, to), keyed by service. Schedule rows are used as they are, recurring
+// rules are expanded minus their exception dates, and dates inside a blackout
+// period lose all of their slots.
+func expandSlots(ctx context.Context, src slotSource, serviceIDs []pgtype.UUID, from, to time.Time) (map[pgtype.UUID][]models.ScheduleSlot, error) {
+	windowStart := pgtype.Date{Time: from, Valid: true}
+	windowEnd := pgtype.Date{Time: to, Valid: true}
+
+	schedules, err := src.ListSchedulesInWindow(ctx, db.ListSchedulesInWindowParams{
+		ServiceIds:  serviceIDs,
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := src.ListScheduleRulesByServices(ctx, serviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ruleIDs := make([]pgtype.UUID, len(rules))
+	for i, rule := range rules {
+		ruleIDs[i] = rule.ID
+	}
+	exceptions, err := src.ListScheduleRuleExceptions(ctx, ruleIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	blackouts, err := src.ListScheduleBlackoutsInWindow(ctx, db.ListScheduleBlackoutsInWindowParams{
+		ServiceIds:  serviceIDs,
+		WindowStart: windowStart,
+		WindowEnd:   windowEnd,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slots := make(map[pgtype.UUID][]models.ScheduleSlot)
+	for _, schedule := range schedules {
+		slots[schedule.ServiceID] = append(slots[schedule.ServiceID], models.ScheduleSlot{
+			ServiceID:  schedule.ServiceID,
+			ScheduleID: schedule.ID,
+			Date:       schedule.Date,
+			TimeStart:  schedule.TimeStart,
+			TimeEnd:    schedule.TimeEnd,
+			Status:     schedule.Status,
+		})
+	}
+
+	exDates := make(map[pgtype.UUID]map[time.Time]bool)
+	for _, exception := range exceptions {
+		if exDates[exception.RuleID] == nil {
+			exDates[exception.RuleID] = make(map[time.Time]bool)
+		}
+		exDates[exception.RuleID][dateOnly(exception.Date.Time)] = true
+	}
+
+	for _, rule := range rules {
+		rrule, err := utils.ParseRRule(rule.Rrule)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, date := range rrule.Between(rule.StartsOn.Time, from, to) {
+			if exDates[rule.ID][date] {
+				continue
+			}
+			slots[rule.ServiceID] = append(slots[rule.ServiceID], models.ScheduleSlot{
+				ServiceID: rule.ServiceID,
+				RuleID:    rule.ID,
+				Date:      pgtype.Date{Time: date, Valid: true},
+				TimeStart: rule.TimeStart,
+				TimeEnd:   rule.TimeEnd,
+				Status:    rule.Status,
+			})
+		}
+	}
+
+	for serviceID, serviceSlots := range slots {
+		open := serviceSlots[:0]
+		for _, slot := range serviceSlots {
+			if !blackedOut(blackouts, serviceID, slot.Date.Time) {
+				open = append(open, slot)
+			}
+		}
+
+		sort.Slice(open, func(i, j int) bool {
+			if !open[i].Date.Time.Equal(open[j].Date.Time) {
+				return open[i].Date.Time.Before(open[j].Date.Time)
+			}
+			return open[i].TimeStart.Microseconds < open[j].TimeStart.Microseconds
+		})
+		slots[serviceID] = open
+	}
+
+	return slots, nil
+}
+
+func blackedOut(blackouts []db.ScheduleBlackout, serviceID pgtype.UUID, date time.Time) bool {
+	for _, blackout := range blackouts {
+		if blackout.ServiceID == serviceID && !date.Before(blackout.StartsOn.Time) && !date.After(blackout.EndsOn.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// stayIsOpen reports whether every night in [checkIn, checkOut) has an
+// available slot and no blocked one.
+func stayIsOpen(slots []models.ScheduleSlot, checkIn, checkOut time.Time) bool {
+	available := make(map[time.Time]bool)
+	for _, slot := range slots {
+		switch slot.Status {
+		case err2.ScheduleBlockedStatus:
+			available[dateOnly(slot.Date.Time)] = false
+		case err2.ScheduleAvailableStatus:
+			if _, seen := available[dateOnly(slot.Date.Time)]; !seen {
+				available[dateOnly(slot.Date.Time)] = true
+			}
+		}
+	}
+
+	for night := dateOnly(checkIn); night.Before(dateOnly(checkOut)); night = night.AddDate(0, 0, 1) {
+		if !available[night] {
+			return false
+		}
+	}
+	return true
+}
+
+// dateOnly normalizes t to midnight UTC so dates can be compared and used as
+// map keys regardless of how they were parsed.
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 }
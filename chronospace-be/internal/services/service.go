@@ -1,7 +1,11 @@
 package services
 
 import (
+	"chronospace-be/internal/config"
 	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/mailer"
+	"chronospace-be/internal/payments"
+	"chronospace-be/internal/token"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -13,17 +17,35 @@ type Service struct {
 	ScheduleService     *ScheduleService
 	NotificationService *NotificationService
 	MapsService         *MapsService
+	CalendarService     *CalendarService
+	OIDCService         *OIDCService
+	PaymentService      *PaymentService
+	PricingService      *PricingService
+	ExchangeRateService *ExchangeRateService
+	InvoiceService      *InvoiceService
+	Tokens              *token.Manager
 }
 
-func NewService(pool *pgxpool.Pool, secretKey string, gmapsKey string) *Service {
-	queries := db.New(pool)
+func NewService(pool *pgxpool.Pool, mail mailer.Mailer, tokens *token.Manager, paymentProvider payments.Provider, cfg *config.Config) *Service {
+	store := db.NewStore(pool)
+	notificationService := NewNotificationService(mail, cfg.WebappBaseUrl)
+	userService := NewUserService(store, notificationService, tokens, cfg)
+	paymentService := NewPaymentService(store, paymentProvider)
+	exchangeRateService := NewExchangeRateService(store)
 
 	return &Service{
-		UserService:         NewUserService(queries, secretKey),
-		BookingService:      NewBookingService(queries),
-		ServiceService:      NewServiceService(queries, *NewMapsService(gmapsKey)),
-		ScheduleService:     NewScheduleService(queries),
-		NotificationService: NewNotificationService(),
-		MapsService:         NewMapsService(gmapsKey),
+		UserService:         userService,
+		BookingService:      NewBookingService(store, paymentService),
+		ServiceService:      NewServiceService(store, *NewMapsService(cfg.GoogleAPI), exchangeRateService, cfg.DefaultCurrency),
+		ScheduleService:     NewScheduleService(store),
+		NotificationService: notificationService,
+		MapsService:         NewMapsService(cfg.GoogleAPI),
+		CalendarService:     NewCalendarService(store),
+		OIDCService:         NewOIDCService(store, userService, cfg),
+		PaymentService:      paymentService,
+		PricingService:      NewPricingService(store, exchangeRateService),
+		ExchangeRateService: exchangeRateService,
+		InvoiceService:      NewInvoiceService(store, cfg.InvoiceTaxRate),
+		Tokens:              tokens,
 	}
 }
@@ -1,35 +1,63 @@
 package services
 
 import (
+	"chronospace-be/internal/cancellation"
+	"chronospace-be/internal/currency"
 	db "chronospace-be/internal/db/sqlc"
 	"chronospace-be/internal/models"
+	"chronospace-be/internal/pricing"
+	"chronospace-be/internal/utils"
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+
+	err2 "chronospace-be/internal/models/enums"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type IServiceRepository interface {
+	slotSource
+
 	CreateService(ctx context.Context, arg db.CreateServiceParams) (db.Service, error)
 	DeleteService(ctx context.Context, id pgtype.UUID) error
 	GetService(ctx context.Context, id pgtype.UUID) (db.Service, error)
+	ListPricingRulesByServices(ctx context.Context, serviceIds []pgtype.UUID) ([]db.PricingRule, error)
 	ListServices(ctx context.Context) ([]db.Service, error)
+	ListServicesByOwner(ctx context.Context, ownerID pgtype.UUID) ([]db.Service, error)
+	SearchAvailableServices(ctx context.Context, arg db.SearchAvailableServicesParams) ([]db.Service, error)
 	UpdateService(ctx context.Context, arg db.UpdateServiceParams) (db.Service, error)
 }
 
+// defaultMaxGuests is used when a service is created without a capacity.
+const defaultMaxGuests = 2
+
+// searchBatchSize is how many candidate services an availability search
+// checks against their schedules at a time.
+const searchBatchSize = 100
+
 type ServiceService struct {
-	serviceRepo IServiceRepository
-	mapsService MapsService
+	serviceRepo     IServiceRepository
+	mapsService     MapsService
+	exchangeRates   *ExchangeRateService
+	defaultCurrency string
 }
 
-func NewServiceService(serviceRepository IServiceRepository, maps MapsService) *ServiceService {
+func NewServiceService(serviceRepository IServiceRepository, maps MapsService, exchangeRates *ExchangeRateService, defaultCurrency string) *ServiceService {
+	if defaultCurrency == "" {
+		defaultCurrency = "EUR"
+	}
 	return &ServiceService{
-		serviceRepo: serviceRepository,
-		mapsService: maps,
+		serviceRepo:     serviceRepository,
+		mapsService:     maps,
+		exchangeRates:   exchangeRates,
+		defaultCurrency: strings.ToUpper(defaultCurrency),
 	}
-} 
+}
 
-func (s *ServiceService) CreateService(ctx context.Context, req models.CreateServiceRequest) (*models.ServiceResponse, error) {
+func (s *ServiceService) CreateService(ctx context.Context, actor models.Actor, req models.CreateServiceRequest) (*models.ServiceResponse, error) {
 	// First validate if the location exists
 	isValid, err := s.mapsService.ValidateLocation(ctx, req.Location)
 	if err != nil {
@@ -44,6 +72,23 @@ func (s *ServiceService) CreateService(ctx context.Context, req models.CreateSer
 		Description: req.Description,
 		Price:       req.Price,
 		Location:    req.Location,
+		OwnerID:     actor.UserID,
+		MaxGuests:   req.MaxGuests,
+		Currency:    req.Currency,
+	}
+	if arg.MaxGuests == 0 {
+		arg.MaxGuests = defaultMaxGuests
+	}
+	if arg.Currency == "" {
+		arg.Currency = s.defaultCurrency
+	}
+	arg.Currency, err = validatePrice(arg.Price, arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	arg.CancellationPolicy, err = encodeCancellationPolicy(req.CancellationPolicy, nil)
+	if err != nil {
+		return nil, err
 	}
 
 	service, err := s.serviceRepo.CreateService(ctx, arg)
@@ -57,25 +102,50 @@ func (s *ServiceService) CreateService(ctx context.Context, req models.CreateSer
 		Description: service.Description,
 		Price:       service.Price,
 		Location:    service.Location,
+		OwnerID:     service.OwnerID,
+		MaxGuests:   service.MaxGuests,
+		Currency:    service.Currency,
+
+		CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
 	}, nil
 }
 
-func (s *ServiceService) GetService(ctx context.Context, id pgtype.UUID) (*models.ServiceResponse, error) {
+// GetService returns a service, with its price converted to displayCurrency
+// when one is given.
+func (s *ServiceService) GetService(ctx context.Context, id pgtype.UUID, displayCurrency string) (*models.ServiceResponse, error) {
+	display, rates, err := s.exchangeRates.displayRates(ctx, displayCurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	service, err := s.serviceRepo.GetService(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.ServiceResponse{
+	response := &models.ServiceResponse{
 		ID:          service.ID,
 		Name:        service.Name,
 		Description: service.Description,
 		Price:       service.Price,
 		Location:    service.Location,
-	}, nil
+		OwnerID:     service.OwnerID,
+		MaxGuests:   service.MaxGuests,
+		Currency:    service.Currency,
+
+		CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
+	}
+	if rates != nil {
+		response.DisplayPrice, err = convertMoney(rates, service.Price, service.Currency, display)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return response, nil
 }
 
-func (s *ServiceService) UpdateService(ctx context.Context, id pgtype.UUID, req models.UpdateServiceRequest) (*models.ServiceResponse, error) {
+func (s *ServiceService) UpdateService(ctx context.Context, actor models.Actor, id pgtype.UUID, req models.UpdateServiceRequest) (*models.ServiceResponse, error) {
 	// If location is being updated, validate it
 	if req.Location != "" {
 		isValid, err := s.mapsService.ValidateLocation(ctx, req.Location)
@@ -93,6 +163,10 @@ func (s *ServiceService) UpdateService(ctx context.Context, id pgtype.UUID, req
 		return nil, err
 	}
 
+	if !actor.CanManage(existingService.OwnerID) {
+		return nil, err2.ErrForbidden
+	}
+
 	// Prepare update parameters, keeping existing values if not provided in request
 	arg := db.UpdateServiceParams{
 		ID:          id,
@@ -100,6 +174,8 @@ func (s *ServiceService) UpdateService(ctx context.Context, id pgtype.UUID, req
 		Description: req.Description,
 		Price:       req.Price,
 		Location:    req.Location,
+		MaxGuests:   req.MaxGuests,
+		Currency:    req.Currency,
 	}
 
 	// If fields are empty, keep existing values
@@ -115,6 +191,20 @@ func (s *ServiceService) UpdateService(ctx context.Context, id pgtype.UUID, req
 	if req.Location == "" {
 		arg.Location = existingService.Location
 	}
+	if req.MaxGuests == 0 {
+		arg.MaxGuests = existingService.MaxGuests
+	}
+	if req.Currency == "" {
+		arg.Currency = existingService.Currency
+	}
+	arg.Currency, err = validatePrice(arg.Price, arg.Currency)
+	if err != nil {
+		return nil, err
+	}
+	arg.CancellationPolicy, err = encodeCancellationPolicy(req.CancellationPolicy, existingService.CancellationPolicy)
+	if err != nil {
+		return nil, err
+	}
 
 	service, err := s.serviceRepo.UpdateService(ctx, arg)
 	if err != nil {
@@ -127,16 +217,25 @@ func (s *ServiceService) UpdateService(ctx context.Context, id pgtype.UUID, req
 		Description: service.Description,
 		Price:       service.Price,
 		Location:    service.Location,
+		OwnerID:     service.OwnerID,
+		MaxGuests:   service.MaxGuests,
+		Currency:    service.Currency,
+
+		CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
 	}, nil
 }
 
-func (s *ServiceService) DeleteService(ctx context.Context, id pgtype.UUID) error {
+func (s *ServiceService) DeleteService(ctx context.Context, actor models.Actor, id pgtype.UUID) error {
 	// Check if service exists
-	_, err := s.serviceRepo.GetService(ctx, id)
+	existingService, err := s.serviceRepo.GetService(ctx, id)
 	if err != nil {
 		return fmt.Errorf("service not found: %v", err)
 	}
 
+	if !actor.CanManage(existingService.OwnerID) {
+		return err2.ErrForbidden
+	}
+
 	// Delete the service
 	err = s.serviceRepo.DeleteService(ctx, id)
 	if err != nil {
@@ -146,13 +245,53 @@ func (s *ServiceService) DeleteService(ctx context.Context, id pgtype.UUID) erro
 	return nil
 }
 
-func (s *ServiceService) ListServices(ctx context.Context) ([]*models.ServiceResponse, error) {
+// ListServices returns all services, with their prices converted to
+// displayCurrency when one is given.
+func (s *ServiceService) ListServices(ctx context.Context, displayCurrency string) ([]*models.ServiceResponse, error) {
+	display, rates, err := s.exchangeRates.displayRates(ctx, displayCurrency)
+	if err != nil {
+		return nil, err
+	}
+
 	services, err := s.serviceRepo.ListServices(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list services: %v", err)
 	}
 
 	var response []*models.ServiceResponse
+	for _, service := range services {
+		item := &models.ServiceResponse{
+			ID:          service.ID,
+			Name:        service.Name,
+			Description: service.Description,
+			Price:       service.Price,
+			Location:    service.Location,
+			OwnerID:     service.OwnerID,
+			MaxGuests:   service.MaxGuests,
+			Currency:    service.Currency,
+
+			CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
+		}
+		if rates != nil {
+			item.DisplayPrice, err = convertMoney(rates, service.Price, service.Currency, display)
+			if err != nil {
+				return nil, err
+			}
+		}
+		response = append(response, item)
+	}
+
+	return response, nil
+}
+
+// ListServicesByOwner returns the services listed by the given provider.
+func (s *ServiceService) ListServicesByOwner(ctx context.Context, ownerID pgtype.UUID) ([]*models.ServiceResponse, error) {
+	services, err := s.serviceRepo.ListServicesByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %v", err)
+	}
+
+	response := make([]*models.ServiceResponse, 0, len(services))
 	for _, service := range services {
 		response = append(response, &models.ServiceResponse{
 			ID:          service.ID,
@@ -160,8 +299,272 @@ func (s *ServiceService) ListServices(ctx context.Context) ([]*models.ServiceRes
 			Description: service.Description,
 			Price:       service.Price,
 			Location:    service.Location,
+			OwnerID:     service.OwnerID,
+			MaxGuests:   service.MaxGuests,
+			Currency:    service.Currency,
+
+			CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
 		})
 	}
 
 	return response, nil
 }
+
+// SearchAvailability returns the services that can host the requested number
+// of guests for every night between check-in and check-out, together with the
+// total price of the stay under their pricing rules. Cheapest stays come first.
+func (s *ServiceService) SearchAvailability(ctx context.Context, query models.AvailabilitySearchQuery) ([]*models.AvailableServiceResponse, error) {
+	checkIn := pgtype.Date{Time: query.CheckIn, Valid: true}
+	checkOut := pgtype.Date{Time: query.CheckOut, Valid: true}
+	if !query.CheckOut.After(query.CheckIn) || query.CheckOut.Sub(query.CheckIn) > maxSlotWindow {
+		return nil, err2.ErrBookingInvalidDateRange
+	}
+
+	display, rates, err := s.exchangeRates.displayRates(ctx, query.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Services may be priced in different currencies, so prices are
+	// converted to the requested currency, or the default one, before they
+	// are filtered and sorted
+	bounds := priceBounds{code: s.defaultCurrency}
+	if display != "" {
+		bounds.code = display
+	}
+	if bounds.min, err = parsePriceBound(query.MinPrice, bounds.code, "min_price"); err != nil {
+		return nil, err
+	}
+	if bounds.max, err = parsePriceBound(query.MaxPrice, bounds.code, "max_price"); err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.serviceRepo.SearchAvailableServices(ctx, db.SearchAvailableServicesParams{
+		CheckIn:          checkIn,
+		CheckOut:         checkOut,
+		Location:         pgtype.Text{String: query.Location, Valid: query.Location != ""},
+		Guests:           query.Guests,
+		InactiveStatuses: inactiveBookingStatuses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search services: %w", err)
+	}
+
+	// Stays are priced like quotes, which SQL cannot do, so every candidate
+	// is priced before sorting. Only the schedules are expanded lazily.
+	stays, err := s.priceStays(ctx, candidates, checkIn, checkOut, bounds, rates)
+	if err != nil {
+		return nil, err
+	}
+
+	// Schedules may come from recurring rules, so nightly availability is
+	// checked after expanding them rather than in SQL. Stays are checked in
+	// batches, cheapest first, until the page is full.
+	nights := countNights(checkIn, checkOut)
+	response := make([]*models.AvailableServiceResponse, 0, query.Limit)
+	skipped := int32(0)
+	for start := 0; start < len(stays); start += searchBatchSize {
+		batch := stays[start:min(start+searchBatchSize, len(stays))]
+
+		serviceIDs := make([]pgtype.UUID, len(batch))
+		for i, stay := range batch {
+			serviceIDs[i] = stay.service.ID
+		}
+		slots, err := expandSlots(ctx, s.serviceRepo, serviceIDs, query.CheckIn, query.CheckOut)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search services: %w", err)
+		}
+
+		for _, stay := range batch {
+			service := stay.service
+			if !stayIsOpen(slots[service.ID], query.CheckIn, query.CheckOut) {
+				continue
+			}
+			if skipped < query.Offset {
+				skipped++
+				continue
+			}
+			item := &models.AvailableServiceResponse{
+				ServiceResponse: models.ServiceResponse{
+					ID:          service.ID,
+					Name:        service.Name,
+					Description: service.Description,
+					Price:       service.Price,
+					Location:    service.Location,
+					OwnerID:     service.OwnerID,
+					MaxGuests:   service.MaxGuests,
+					Currency:    service.Currency,
+
+					CancellationPolicy: toCancellationPolicy(service.CancellationPolicy),
+				},
+				Nights:     nights,
+				TotalPrice: currency.FromMinor(stay.quote.Total, service.Currency),
+			}
+			if rates != nil {
+				item.DisplayPrice, err = convertMoney(rates, service.Price, service.Currency, display)
+				if err != nil {
+					return nil, err
+				}
+				item.DisplayTotalPrice, err = convertTotal(rates, stay.quote, service.Currency, display)
+				if err != nil {
+					return nil, err
+				}
+			}
+			response = append(response, item)
+			if int32(len(response)) == query.Limit {
+				return response, nil
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// pricedStay is a candidate of an availability search with the price of the
+// stay. Total is in the currency prices are compared in.
+type pricedStay struct {
+	service db.Service
+	quote   pricing.Quote
+	total   int64
+}
+
+// priceBounds limits the nightly price of search results. Prices are
+// compared in minor units of code; nil bounds leave that side open.
+type priceBounds struct {
+	code     string
+	min, max *int64
+}
+
+func parsePriceBound(raw, code, field string) (*int64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var amount pgtype.Numeric
+	if err := amount.Scan(raw); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", err2.ErrInvalidPrice, field, err)
+	}
+	minor, err := currency.ToMinor(amount, code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", err2.ErrInvalidPrice, field, err)
+	}
+	return &minor, nil
+}
+
+// priceStays prices the stay at every service with its pricing rules, as a
+// quote would, and orders the stays by total. Services whose minimum stay is
+// not met or whose nightly price is out of bounds are left out. Rates are
+// loaded when a service is priced in another currency than the bounds and
+// none are given.
+func (s *ServiceService) priceStays(ctx context.Context, services []db.Service, checkIn, checkOut pgtype.Date, bounds priceBounds, rates currency.Rates) ([]pricedStay, error) {
+	serviceIDs := make([]pgtype.UUID, len(services))
+	for i, service := range services {
+		serviceIDs[i] = service.ID
+	}
+	stored, err := s.serviceRepo.ListPricingRulesByServices(ctx, serviceIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search services: %w", err)
+	}
+	rules := make(map[pgtype.UUID][]db.PricingRule)
+	for _, rule := range stored {
+		rules[rule.ServiceID] = append(rules[rule.ServiceID], rule)
+	}
+
+	stays := make([]pricedStay, 0, len(services))
+	for _, service := range services {
+		quote, err := calculateQuote(service, rules[service.ID], checkIn, checkOut)
+		if errors.Is(err, err2.ErrMinimumStayNotMet) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		nightly, err := currency.ToMinor(service.Price, service.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", err2.ErrInvalidPrice, err)
+		}
+
+		total := quote.Total
+		if service.Currency != bounds.code {
+			if rates == nil {
+				if rates, err = s.exchangeRates.Rates(ctx); err != nil {
+					return nil, err
+				}
+			}
+			rate, err := rates.Rate(service.Currency, bounds.code)
+			if err != nil {
+				return nil, utils.ReplaceError(err, currency.ErrNoRate, err2.ErrExchangeRateNotFound)
+			}
+			total = convertQuote(quote, service.Currency, bounds.code, rate).Total
+			nightly = currency.ConvertAt(nightly, service.Currency, bounds.code, rate)
+		}
+
+		if (bounds.min != nil && nightly < *bounds.min) || (bounds.max != nil && nightly > *bounds.max) {
+			continue
+		}
+		stays = append(stays, pricedStay{service: service, quote: quote, total: total})
+	}
+
+	// Candidates come ordered by name, which breaks ties
+	sort.SliceStable(stays, func(i, j int) bool {
+		return stays[i].total < stays[j].total
+	})
+	return stays, nil
+}
+
+// convertTotal converts the total of a quote the way a quote shown in
+// another currency adds up.
+func convertTotal(rates currency.Rates, quote pricing.Quote, from, to string) (*models.Money, error) {
+	rate, err := rates.Rate(from, to)
+	if err != nil {
+		return nil, utils.ReplaceError(err, currency.ErrNoRate, err2.ErrExchangeRateNotFound)
+	}
+	converted := convertQuote(quote, from, to, rate)
+	return &models.Money{Amount: currency.FromMinor(converted.Total, to), Currency: to}, nil
+}
+
+// validatePrice checks that a price is positive and fits the minor unit of its
+// currency, and returns the normalized currency code.
+func validatePrice(price pgtype.Numeric, code string) (string, error) {
+	code, err := currency.Normalize(code)
+	if err != nil {
+		return "", utils.ReplaceError(err, currency.ErrUnknownCurrency, err2.ErrUnknownCurrency)
+	}
+
+	minor, err := currency.ToMinor(price, code)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", err2.ErrInvalidPrice, err)
+	}
+	if minor <= 0 {
+		return "", fmt.Errorf("%w: must be positive", err2.ErrInvalidPrice)
+	}
+	return code, nil
+}
+
+// encodeCancellationPolicy validates a requested policy for storage. Without
+// a request the current policy is kept, or the default one for new services.
+func encodeCancellationPolicy(req *cancellation.Policy, current []byte) ([]byte, error) {
+	if req == nil {
+		if current != nil {
+			return current, nil
+		}
+		return cancellation.Default().Marshal()
+	}
+
+	policy, err := cancellation.Normalize(*req)
+	if err != nil {
+		return nil, utils.ReplaceError(err, cancellation.ErrInvalidPolicy, err2.ErrInvalidCancellationPolicy)
+	}
+	return policy.Marshal()
+}
+
+// toCancellationPolicy decodes a stored policy. Stored policies were
+// validated on the way in, so decoding only fails for corrupt rows, which
+// fall back to the default policy.
+func toCancellationPolicy(raw []byte) cancellation.Policy {
+	policy, err := cancellation.Parse(raw)
+	if err != nil {
+		return cancellation.Default()
+	}
+	return policy
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"chronospace-be/internal/currency"
+	db "chronospace-be/internal/db/sqlc"
+	"chronospace-be/internal/models"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	err2 "chronospace-be/internal/models/enums"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeServiceRepository returns its services as search candidates, with every
+// night of every service open.
+type fakeServiceRepository struct {
+	services []db.Service
+	rules    []db.PricingRule
+}
+
+func (r *fakeServiceRepository) CreateService(context.Context, db.CreateServiceParams) (db.Service, error) {
+	return db.Service{}, errors.New("not implemented")
+}
+
+func (r *fakeServiceRepository) DeleteService(context.Context, pgtype.UUID) error {
+	return errors.New("not implemented")
+}
+
+func (r *fakeServiceRepository) GetService(context.Context, pgtype.UUID) (db.Service, error) {
+	return db.Service{}, errors.New("not implemented")
+}
+
+func (r *fakeServiceRepository) ListPricingRulesByServices(_ context.Context, serviceIDs []pgtype.UUID) ([]db.PricingRule, error) {
+	var rules []db.PricingRule
+	for _, rule := range r.rules {
+		for _, id := range serviceIDs {
+			if rule.ServiceID == id {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeServiceRepository) ListScheduleBlackoutsInWindow(context.Context, db.ListScheduleBlackoutsInWindowParams) ([]db.ScheduleBlackout, error) {
+	return nil, nil
+}
+
+func (r *fakeServiceRepository) ListScheduleRuleExceptions(context.Context, []pgtype.UUID) ([]db.ScheduleRuleException, error) {
+	return nil, nil
+}
+
+func (r *fakeServiceRepository) ListScheduleRulesByServices(context.Context, []pgtype.UUID) ([]db.ScheduleRule, error) {
+	return nil, nil
+}
+
+func (r *fakeServiceRepository) ListSchedulesInWindow(_ context.Context, arg db.ListSchedulesInWindowParams) ([]db.Schedule, error) {
+	var schedules []db.Schedule
+	for _, id := range arg.ServiceIds {
+		for date := arg.WindowStart.Time; date.Before(arg.WindowEnd.Time); date = date.AddDate(0, 0, 1) {
+			schedules = append(schedules, db.Schedule{
+				ServiceID: id,
+				Date:      pgtype.Date{Time: date, Valid: true},
+				Status:    err2.ScheduleAvailableStatus,
+			})
+		}
+	}
+	return schedules, nil
+}
+
+func (r *fakeServiceRepository) ListServices(context.Context) ([]db.Service, error) {
+	return r.services, nil
+}
+
+func (r *fakeServiceRepository) ListServicesByOwner(context.Context, pgtype.UUID) ([]db.Service, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (r *fakeServiceRepository) SearchAvailableServices(context.Context, db.SearchAvailableServicesParams) ([]db.Service, error) {
+	return r.services, nil
+}
+
+func (r *fakeServiceRepository) UpdateService(context.Context, db.UpdateServiceParams) (db.Service, error) {
+	return db.Service{}, errors.New("not implemented")
+}
+
+func testService(id byte, name string, cents int64) db.Service {
+	return db.Service{
+		ID:        pgtype.UUID{Bytes: [16]byte{id}, Valid: true},
+		Name:      name,
+		Price:     pgtype.Numeric{Int: big.NewInt(cents), Exp: -2, Valid: true},
+		MaxGuests: 2,
+		Currency:  "EUR",
+	}
+}
+
+func TestServiceServiceSearchAvailabilityPricing(t *testing.T) {
+	alpha := testService(1, "Alpha", 10000)
+	bravo := testService(2, "Bravo", 9000)
+	charlie := testService(3, "Charlie", 5000)
+
+	repo := &fakeServiceRepository{
+		services: []db.Service{alpha, bravo, charlie},
+		rules: []db.PricingRule{
+			{
+				ServiceID:         bravo.ID,
+				Kind:              "weekday",
+				DaysOfWeek:        []int32{int32(time.Friday), int32(time.Saturday)},
+				AdjustmentPercent: pgtype.Int4{Int32: 50, Valid: true},
+			},
+			{
+				ServiceID: charlie.ID,
+				Kind:      "min_stay",
+				MinNights: pgtype.Int4{Int32: 3, Valid: true},
+			},
+		},
+	}
+	service := &ServiceService{serviceRepo: repo, defaultCurrency: "EUR"}
+
+	// A Friday and a Saturday night
+	checkIn := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+	checkOut := checkIn.AddDate(0, 0, 2)
+
+	tests := []struct {
+		name       string
+		offset     int32
+		wantNames  []string
+		wantTotals []int64
+	}{
+		{
+			// Bravo's base rate is cheaper, but its weekend surcharge is not;
+			// Charlie needs three nights
+			name:       "priced with rules",
+			wantNames:  []string{"Alpha", "Bravo"},
+			wantTotals: []int64{20000, 27000},
+		},
+		{
+			name:       "second page",
+			offset:     1,
+			wantNames:  []string{"Bravo"},
+			wantTotals: []int64{27000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.SearchAvailability(context.Background(), models.AvailabilitySearchQuery{
+				CheckIn:  checkIn,
+				CheckOut: checkOut,
+				Guests:   1,
+				Limit:    20,
+				Offset:   tt.offset,
+			})
+			if err != nil {
+				t.Fatalf("SearchAvailability() error = %v", err)
+			}
+
+			if len(results) != len(tt.wantNames) {
+				t.Fatalf("SearchAvailability() returned %d services, want %d", len(results), len(tt.wantNames))
+			}
+			for i, result := range results {
+				total, err := currency.ToMinor(result.TotalPrice, result.Currency)
+				if err != nil {
+					t.Fatalf("total price of %s: %v", result.Name, err)
+				}
+				if result.Name != tt.wantNames[i] || total != tt.wantTotals[i] {
+					t.Errorf("result %d = %s at %d, want %s at %d", i, result.Name, total, tt.wantNames[i], tt.wantTotals[i])
+				}
+			}
+		})
+	}
+}
+
+// fakeExchangeRateRepository serves a fixed set of exchange rates.
+type fakeExchangeRateRepository struct {
+	rates []db.ExchangeRate
+}
+
+func (r *fakeExchangeRateRepository) ExecTx(context.Context, func(*db.Queries) error) error {
+	return errors.New("not implemented")
+}
+
+func (r *fakeExchangeRateRepository) ListExchangeRates(context.Context) ([]db.ExchangeRate, error) {
+	return r.rates, nil
+}
+
+func TestServiceServiceSearchAvailabilityCurrencies(t *testing.T) {
+	// 6000 JPY is 37.50 EUR: cheaper than 50 EUR, though the raw amount is larger
+	yen := db.Service{
+		ID:        pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		Name:      "Yen",
+		Price:     pgtype.Numeric{Int: big.NewInt(6000), Valid: true},
+		MaxGuests: 2,
+		Currency:  "JPY",
+	}
+	euro := testService(2, "Euro", 5000)
+
+	service := &ServiceService{
+		serviceRepo: &fakeServiceRepository{services: []db.Service{euro, yen}},
+		exchangeRates: NewExchangeRateService(&fakeExchangeRateRepository{rates: []db.ExchangeRate{{
+			Base:  "EUR",
+			Quote: "JPY",
+			Rate:  pgtype.Numeric{Int: big.NewInt(160), Valid: true},
+		}}}),
+		defaultCurrency: "EUR",
+	}
+
+	checkIn := time.Date(2024, time.March, 4, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		currency  string
+		minPrice  string
+		maxPrice  string
+		wantNames []string
+		wantErr   error
+	}{
+		{name: "sorted in the default currency", wantNames: []string{"Yen", "Euro"}},
+		{name: "sorted in the requested currency", currency: "JPY", wantNames: []string{"Yen", "Euro"}},
+		{name: "maximum in the default currency", maxPrice: "40", wantNames: []string{"Yen"}},
+		{name: "minimum in the default currency", minPrice: "40", wantNames: []string{"Euro"}},
+		{name: "maximum in the requested currency", currency: "JPY", maxPrice: "7000", wantNames: []string{"Yen"}},
+		{name: "minimum in the requested currency", currency: "JPY", minPrice: "8000", wantNames: []string{"Euro"}},
+		{name: "bound finer than the currency", currency: "JPY", minPrice: "10.5", wantErr: err2.ErrInvalidPrice},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := service.SearchAvailability(context.Background(), models.AvailabilitySearchQuery{
+				CheckIn:  checkIn,
+				CheckOut: checkIn.AddDate(0, 0, 2),
+				Guests:   1,
+				MinPrice: tt.minPrice,
+				MaxPrice: tt.maxPrice,
+				Limit:    20,
+				Currency: tt.currency,
+			})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SearchAvailability() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SearchAvailability() error = %v", err)
+			}
+
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+			if len(names) != len(tt.wantNames) {
+				t.Fatalf("SearchAvailability() = %v, want %v", names, tt.wantNames)
+			}
+			for i := range names {
+				if names[i] != tt.wantNames[i] {
+					t.Fatalf("SearchAvailability() = %v, want %v", names, tt.wantNames)
+				}
+			}
+		})
+	}
+}
@@ -1,14 +1,22 @@
 package services
 
 import (
+	"chronospace-be/internal/config"
 	db "chronospace-be/internal/db/sqlc"
 	"chronospace-be/internal/models"
+	"chronospace-be/internal/token"
 	"chronospace-be/internal/utils"
 	"context"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"log"
+	"net/mail"
 	"strings"
 	"time"
+	"unicode"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 
@@ -16,26 +24,94 @@ import (
 )
 
 type IUserRepository interface {
+	CreateEmailVerificationToken(ctx context.Context, arg db.CreateEmailVerificationTokenParams) (db.EmailVerificationToken, error)
+	CountServicesByOwner(ctx context.Context, ownerID pgtype.UUID) (int64, error)
+	CountUnusedRecoveryCodes(ctx context.Context, userID pgtype.UUID) (int64, error)
+	CountUpcomingBookingsByUser(ctx context.Context, arg db.CountUpcomingBookingsByUserParams) (int64, error)
+	CreatePasswordResetToken(ctx context.Context, arg db.CreatePasswordResetTokenParams) (db.PasswordResetToken, error)
 	CreateUser(ctx context.Context, arg db.CreateUserParams) (db.User, error)
 	CreateUserToken(ctx context.Context, arg db.CreateUserTokenParams) (db.UserToken, error)
-	DeleteUser(ctx context.Context, id pgtype.UUID) error
+	DeleteEmailVerificationTokensByUser(ctx context.Context, userID pgtype.UUID) error
+	DeleteExpiredTokens(ctx context.Context) error
+	DeleteOtherUserSessions(ctx context.Context, arg db.DeleteOtherUserSessionsParams) error
+	DeletePasswordResetTokensByUser(ctx context.Context, userID pgtype.UUID) error
+	DeleteUserSession(ctx context.Context, arg db.DeleteUserSessionParams) (int64, error)
+	DeleteUserTokensByUserID(ctx context.Context, userID pgtype.UUID) error
+	ExecTx(ctx context.Context, fn func(*db.Queries) error) error
+	GetEmailVerificationToken(ctx context.Context, tokenHash string) (db.EmailVerificationToken, error)
+	GetPasswordResetToken(ctx context.Context, tokenHash string) (db.PasswordResetToken, error)
 	GetUser(ctx context.Context, id pgtype.UUID) (db.User, error)
 	GetUserByEmail(ctx context.Context, email string) (db.User, error)
 	GetUserByUsername(ctx context.Context, username string) (db.User, error)
+	GetUserTokenByRefreshToken(ctx context.Context, refreshToken string) (db.UserToken, error)
+	ListBookingStatusHistory(ctx context.Context, bookingID pgtype.UUID) ([]db.BookingStatusHistory, error)
+	ListBookingsByUser(ctx context.Context, userID pgtype.UUID) ([]db.Booking, error)
+	ListUserIdentities(ctx context.Context, userID pgtype.UUID) ([]db.UserIdentity, error)
+	ListUserSessions(ctx context.Context, userID pgtype.UUID) ([]db.UserToken, error)
 	ListUsers(ctx context.Context, arg db.ListUsersParams) ([]db.User, error)
+	LockUser(ctx context.Context, arg db.LockUserParams) error
+	MarkUserEmailUnverified(ctx context.Context, id pgtype.UUID) error
+	MarkUserEmailVerified(ctx context.Context, id pgtype.UUID) (db.User, error)
+	PruneUserSessions(ctx context.Context, arg db.PruneUserSessionsParams) error
+	RecordFailedLogin(ctx context.Context, arg db.RecordFailedLoginParams) (db.User, error)
+	RotateUserToken(ctx context.Context, id pgtype.UUID) (db.UserToken, error)
+	SetUserTOTPSecret(ctx context.Context, arg db.SetUserTOTPSecretParams) error
+	UnlockUser(ctx context.Context, id pgtype.UUID) (db.User, error)
 	UpdateUser(ctx context.Context, arg db.UpdateUserParams) (db.User, error)
-	UpdateUserToken(ctx context.Context, arg db.UpdateUserTokenParams) (db.UserToken, error)
+	UpdateUserRole(ctx context.Context, arg db.UpdateUserRoleParams) (db.User, error)
+	UseEmailVerificationToken(ctx context.Context, id pgtype.UUID) (db.EmailVerificationToken, error)
+	UseRecoveryCode(ctx context.Context, arg db.UseRecoveryCodeParams) (int64, error)
+	UseTOTPStep(ctx context.Context, arg db.UseTOTPStepParams) (int64, error)
+}
+
+const (
+	// emailVerificationTTL is how long a verification link stays valid.
+	emailVerificationTTL = 24 * time.Hour
+	// passwordResetTTL is how long a password reset link stays valid.
+	passwordResetTTL = time.Hour
+	// loginFreeAttempts is how many failed sign-ins an account gets before
+	// every further attempt has to wait for a growing delay.
+	loginFreeAttempts = 3
+	// totpIssuer labels the account in authenticator apps.
+	totpIssuer = "Chronospace"
+	// recoveryCodeCount is how many recovery codes are issued at a time.
+	recoveryCodeCount = 10
+)
+
+// LoginBlockedError is returned when a sign-in is refused before the
+// password is checked. Err is ErrAccountLocked or ErrLoginThrottled.
+type LoginBlockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Err
 }
 
 type UserService struct {
-	userRepo  IUserRepository
-	secretKey string
+	userRepo         IUserRepository
+	notifier         *NotificationService
+	tokens           *token.Manager
+	mfaKey           string
+	maxSessions      int
+	loginMaxAttempts int
+	loginLockout     time.Duration
 }
 
-func NewUserService(userRepository IUserRepository, secretKey string) *UserService {
+func NewUserService(userRepository IUserRepository, notifier *NotificationService, tokens *token.Manager, cfg *config.Config) *UserService {
 	return &UserService{
-		userRepo: userRepository,
-		secretKey: secretKey,
+		userRepo:         userRepository,
+		notifier:         notifier,
+		tokens:           tokens,
+		mfaKey:           cfg.MFAEncryptionKey,
+		maxSessions:      cfg.MaxSessions,
+		loginMaxAttempts: cfg.LoginMaxAttempts,
+		loginLockout:     cfg.LoginLockoutDuration,
 	}
 }
 
@@ -50,16 +126,26 @@ func (s *UserService) RegisterUser(ctx context.Context, params models.CreateUser
 	params.Username = strings.TrimSpace(params.Username)
 
 	// Basic validation
-	if len(params.Password) < 8 {
-		return models.UserCreatedResponse{}, err2.ErrPassword8Symbols
+	if err := checkPasswordPolicy(params.Password, params.Username, params.Email); err != nil {
+		return models.UserCreatedResponse{}, err
 	}
 
-	if !strings.Contains(params.Email, "@") {
-		return models.UserCreatedResponse{}, err2.ErrInvalidEmailFormat
+	email, err := normalizeEmail(params.Email)
+	if err != nil {
+		return models.UserCreatedResponse{}, err
+	}
+	params.Email = email
+
+	// Admins can only be appointed by other admins
+	if params.Role == "" {
+		params.Role = err2.GuestRole
+	}
+	if params.Role != err2.GuestRole && params.Role != err2.ProviderRole {
+		return models.UserCreatedResponse{}, err2.ErrInvalidRole
 	}
 
 	// Check if email already exists (using case-insensitive comparison)
-	if _, err := s.userRepo.GetUserByEmail(ctx, strings.ToLower(params.Email)); err == nil {
+	if _, err := s.userRepo.GetUserByEmail(ctx, params.Email); err == nil {
 		return models.UserCreatedResponse{}, err2.ErrEmailAlreadyExists
 	}
 
@@ -83,39 +169,41 @@ func (s *UserService) RegisterUser(ctx context.Context, params models.CreateUser
 
 	// Update params with hashed password and normalized email/username
 	params.Password = string(hashedPassword)
-	params.Email = strings.ToLower(params.Email)
 	params.Username = strings.ToLower(params.Username)
 
 	// Create user with timeout context
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	_, err = s.userRepo.CreateUser(ctx, db.CreateUserParams{
+	user, err := s.userRepo.CreateUser(ctx, db.CreateUserParams{
 		Username: params.Username,
 		FullName: params.FullName,
 		Email:    params.Email,
 		Password: params.Password,
+		Role:     params.Role,
 	})
 	if err != nil {
 		return models.UserCreatedResponse{}, fmt.Errorf("error creating user: %w", err)
 	}
 
+	// The account exists either way; a lost mail can be requested again
+	if err := s.sendEmailVerification(ctx, user); err != nil {
+		log.Printf("email verification for %s: %v", user.Email, err)
+	}
+
 	return models.UserCreatedResponse{
-		Message: "user created successfully",
+		Message: "user created successfully, check your inbox to verify your email address",
 	}, nil
 }
 
-func (s *UserService) LoginUser(ctx context.Context, email, password string) (models.LoginResponse, error) {
+func (s *UserService) LoginUser(ctx context.Context, email, password string, device models.Device) (models.LoginResponse, error) {
 	if ctx == nil {
 		return models.LoginResponse{}, err2.ErrInvalidContex
 	}
 
-	// Trim and normalize email
-	email = strings.ToLower(strings.TrimSpace(email))
-
-	// Basic email validation
-	if !strings.Contains(email, "@") {
-		return models.LoginResponse{}, err2.ErrInvalidEmailFormat
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return models.LoginResponse{}, err
 	}
 
 	// Create timeout context
@@ -128,42 +216,258 @@ func (s *UserService) LoginUser(ctx context.Context, email, password string) (mo
 		return models.LoginResponse{}, err2.ErrInvalidCredentials
 	}
 
+	// Refuse locked and throttled accounts before spending time on bcrypt
+	now := time.Now()
+	if err := s.checkLoginAllowed(user, now); err != nil {
+		return models.LoginResponse{}, err
+	}
+
 	// Compare passwords
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return models.LoginResponse{}, err2.ErrInvalidCredentials
+		return models.LoginResponse{}, s.recordFailedLogin(ctx, user, now, err2.ErrInvalidCredentials)
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil.Valid {
+		if _, err := s.userRepo.UnlockUser(ctx, user.ID); err != nil {
+			return models.LoginResponse{}, fmt.Errorf("error resetting failed logins: %w", err)
+		}
+	}
+
+	// Only reveal the verification state to someone who knows the password
+	if !user.EmailVerifiedAt.Valid {
+		return models.LoginResponse{}, err2.ErrEmailNotVerified
+	}
+
+	return s.completeLogin(ctx, user, device)
+}
+
+// completeLogin signs in a user whose first factor has been checked.
+// Accounts with two-factor authentication get a token that only LoginMFA
+// accepts instead of a session.
+func (s *UserService) completeLogin(ctx context.Context, user db.User, device models.Device) (models.LoginResponse, error) {
+	if user.TotpEnabledAt.Valid {
+		mfaToken, err := s.tokens.GenerateMFAToken(user.ID)
+		if err != nil {
+			return models.LoginResponse{}, err2.ErrGeneratingToken
+		}
+		return models.LoginResponse{MFARequired: true, MFAToken: mfaToken}, nil
+	}
+
+	return s.openSession(ctx, user, device)
+}
+
+// LoginMFA completes the sign-in of an account with two-factor
+// authentication. code is either a current TOTP code or an unused recovery
+// code. Wrong codes count as failed logins.
+func (s *UserService) LoginMFA(ctx context.Context, mfaToken, code string, device models.Device) (models.LoginResponse, error) {
+	if ctx == nil {
+		return models.LoginResponse{}, err2.ErrInvalidContex
 	}
 
-	// Generate JWT token
-	tokens, err := utils.GenerateTokens(ctx, user.ID, s.secretKey)
+	claims, err := s.tokens.Parse(mfaToken, token.TypeMFA)
 	if err != nil {
-		return models.LoginResponse{}, err2.ErrGeneratingToken
+		return models.LoginResponse{}, err2.ErrInvalidMFAToken
 	}
+	userID, _ := claims.User()
 
-	// Store token in database
-	_, err = s.userRepo.UpdateUserToken(ctx, db.UpdateUserTokenParams{
-		ID:                    user.ID,
-		RefreshToken:          tokens.RefreshToken,
-		RefreshTokenExpiresAt: pgtype.Timestamp{Time: tokens.RefreshExpiry, Valid: true},
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil || !user.TotpEnabledAt.Valid {
+		return models.LoginResponse{}, err2.ErrInvalidMFAToken
+	}
+
+	now := time.Now()
+	if err := s.checkLoginAllowed(user, now); err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code, now)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	if !ok {
+		return models.LoginResponse{}, s.recordFailedLogin(ctx, user, now, err2.ErrInvalidMFACode)
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil.Valid {
+		if _, err := s.userRepo.UnlockUser(ctx, user.ID); err != nil {
+			return models.LoginResponse{}, fmt.Errorf("error resetting failed logins: %w", err)
+		}
+	}
+
+	return s.openSession(ctx, user, device)
+}
+
+// checkLoginAllowed refuses sign-ins to locked accounts and to accounts that
+// failed recently and still have to wait out their backoff delay.
+func (s *UserService) checkLoginAllowed(user db.User, now time.Time) error {
+	if user.LockedUntil.Valid && now.Before(user.LockedUntil.Time) {
+		return &LoginBlockedError{Err: err2.ErrAccountLocked, RetryAfter: user.LockedUntil.Time.Sub(now)}
+	}
+
+	if !user.LastFailedLoginAt.Valid {
+		return nil
+	}
+
+	retryAt := user.LastFailedLoginAt.Time.Add(utils.LoginBackoff(int(user.FailedLoginAttempts), loginFreeAttempts))
+	if now.Before(retryAt) {
+		return &LoginBlockedError{Err: err2.ErrLoginThrottled, RetryAfter: retryAt.Sub(now)}
+	}
+
+	return nil
+}
+
+// recordFailedLogin counts a wrong password or second factor against the
+// account and locks it once loginMaxAttempts failures in a row are reached.
+// Below that, failure is returned unchanged.
+func (s *UserService) recordFailedLogin(ctx context.Context, user db.User, now time.Time, failure error) error {
+	user, err := s.userRepo.RecordFailedLogin(ctx, db.RecordFailedLoginParams{
+		ID:                user.ID,
+		LastFailedLoginAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("error recording failed login: %w", err)
+	}
+
+	if s.loginMaxAttempts <= 0 || int(user.FailedLoginAttempts) < s.loginMaxAttempts {
+		return failure
+	}
+
+	err = s.userRepo.LockUser(ctx, db.LockUserParams{
+		ID:          user.ID,
+		LockedUntil: pgtype.Timestamp{Time: now.Add(s.loginLockout), Valid: true},
 	})
 	if err != nil {
-		// If no record exists, create a new one
-		_, err = s.userRepo.CreateUserToken(ctx, db.CreateUserTokenParams{
-			UserID:                user.ID,
-			RefreshToken:          tokens.RefreshToken,
-			RefreshTokenExpiresAt: pgtype.Timestamp{Time: tokens.RefreshExpiry, Valid: true},
+		return fmt.Errorf("error locking account: %w", err)
+	}
+
+	return &LoginBlockedError{Err: err2.ErrAccountLocked, RetryAfter: s.loginLockout}
+}
+
+// RefreshTokens exchanges a valid refresh token for a new token pair. Every
+// refresh token can be used exactly once; presenting one that was already
+// rotated is treated as theft and revokes all refresh tokens of the user.
+func (s *UserService) RefreshTokens(ctx context.Context, refreshToken string, device models.Device) (models.LoginResponse, error) {
+	if ctx == nil {
+		return models.LoginResponse{}, err2.ErrInvalidContex
+	}
+
+	claims, err := s.tokens.Parse(refreshToken, token.TypeRefresh)
+	if err != nil {
+		return models.LoginResponse{}, err2.ErrInvalidRefreshToken
+	}
+	userID, _ := claims.User()
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stored, err := s.userRepo.GetUserTokenByRefreshToken(ctx, utils.HashToken(refreshToken))
+	if err != nil || stored.UserID != userID {
+		return models.LoginResponse{}, err2.ErrInvalidRefreshToken
+	}
+
+	if stored.RotatedAt.Valid {
+		return models.LoginResponse{}, s.revokeTokenFamily(ctx, userID)
+	}
+
+	if !stored.RefreshTokenExpiresAt.Valid || stored.RefreshTokenExpiresAt.Time.Before(time.Now()) {
+		return models.LoginResponse{}, err2.ErrInvalidRefreshToken
+	}
+
+	// Mark the token as used; losing this race means it was replayed concurrently
+	if _, err := s.userRepo.RotateUserToken(ctx, stored.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.LoginResponse{}, s.revokeTokenFamily(ctx, userID)
+		}
+		return models.LoginResponse{}, fmt.Errorf("error rotating token: %w", err)
+	}
+
+	// Pick up role changes made since the previous token was issued
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.LoginResponse{}, err2.ErrInvalidRefreshToken
+	}
+
+	// The new token continues the session of the one it replaces
+	return s.issueTokens(ctx, user, device, stored.SessionID, stored.SessionStartedAt.Time)
+}
+
+// revokeTokenFamily deletes every refresh token of the user after a replayed
+// token was detected and reports the reuse to the caller.
+func (s *UserService) revokeTokenFamily(ctx context.Context, userID pgtype.UUID) error {
+	if err := s.userRepo.DeleteUserTokensByUserID(ctx, userID); err != nil {
+		return fmt.Errorf("error revoking tokens: %w", err)
+	}
+	return err2.ErrRefreshTokenReused
+}
+
+// openSession signs the user in on a new device. When the user already has
+// maxSessions sessions, the least recently used ones are ended.
+func (s *UserService) openSession(ctx context.Context, user db.User, device models.Device) (models.LoginResponse, error) {
+	sessionID, err := utils.NewUUID()
+	if err != nil {
+		return models.LoginResponse{}, err2.ErrGeneratingToken
+	}
+
+	// Rotated tokens are kept for reuse detection only until they expire;
+	// expired rows of every user are cleaned up whenever someone signs in
+	if err := s.userRepo.DeleteExpiredTokens(ctx); err != nil {
+		return models.LoginResponse{}, fmt.Errorf("error deleting expired tokens: %w", err)
+	}
+
+	response, err := s.issueTokens(ctx, user, device, sessionID, time.Now())
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	if s.maxSessions > 0 {
+		err := s.userRepo.PruneUserSessions(ctx, db.PruneUserSessionsParams{
+			UserID: user.ID,
+			Keep:   int32(s.maxSessions),
 		})
 		if err != nil {
-			return models.LoginResponse{}, fmt.Errorf(err2.ErrStoringToken, err)
+			return models.LoginResponse{}, fmt.Errorf("error pruning sessions: %w", err)
 		}
 	}
 
+	return response, nil
+}
+
+// issueTokens generates a new token pair for a session of the user and
+// stores the hash of the refresh token so it can be rotated later.
+func (s *UserService) issueTokens(ctx context.Context, user db.User, device models.Device, sessionID pgtype.UUID, startedAt time.Time) (models.LoginResponse, error) {
+	tokens, err := s.tokens.GenerateTokens(user.ID, user.Role, sessionID)
+	if err != nil {
+		return models.LoginResponse{}, err2.ErrGeneratingToken
+	}
+
+	_, err = s.userRepo.CreateUserToken(ctx, db.CreateUserTokenParams{
+		UserID:                user.ID,
+		RefreshToken:          utils.HashToken(tokens.RefreshToken),
+		RefreshTokenExpiresAt: pgtype.Timestamp{Time: tokens.RefreshExpiry, Valid: true},
+		SessionID:             sessionID,
+		SessionStartedAt:      pgtype.Timestamp{Time: startedAt, Valid: true},
+		UserAgent:             pgtype.Text{String: device.UserAgent, Valid: device.UserAgent != ""},
+		IpAddress:             pgtype.Text{String: device.IPAddress, Valid: device.IPAddress != ""},
+	})
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf(err2.ErrStoringToken, err)
+	}
+
 	return models.LoginResponse{
-		AccessToken: tokens.AccessToken,
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}, nil
 }
 
-func (s *UserService) LogoutUser(ctx context.Context, userID pgtype.UUID) error {
+// LogoutUser ends the given session. Access tokens that predate session
+// tracking carry no session, in which case every session is ended.
+func (s *UserService) LogoutUser(ctx context.Context, userID, sessionID pgtype.UUID) error {
 	if ctx == nil {
 		return err2.ErrInvalidContex
 	}
@@ -172,12 +476,16 @@ func (s *UserService) LogoutUser(ctx context.Context, userID pgtype.UUID) error
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	// Clear the user's refresh token
-	_, err := s.userRepo.UpdateUserToken(ctx, db.UpdateUserTokenParams{
-		ID:                    userID,
-		RefreshToken:          "",
-		RefreshTokenExpiresAt: pgtype.Timestamp{Valid: false},
-	})
+	// Revoke the session's refresh tokens
+	var err error
+	if sessionID.Valid {
+		_, err = s.userRepo.DeleteUserSession(ctx, db.DeleteUserSessionParams{
+			UserID:    userID,
+			SessionID: sessionID,
+		})
+	} else {
+		err = s.userRepo.DeleteUserTokensByUserID(ctx, userID)
+	}
 	if err != nil {
 		return err2.ErrCleaningToken
 	}
@@ -185,11 +493,100 @@ func (s *UserService) LogoutUser(ctx context.Context, userID pgtype.UUID) error
 	return nil
 }
 
-func (s *UserService) GetUser(ctx context.Context, userID pgtype.UUID) (models.UserResponse, error) {
+// ListSessions returns the signed-in devices of the user, most recently used
+// first. The session of the access token making the request is flagged as
+// current.
+func (s *UserService) ListSessions(ctx context.Context, userID, currentSessionID pgtype.UUID) ([]models.SessionResponse, error) {
+	if ctx == nil {
+		return nil, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tokens, err := s.userRepo.ListUserSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing sessions: %w", err)
+	}
+
+	sessions := make([]models.SessionResponse, 0, len(tokens))
+	for _, token := range tokens {
+		sessions = append(sessions, models.SessionResponse{
+			ID:         token.SessionID,
+			UserAgent:  token.UserAgent.String,
+			IPAddress:  token.IpAddress.String,
+			CreatedAt:  token.SessionStartedAt.Time,
+			LastUsedAt: token.CreatedAt.Time,
+			ExpiresAt:  token.RefreshTokenExpiresAt.Time,
+			Current:    currentSessionID.Valid && token.SessionID == currentSessionID,
+		})
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs the user out on one device. Its access token stays
+// valid until it expires but can no longer be refreshed.
+func (s *UserService) RevokeSession(ctx context.Context, userID, sessionID pgtype.UUID) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	deleted, err := s.userRepo.DeleteUserSession(ctx, db.DeleteUserSessionParams{
+		UserID:    userID,
+		SessionID: sessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("error revoking session: %w", err)
+	}
+	if deleted == 0 {
+		return err2.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions signs the user out everywhere except in the current
+// session. Without a current session every session is revoked.
+func (s *UserService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID pgtype.UUID) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	var err error
+	if currentSessionID.Valid {
+		err = s.userRepo.DeleteOtherUserSessions(ctx, db.DeleteOtherUserSessionsParams{
+			UserID:    userID,
+			SessionID: currentSessionID,
+		})
+	} else {
+		err = s.userRepo.DeleteUserTokensByUserID(ctx, userID)
+	}
+	if err != nil {
+		return fmt.Errorf("error revoking sessions: %w", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) GetUser(ctx context.Context, actor models.Actor, userID pgtype.UUID) (models.UserResponse, error) {
 	if ctx == nil {
 		return models.UserResponse{}, err2.ErrInvalidContex
 	}
 
+	if !actor.CanManage(userID) {
+		return models.UserResponse{}, err2.ErrForbidden
+	}
+
 	// Create timeout context
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -199,51 +596,43 @@ func (s *UserService) GetUser(ctx context.Context, userID pgtype.UUID) (models.U
 		return models.UserResponse{}, err2.ErrUserNotFound
 	}
 
-	return models.UserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		FullName: user.FullName,
-		Email:    user.Email,
-	}, nil
+	return toUserResponse(user), nil
 }
 
-func (s *UserService) UpdateUser(ctx context.Context, userID pgtype.UUID, params models.UpdateUserParams) (models.UserResponse, error) {
+func (s *UserService) UpdateUser(ctx context.Context, actor models.Actor, userID pgtype.UUID, params models.UpdateUserParams) (models.UserResponse, error) {
 	if ctx == nil {
 		return models.UserResponse{}, err2.ErrInvalidContex
 	}
 
+	if !actor.CanManage(userID) {
+		return models.UserResponse{}, err2.ErrForbidden
+	}
+
 	// Trim whitespace from inputs
 	params.Email = strings.TrimSpace(params.Email)
 	params.Username = strings.TrimSpace(params.Username)
 
-	// Basic validation
-	if len(params.Password) > 0 && len(params.Password) < 8 {
-		return models.UserResponse{}, err2.ErrPassword8Symbols
+	email, err := normalizeEmail(params.Email)
+	if err != nil {
+		return models.UserResponse{}, err
 	}
+	params.Email = email
 
-	if !strings.Contains(params.Email, "@") {
-		return models.UserResponse{}, err2.ErrInvalidEmailFormat
+	current, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil || current.ErasedAt.Valid {
+		return models.UserResponse{}, err2.ErrUserNotFound
 	}
 
 	// Check if email already exists (using case-insensitive comparison)
-	if _, err := s.userRepo.GetUserByEmail(ctx, strings.ToLower(params.Email)); err == nil {
+	if existing, err := s.userRepo.GetUserByEmail(ctx, params.Email); err == nil && existing.ID != userID {
 		return models.UserResponse{}, err2.ErrEmailAlreadyExists
 	}
 
 	// Check if username already exists (using case-insensitive comparison)
-	if _, err := s.userRepo.GetUserByUsername(ctx, strings.ToLower(params.Username)); err == nil {
+	if existing, err := s.userRepo.GetUserByUsername(ctx, strings.ToLower(params.Username)); err == nil && existing.ID != userID {
 		return models.UserResponse{}, err2.ErrUsernameAlreadyExists
 	}
 
-	// Use password hashing cost if password is provided
-	if len(params.Password) > 0 {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(params.Password), bcrypt.DefaultCost+2)
-		if err != nil {
-			return models.UserResponse{}, fmt.Errorf("error hashing password: %w", err)
-		}
-		params.Password = string(hashedPassword)
-	}
-
 	// Create timeout context
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
@@ -253,37 +642,153 @@ func (s *UserService) UpdateUser(ctx context.Context, userID pgtype.UUID, params
 		Username: params.Username,
 		FullName: params.FullName,
 		Email:    params.Email,
-		Password: params.Password,
 	})
 	if err != nil {
 		return models.UserResponse{}, fmt.Errorf("error updating user: %w", err)
 	}
 
-	return models.UserResponse{
-		ID:       updatedUser.ID,
-		Username: updatedUser.Username,
-		FullName: updatedUser.FullName,
-		Email:    updatedUser.Email,
-	}, nil
+	// A new address has to be verified before it can be used to sign in
+	if updatedUser.Email != current.Email {
+		if err := s.userRepo.MarkUserEmailUnverified(ctx, userID); err != nil {
+			return models.UserResponse{}, fmt.Errorf("error updating user: %w", err)
+		}
+		updatedUser.EmailVerifiedAt = pgtype.Timestamp{}
+
+		if err := s.sendEmailVerification(ctx, updatedUser); err != nil {
+			log.Printf("email verification for %s: %v", updatedUser.Email, err)
+		}
+	}
+
+	return toUserResponse(updatedUser), nil
 }
 
-func (s *UserService) DeleteUser(ctx context.Context, userID pgtype.UUID) error {
+// DeleteUser erases an account. The row stays so that bookings remain
+// attributable in the providers' books, but every personal field is
+// overwritten and all credentials, sessions and linked identities are
+// removed. Providers must first remove their services, guests must cancel
+// upcoming stays.
+func (s *UserService) DeleteUser(ctx context.Context, actor models.Actor, userID pgtype.UUID) error {
 	if ctx == nil {
 		return err2.ErrInvalidContex
 	}
 
+	if !actor.CanManage(userID) {
+		return err2.ErrForbidden
+	}
+
 	// Create timeout context
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err := s.userRepo.DeleteUser(ctx, userID)
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil || user.ErasedAt.Valid {
+		return err2.ErrUserNotFound
+	}
+
+	services, err := s.userRepo.CountServicesByOwner(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("error counting services: %w", err)
+	}
+	if services > 0 {
+		return err2.ErrAccountOwnsServices
+	}
+
+	upcoming, err := s.userRepo.CountUpcomingBookingsByUser(ctx, db.CountUpcomingBookingsByUserParams{
+		UserID:   userID,
+		Statuses: []string{err2.RequestedStatus, err2.AcceptedStatus},
+		Today:    pgtype.Date{Time: time.Now(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("error counting bookings: %w", err)
+	}
+	if upcoming > 0 {
+		return err2.ErrAccountHasUpcomingBookings
+	}
+
+	// Placeholders derived from the ID keep the unique columns unique
+	placeholder := "deleted-" + hex.EncodeToString(userID.Bytes[:])
+
+	err = s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		if _, err := q.EraseUser(ctx, db.EraseUserParams{
+			ID:       userID,
+			Username: placeholder,
+			Email:    placeholder + "@erased.invalid",
+		}); err != nil {
+			return err
+		}
+		if err := q.DeleteUserTokensByUserID(ctx, userID); err != nil {
+			return err
+		}
+		if err := q.DeleteEmailVerificationTokensByUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := q.DeletePasswordResetTokensByUser(ctx, userID); err != nil {
+			return err
+		}
+		if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+		return q.DeleteUserIdentities(ctx, userID)
+	})
 	if err != nil {
+		log.Printf("erasing user %x: %v", userID.Bytes, err)
 		return err2.ErrDeletingUser
 	}
 
 	return nil
 }
 
+// ExportUserData collects the personal data stored about the user.
+func (s *UserService) ExportUserData(ctx context.Context, userID pgtype.UUID) (models.UserDataExport, error) {
+	if ctx == nil {
+		return models.UserDataExport{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil || user.ErasedAt.Valid {
+		return models.UserDataExport{}, err2.ErrUserNotFound
+	}
+
+	bookings, err := s.userRepo.ListBookingsByUser(ctx, userID)
+	if err != nil {
+		return models.UserDataExport{}, fmt.Errorf("error listing bookings: %w", err)
+	}
+
+	exported := make([]models.BookingExport, 0, len(bookings))
+	for _, booking := range bookings {
+		history, err := s.userRepo.ListBookingStatusHistory(ctx, booking.ID)
+		if err != nil {
+			return models.UserDataExport{}, fmt.Errorf("error listing booking history: %w", err)
+		}
+		exported = append(exported, models.BookingExport{
+			Booking:       toBooking(booking),
+			StatusHistory: toBookingStatusChanges(history),
+		})
+	}
+
+	sessions, err := s.ListSessions(ctx, userID, pgtype.UUID{})
+	if err != nil {
+		return models.UserDataExport{}, err
+	}
+
+	identities, err := s.userRepo.ListUserIdentities(ctx, userID)
+	if err != nil {
+		return models.UserDataExport{}, fmt.Errorf("error listing identities: %w", err)
+	}
+
+	return models.UserDataExport{
+		ExportedAt: time.Now().UTC(),
+		Profile:    toUserResponse(user),
+		Bookings:   exported,
+		Sessions:   sessions,
+		Identities: toIdentityResponses(identities),
+	}, nil
+}
+
 func (s *UserService) ListUsers(ctx context.Context, params db.ListUsersParams) ([]models.UserResponse, error) {
 	if ctx == nil {
 		return nil, err2.ErrInvalidContex
@@ -300,13 +805,637 @@ func (s *UserService) ListUsers(ctx context.Context, params db.ListUsersParams)
 
 	var userResponses []models.UserResponse
 	for _, user := range users {
-		userResponses = append(userResponses, models.UserResponse{
-			ID:       user.ID,
-			Username: user.Username,
-			FullName: user.FullName,
-			Email:    user.Email,
-		})
+		userResponses = append(userResponses, toUserResponse(user))
 	}
 
 	return userResponses, nil
 }
+
+// UpdateUserRole assigns a new role to a user. Tokens issued before the change
+// keep the old role until they are refreshed.
+func (s *UserService) UpdateUserRole(ctx context.Context, userID pgtype.UUID, role string) (models.UserResponse, error) {
+	if ctx == nil {
+		return models.UserResponse{}, err2.ErrInvalidContex
+	}
+
+	if role != err2.GuestRole && role != err2.ProviderRole && role != err2.AdminRole {
+		return models.UserResponse{}, err2.ErrInvalidRole
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.UpdateUserRole(ctx, db.UpdateUserRoleParams{
+		ID:   userID,
+		Role: role,
+	})
+	if err != nil {
+		return models.UserResponse{}, err2.ErrUserNotFound
+	}
+
+	return toUserResponse(user), nil
+}
+
+// VerifyEmail confirms the address the verification token was sent to.
+// Tokens are single-use and expire after emailVerificationTTL.
+func (s *UserService) VerifyEmail(ctx context.Context, token string) (models.UserResponse, error) {
+	if ctx == nil {
+		return models.UserResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stored, err := s.userRepo.GetEmailVerificationToken(ctx, utils.HashToken(token))
+	if err != nil || !stored.ExpiresAt.Valid || stored.ExpiresAt.Time.Before(time.Now()) {
+		return models.UserResponse{}, err2.ErrInvalidVerificationToken
+	}
+
+	// Losing this race means the token was used concurrently
+	if _, err := s.userRepo.UseEmailVerificationToken(ctx, stored.ID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.UserResponse{}, err2.ErrInvalidVerificationToken
+		}
+		return models.UserResponse{}, fmt.Errorf("error verifying email: %w", err)
+	}
+
+	user, err := s.userRepo.MarkUserEmailVerified(ctx, stored.UserID)
+	if err != nil {
+		return models.UserResponse{}, err2.ErrUserNotFound
+	}
+
+	return toUserResponse(user), nil
+}
+
+// ResendEmailVerification sends a fresh verification link and invalidates
+// the previous ones. Unknown and already verified addresses are silently
+// ignored so the endpoint cannot be used to probe for accounts.
+func (s *UserService) ResendEmailVerification(ctx context.Context, email string) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil || user.EmailVerifiedAt.Valid {
+		return nil
+	}
+
+	return s.sendEmailVerification(ctx, user)
+}
+
+// RequestPasswordReset mails a single-use reset link to the address.
+// Unknown addresses are silently ignored so the endpoint cannot be used to
+// probe for accounts.
+func (s *UserService) RequestPasswordReset(ctx context.Context, email string) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	email, err := normalizeEmail(email)
+	if err != nil {
+		return err
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		return nil
+	}
+
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	// Only the latest link works
+	if err := s.userRepo.DeletePasswordResetTokensByUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	_, err = s.userRepo.CreatePasswordResetToken(ctx, db.CreatePasswordResetTokenParams{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(passwordResetTTL), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.SendPasswordReset(ctx, user.Email, user.FullName, token)
+}
+
+// ResetPassword sets a new password using a token from RequestPasswordReset.
+// The token is consumed and every refresh token of the user is revoked, so
+// sessions opened with the old password cannot be renewed.
+func (s *UserService) ResetPassword(ctx context.Context, token, password string) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	stored, err := s.userRepo.GetPasswordResetToken(ctx, utils.HashToken(token))
+	if err != nil || !stored.ExpiresAt.Valid || stored.ExpiresAt.Time.Before(time.Now()) {
+		return err2.ErrInvalidResetToken
+	}
+
+	user, err := s.userRepo.GetUser(ctx, stored.UserID)
+	if err != nil {
+		return err2.ErrInvalidResetToken
+	}
+
+	if err := checkPasswordPolicy(password, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost+2)
+	if err != nil {
+		return fmt.Errorf("error hashing password: %w", err)
+	}
+
+	return s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		// Losing this race means the token was used concurrently
+		if _, err := q.UsePasswordResetToken(ctx, stored.ID); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return err2.ErrInvalidResetToken
+			}
+			return fmt.Errorf("error resetting password: %w", err)
+		}
+
+		if _, err := q.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+			ID:       stored.UserID,
+			Password: string(hashedPassword),
+		}); err != nil {
+			return fmt.Errorf("error resetting password: %w", err)
+		}
+
+		if err := q.DeletePasswordResetTokensByUser(ctx, stored.UserID); err != nil {
+			return fmt.Errorf("error resetting password: %w", err)
+		}
+
+		if err := q.DeleteUserTokensByUserID(ctx, stored.UserID); err != nil {
+			return err2.ErrCleaningToken
+		}
+
+		return nil
+	})
+}
+
+// ChangePassword replaces the password of the user after checking the
+// current one. All refresh tokens are revoked and a fresh token pair is
+// returned, so only the session that made the change stays signed in.
+func (s *UserService) ChangePassword(ctx context.Context, userID pgtype.UUID, currentPassword, newPassword string, device models.Device) (models.LoginResponse, error) {
+	if ctx == nil {
+		return models.LoginResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.LoginResponse{}, err2.ErrUserNotFound
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword)); err != nil {
+		return models.LoginResponse{}, err2.ErrWrongPassword
+	}
+
+	if currentPassword == newPassword {
+		return models.LoginResponse{}, err2.ErrPasswordUnchanged
+	}
+
+	if err := checkPasswordPolicy(newPassword, user.Username, user.Email); err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost+2)
+	if err != nil {
+		return models.LoginResponse{}, fmt.Errorf("error hashing password: %w", err)
+	}
+
+	err = s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		updated, err := q.UpdateUserPassword(ctx, db.UpdateUserPasswordParams{
+			ID:       userID,
+			Password: string(hashedPassword),
+		})
+		if err != nil {
+			return fmt.Errorf("error changing password: %w", err)
+		}
+		user = updated
+
+		if err := q.DeleteUserTokensByUserID(ctx, userID); err != nil {
+			return err2.ErrCleaningToken
+		}
+
+		return nil
+	})
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+
+	return s.openSession(ctx, user, device)
+}
+
+// MFAStatus reports whether two-factor authentication is enabled for the
+// user and how many recovery codes are left.
+func (s *UserService) MFAStatus(ctx context.Context, userID pgtype.UUID) (models.MFAStatusResponse, error) {
+	if ctx == nil {
+		return models.MFAStatusResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.MFAStatusResponse{}, err2.ErrUserNotFound
+	}
+
+	response := models.MFAStatusResponse{Enabled: user.TotpEnabledAt.Valid}
+	if !response.Enabled {
+		return response, nil
+	}
+
+	enabledAt := user.TotpEnabledAt.Time
+	response.EnabledAt = &enabledAt
+	if response.RecoveryCodesLeft, err = s.userRepo.CountUnusedRecoveryCodes(ctx, userID); err != nil {
+		return models.MFAStatusResponse{}, fmt.Errorf("error counting recovery codes: %w", err)
+	}
+
+	return response, nil
+}
+
+// EnrollTOTP starts setting up an authenticator app. The new secret only
+// takes effect once ConfirmTOTP has seen a code generated from it; starting
+// over replaces a secret that was never confirmed.
+func (s *UserService) EnrollTOTP(ctx context.Context, userID pgtype.UUID) (models.TOTPEnrollmentResponse, error) {
+	if ctx == nil {
+		return models.TOTPEnrollmentResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.TOTPEnrollmentResponse{}, err2.ErrUserNotFound
+	}
+	if user.TotpEnabledAt.Valid {
+		return models.TOTPEnrollmentResponse{}, err2.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return models.TOTPEnrollmentResponse{}, fmt.Errorf("error generating secret: %w", err)
+	}
+
+	encrypted, err := utils.EncryptString(secret, s.mfaKey)
+	if err != nil {
+		return models.TOTPEnrollmentResponse{}, fmt.Errorf("error encrypting secret: %w", err)
+	}
+
+	err = s.userRepo.SetUserTOTPSecret(ctx, db.SetUserTOTPSecretParams{
+		ID:         userID,
+		TotpSecret: pgtype.Text{String: encrypted, Valid: true},
+	})
+	if err != nil {
+		return models.TOTPEnrollmentResponse{}, fmt.Errorf("error storing secret: %w", err)
+	}
+
+	return models.TOTPEnrollmentResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP enables two-factor authentication once the user proves their
+// authenticator produces valid codes, and returns the first set of
+// recovery codes. They are shown only this once.
+func (s *UserService) ConfirmTOTP(ctx context.Context, userID pgtype.UUID, code string) (models.RecoveryCodesResponse, error) {
+	if ctx == nil {
+		return models.RecoveryCodesResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.RecoveryCodesResponse{}, err2.ErrUserNotFound
+	}
+	if user.TotpEnabledAt.Valid {
+		return models.RecoveryCodesResponse{}, err2.ErrMFAAlreadyEnabled
+	}
+	if !user.TotpSecret.Valid {
+		return models.RecoveryCodesResponse{}, err2.ErrMFANotEnrolled
+	}
+
+	secret, err := utils.DecryptString(user.TotpSecret.String, s.mfaKey)
+	if err != nil {
+		return models.RecoveryCodesResponse{}, fmt.Errorf("error decrypting secret: %w", err)
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return models.RecoveryCodesResponse{}, err2.ErrInvalidMFACode
+	}
+
+	var codes []string
+	err = s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		if _, err := q.EnableUserTOTP(ctx, db.EnableUserTOTPParams{
+			ID:           userID,
+			TotpLastStep: step,
+		}); err != nil {
+			return fmt.Errorf("error enabling two-factor authentication: %w", err)
+		}
+
+		codes, err = replaceRecoveryCodes(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return models.RecoveryCodesResponse{}, err
+	}
+
+	return models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTOTP turns two-factor authentication off. It takes the password and
+// a second factor so that a stolen session alone cannot weaken the account.
+func (s *UserService) DisableTOTP(ctx context.Context, userID pgtype.UUID, password, code string) error {
+	if ctx == nil {
+		return err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return err2.ErrUserNotFound
+	}
+	if !user.TotpEnabledAt.Valid {
+		return err2.ErrMFANotEnabled
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return err2.ErrWrongPassword
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code, time.Now())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return err2.ErrInvalidMFACode
+	}
+
+	return s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		if err := q.DisableUserTOTP(ctx, userID); err != nil {
+			return fmt.Errorf("error disabling two-factor authentication: %w", err)
+		}
+		if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+			return fmt.Errorf("error deleting recovery codes: %w", err)
+		}
+		return nil
+	})
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user, used or
+// not, with a new set.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, userID pgtype.UUID, code string) (models.RecoveryCodesResponse, error) {
+	if ctx == nil {
+		return models.RecoveryCodesResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if err != nil {
+		return models.RecoveryCodesResponse{}, err2.ErrUserNotFound
+	}
+	if !user.TotpEnabledAt.Valid {
+		return models.RecoveryCodesResponse{}, err2.ErrMFANotEnabled
+	}
+
+	ok, err := s.verifySecondFactor(ctx, user, code, time.Now())
+	if err != nil {
+		return models.RecoveryCodesResponse{}, err
+	}
+	if !ok {
+		return models.RecoveryCodesResponse{}, err2.ErrInvalidMFACode
+	}
+
+	var codes []string
+	err = s.userRepo.ExecTx(ctx, func(q *db.Queries) error {
+		codes, err = replaceRecoveryCodes(ctx, q, userID)
+		return err
+	})
+	if err != nil {
+		return models.RecoveryCodesResponse{}, err
+	}
+
+	return models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// verifySecondFactor accepts a TOTP code that was not used before or an
+// unused recovery code, consuming either.
+func (s *UserService) verifySecondFactor(ctx context.Context, user db.User, code string, now time.Time) (bool, error) {
+	secret, err := utils.DecryptString(user.TotpSecret.String, s.mfaKey)
+	if err != nil {
+		return false, fmt.Errorf("error decrypting secret: %w", err)
+	}
+
+	if step, ok := utils.ValidateTOTP(secret, code, now); ok {
+		// A step at or before the last accepted one is a replayed code
+		used, err := s.userRepo.UseTOTPStep(ctx, db.UseTOTPStepParams{
+			ID:           user.ID,
+			TotpLastStep: step,
+		})
+		if err != nil {
+			return false, fmt.Errorf("error storing code use: %w", err)
+		}
+		return used == 1, nil
+	}
+
+	used, err := s.userRepo.UseRecoveryCode(ctx, db.UseRecoveryCodeParams{
+		UserID:   user.ID,
+		CodeHash: hashRecoveryCode(code),
+	})
+	if err != nil {
+		return false, fmt.Errorf("error storing code use: %w", err)
+	}
+	return used == 1, nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and stores the
+// hashes of recoveryCodeCount new ones.
+func replaceRecoveryCodes(ctx context.Context, q *db.Queries, userID pgtype.UUID) ([]string, error) {
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, fmt.Errorf("error deleting recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for len(codes) < recoveryCodeCount {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("error generating recovery code: %w", err)
+		}
+
+		err = q.CreateRecoveryCode(ctx, db.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error storing recovery code: %w", err)
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// hashRecoveryCode hashes a recovery code regardless of case, spaces and
+// dashes, which users tend to get wrong when typing it.
+func hashRecoveryCode(code string) string {
+	code = strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return utils.HashToken(code)
+}
+
+// sendEmailVerification replaces any outstanding verification tokens of the
+// user with a new one and mails it. Only the hash of the token is stored.
+func (s *UserService) sendEmailVerification(ctx context.Context, user db.User) error {
+	token, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DeleteEmailVerificationTokensByUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	_, err = s.userRepo.CreateEmailVerificationToken(ctx, db.CreateEmailVerificationTokenParams{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: pgtype.Timestamp{Time: time.Now().Add(emailVerificationTTL), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.notifier.SendEmailVerification(ctx, user.Email, user.FullName, token)
+}
+
+// checkPasswordPolicy enforces the rules every new password has to meet: 8
+// to 72 bytes (bcrypt ignores anything longer), at least one letter and one
+// digit, and no username or email local part inside it.
+func checkPasswordPolicy(password string, identities ...string) error {
+	if len(password) < 8 {
+		return err2.ErrPassword8Symbols
+	}
+	if len(password) > 72 {
+		return err2.ErrPasswordTooLong
+	}
+
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return err2.ErrPasswordTooWeak
+	}
+
+	lowered := strings.ToLower(password)
+	for _, identity := range identities {
+		identity, _, _ = strings.Cut(strings.ToLower(identity), "@")
+		if len(identity) >= 3 && strings.Contains(lowered, identity) {
+			return err2.ErrPasswordContainsIdentity
+		}
+	}
+
+	return nil
+}
+
+// normalizeEmail validates a bare address such as "jane@example.com" and
+// returns it lower-cased. Display names ("Jane <jane@example.com>") are
+// rejected.
+func normalizeEmail(email string) (string, error) {
+	email = strings.TrimSpace(email)
+
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return "", err2.ErrInvalidEmailFormat
+	}
+
+	return strings.ToLower(email), nil
+}
+
+// UnlockUser lifts a lockout and clears the failed sign-in count of a user.
+func (s *UserService) UnlockUser(ctx context.Context, userID pgtype.UUID) (models.UserResponse, error) {
+	if ctx == nil {
+		return models.UserResponse{}, err2.ErrInvalidContex
+	}
+
+	// Create timeout context
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	user, err := s.userRepo.UnlockUser(ctx, userID)
+	if err != nil {
+		return models.UserResponse{}, err2.ErrUserNotFound
+	}
+
+	return toUserResponse(user), nil
+}
+
+func toUserResponse(user db.User) models.UserResponse {
+	response := models.UserResponse{
+		ID:       user.ID,
+		Username: user.Username,
+		FullName: user.FullName,
+		Email:    user.Email,
+		Role:     user.Role,
+	}
+	if user.EmailVerifiedAt.Valid {
+		verifiedAt := user.EmailVerifiedAt.Time
+		response.EmailVerifiedAt = &verifiedAt
+	}
+	response.MFAEnabled = user.TotpEnabledAt.Valid
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(time.Now()) {
+		lockedUntil := user.LockedUntil.Time
+		response.LockedUntil = &lockedUntil
+	}
+	if user.ErasedAt.Valid {
+		erasedAt := user.ErasedAt.Time
+		response.ErasedAt = &erasedAt
+	}
+	return response
+}
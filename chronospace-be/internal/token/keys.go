@@ -0,0 +1,184 @@
+package token
+
+import (
+	"chronospace-be/internal/config"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+type key struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+}
+
+// Manager signs tokens with one key and verifies them with any of the
+// configured keys.
+type Manager struct {
+	signing *key
+	keys    map[string]*key
+}
+
+// NewManager loads the keys described by the configuration.
+//
+// With HS256, SECRET_KEY signs under the ID JWT_KEY_ID and
+// JWT_PREVIOUS_SECRETS ("kid:secret,...") keeps retired secrets valid.
+// With RS256 or EdDSA, every <kid>.pem file in JWT_KEYS_DIR is trusted and
+// <JWT_KEY_ID>.pem, which must hold a private key, signs. Keys are rotated by
+// adding a new key, switching JWT_KEY_ID to it and removing the old one
+// once the tokens it signed have expired.
+func NewManager(cfg *config.Config) (*Manager, error) {
+	m := &Manager{keys: make(map[string]*key)}
+
+	if cfg.JWTKeyID == "" {
+		return nil, errors.New("JWT_KEY_ID is required")
+	}
+
+	for _, entry := range strings.Split(cfg.JWTPreviousSecrets, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, secret, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || secret == "" {
+			return nil, errors.New("JWT_PREVIOUS_SECRETS entries must look like kid:secret")
+		}
+		m.add(hmacKey(id, secret))
+	}
+
+	switch cfg.JWTAlgorithm {
+	case AlgorithmHS256:
+		if cfg.SecretKey == "" {
+			return nil, errors.New("SECRET_KEY is required for HS256")
+		}
+		m.add(hmacKey(cfg.JWTKeyID, cfg.SecretKey))
+	case AlgorithmRS256, AlgorithmEdDSA:
+		if err := m.loadDir(cfg.JWTKeysDir); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q", cfg.JWTAlgorithm)
+	}
+
+	signing, ok := m.keys[cfg.JWTKeyID]
+	if !ok || signing.private == nil {
+		return nil, fmt.Errorf("no private key with ID %q", cfg.JWTKeyID)
+	}
+	if signing.method.Alg() != cfg.JWTAlgorithm {
+		return nil, fmt.Errorf("key %q is not a %s key", cfg.JWTKeyID, cfg.JWTAlgorithm)
+	}
+	m.signing = signing
+
+	return m, nil
+}
+
+// NewHMACManager returns a manager that signs and verifies with a single
+// HS256 secret.
+func NewHMACManager(keyID, secret string) *Manager {
+	signing := hmacKey(keyID, secret)
+	return &Manager{
+		signing: signing,
+		keys:    map[string]*key{keyID: signing},
+	}
+}
+
+func (m *Manager) add(k *key) {
+	m.keys[k.id] = k
+}
+
+func hmacKey(id, secret string) *key {
+	return &key{
+		id:      id,
+		method:  jwt.SigningMethodHS256,
+		private: []byte(secret),
+		public:  []byte(secret),
+	}
+}
+
+// loadDir trusts every PEM file in dir, using the file name as key ID.
+func (m *Manager) loadDir(dir string) error {
+	if dir == "" {
+		return errors.New("JWT_KEYS_DIR is required for asymmetric keys")
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		id := strings.TrimSuffix(filepath.Base(path), ".pem")
+		k, err := parsePEM(id, data)
+		if err != nil {
+			return fmt.Errorf("key file %s: %w", path, err)
+		}
+		m.add(k)
+	}
+
+	return nil
+}
+
+// parsePEM reads an RSA or Ed25519 key. Private keys may be PKCS #1 or
+// PKCS #8, public keys PKIX.
+func parsePEM(id string, data []byte) (*key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	k := &key{id: id}
+	switch parsed := parsed.(type) {
+	case *rsa.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodRS256, parsed, &parsed.PublicKey
+	case *rsa.PublicKey:
+		k.method, k.public = jwt.SigningMethodRS256, parsed
+	case ed25519.PrivateKey:
+		k.method, k.private, k.public = jwt.SigningMethodEdDSA, parsed, parsed.Public()
+	case ed25519.PublicKey:
+		k.method, k.public = jwt.SigningMethodEdDSA, parsed
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	return k, nil
+}
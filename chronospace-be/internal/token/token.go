@@ -0,0 +1,199 @@
+// Package token issues and verifies the JWTs that authenticate API clients.
+// Tokens carry the ID of the key that signed them, so keys can be rotated
+// without invalidating tokens that are still in use.
+package token
+
+import (
+	"chronospace-be/internal/models"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Type tells apart tokens signed with the same keys but granting different
+// things.
+type Type string
+
+const (
+	TypeAccess  Type = "access"
+	TypeRefresh Type = "refresh"
+	// TypeMFA is issued after the password check of an account with
+	// two-factor authentication. It only allows completing the sign-in.
+	TypeMFA Type = "mfa"
+)
+
+const (
+	AccessTTL  = 15 * time.Minute
+	RefreshTTL = 7 * 24 * time.Hour
+	MFATTL     = 5 * time.Minute
+)
+
+// ContextKey is the gin context key the JWT middleware stores *Claims under.
+const ContextKey = "claims"
+
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrExpiredToken = errors.New("token has expired")
+	ErrTokenType    = errors.New("invalid token type")
+)
+
+// Claims are the claims of every token issued by Manager.
+type Claims struct {
+	UserID    string `json:"user_id"`
+	Role      string `json:"role,omitempty"`
+	SessionID string `json:"sid,omitempty"`
+	Type      Type   `json:"type"`
+	jwt.RegisteredClaims
+}
+
+// User returns the ID of the user the token was issued to.
+func (c *Claims) User() (pgtype.UUID, error) {
+	var id pgtype.UUID
+	if err := id.Scan(c.UserID); err != nil {
+		return pgtype.UUID{}, ErrInvalidToken
+	}
+	return id, nil
+}
+
+// Session returns the session an access token belongs to. Tokens issued
+// before sessions were tracked carry none.
+func (c *Claims) Session() (pgtype.UUID, bool) {
+	var id pgtype.UUID
+	if c.SessionID == "" || id.Scan(c.SessionID) != nil {
+		return pgtype.UUID{}, false
+	}
+	return id, true
+}
+
+// GenerateTokens issues an access and a refresh token for a session of the
+// user.
+func (m *Manager) GenerateTokens(userID pgtype.UUID, role string, sessionID pgtype.UUID) (models.Tokens, error) {
+	now := time.Now()
+	accessExpiry := now.Add(AccessTTL)
+	refreshExpiry := now.Add(RefreshTTL)
+
+	accessToken, err := m.Issue(Claims{
+		UserID:    encodeUUID(userID),
+		Role:      role,
+		SessionID: encodeUUID(sessionID),
+		Type:      TypeAccess,
+	}, now, accessExpiry)
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("error generating access token: %w", err)
+	}
+
+	refreshToken, err := m.Issue(Claims{
+		UserID: encodeUUID(userID),
+		Type:   TypeRefresh,
+	}, now, refreshExpiry)
+	if err != nil {
+		return models.Tokens{}, fmt.Errorf("error generating refresh token: %w", err)
+	}
+
+	return models.Tokens{
+		AccessToken:   accessToken,
+		RefreshToken:  refreshToken,
+		AccessExpiry:  accessExpiry,
+		RefreshExpiry: refreshExpiry,
+	}, nil
+}
+
+// GenerateMFAToken issues the token that is exchanged, together with a
+// second factor, for a token pair.
+func (m *Manager) GenerateMFAToken(userID pgtype.UUID) (string, error) {
+	now := time.Now()
+	return m.Issue(Claims{
+		UserID: encodeUUID(userID),
+		Type:   TypeMFA,
+	}, now, now.Add(MFATTL))
+}
+
+// Issue signs claims with the current signing key. The subject, token ID and
+// validity period are filled in.
+func (m *Manager) Issue(claims Claims, issuedAt, expiresAt time.Time) (string, error) {
+	// Unique token IDs keep tokens issued within the same second distinct
+	tokenID, err := randomID()
+	if err != nil {
+		return "", fmt.Errorf("error generating token id: %w", err)
+	}
+
+	claims.Subject = claims.UserID
+	claims.ID = tokenID
+	claims.IssuedAt = jwt.NewNumericDate(issuedAt)
+	claims.ExpiresAt = jwt.NewNumericDate(expiresAt)
+
+	token := jwt.NewWithClaims(m.signing.method, claims)
+	token.Header["kid"] = m.signing.id
+
+	return token.SignedString(m.signing.private)
+}
+
+// Parse verifies a token and checks that it is of the expected type.
+func (m *Manager) Parse(raw string, want Type) (*Claims, error) {
+	claims := &Claims{}
+	_, err := jwt.ParseWithClaims(raw, claims, m.keyFunc,
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, ErrInvalidToken
+	}
+
+	if claims.Type != want {
+		return nil, ErrTokenType
+	}
+	if _, err := claims.User(); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// keyFunc picks the verification key named by the token's kid header. The
+// algorithm must be the one the key was configured for, so a public key can
+// never be used as an HMAC secret.
+//
+// Tokens issued before key IDs were introduced carry none. They were signed
+// with SECRET_KEY, so they are only accepted while that secret still signs,
+// i.e. with HS256; deployments on asymmetric keys reject them.
+func (m *Manager) keyFunc(token *jwt.Token) (interface{}, error) {
+	var key *key
+	if kid, ok := token.Header["kid"]; ok {
+		id, _ := kid.(string)
+		if key, ok = m.keys[id]; !ok {
+			return nil, fmt.Errorf("unknown signing key %q", id)
+		}
+	} else {
+		if m.signing.method != jwt.SigningMethodHS256 {
+			return nil, errors.New("token has no key ID")
+		}
+		key = m.signing
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// encodeUUID formats a UUID the way tokens have always carried them: as 32
+// hex digits.
+func encodeUUID(id pgtype.UUID) string {
+	return hex.EncodeToString(id.Bytes[:])
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,228 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"chronospace-be/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+var testUser = pgtype.UUID{Bytes: [16]byte{1, 2, 3}, Valid: true}
+
+// keyFiles writes an RSA and an Ed25519 private key to a directory, named
+// "rsa" and "ed", and returns the directory and the RSA key.
+func keyFiles(t *testing.T) (string, *rsa.PrivateKey) {
+	t.Helper()
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generating RSA key: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "rsa.pem"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generating Ed25519 key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatalf("encoding Ed25519 key: %v", err)
+	}
+	writePEM(t, filepath.Join(dir, "ed.pem"), "PRIVATE KEY", der)
+
+	return dir, rsaKey
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatalf("writing %s: %v", path, err)
+	}
+}
+
+func newManager(t *testing.T, cfg config.Config) *Manager {
+	t.Helper()
+	m, err := NewManager(&cfg)
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return m
+}
+
+// sign returns a token with the given header kid (none when empty), signed
+// with method and key.
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, typ Type) string {
+	t.Helper()
+	now := time.Now()
+	token := jwt.NewWithClaims(method, Claims{
+		UserID: encodeUUID(testUser),
+		Type:   typ,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		},
+	})
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("signing token: %v", err)
+	}
+	return raw
+}
+
+func TestManagerParse(t *testing.T) {
+	dir, rsaKey := keyFiles(t)
+
+	hmac := newManager(t, config.Config{
+		JWTAlgorithm:       AlgorithmHS256,
+		JWTKeyID:           "current",
+		SecretKey:          "current-secret",
+		JWTPreviousSecrets: "old:old-secret",
+	})
+	rs256 := newManager(t, config.Config{JWTAlgorithm: AlgorithmRS256, JWTKeyID: "rsa", JWTKeysDir: dir})
+	eddsa := newManager(t, config.Config{JWTAlgorithm: AlgorithmEdDSA, JWTKeyID: "ed", JWTKeysDir: dir})
+
+	issue := func(m *Manager, typ Type) func(t *testing.T) string {
+		return func(t *testing.T) string {
+			now := time.Now()
+			raw, err := m.Issue(Claims{UserID: encodeUUID(testUser), Type: typ}, now, now.Add(time.Minute))
+			if err != nil {
+				t.Fatalf("Issue() error = %v", err)
+			}
+			return raw
+		}
+	}
+	rsaPublicPEM := func(t *testing.T) []byte {
+		der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		if err != nil {
+			t.Fatalf("encoding public key: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	tests := []struct {
+		name     string
+		verifier *Manager
+		token    func(t *testing.T) string
+		want     Type
+		wantErr  error
+	}{
+		{name: "HS256 round trip", verifier: hmac, token: issue(hmac, TypeAccess), want: TypeAccess},
+		{name: "RS256 round trip", verifier: rs256, token: issue(rs256, TypeAccess), want: TypeAccess},
+		{name: "EdDSA round trip", verifier: eddsa, token: issue(eddsa, TypeAccess), want: TypeAccess},
+		{name: "refresh token as refresh", verifier: hmac, token: issue(hmac, TypeRefresh), want: TypeRefresh},
+		{
+			name:     "previous secret still verifies",
+			verifier: hmac,
+			token:    issue(NewHMACManager("old", "old-secret"), TypeAccess),
+			want:     TypeAccess,
+		},
+		{
+			name:     "key from the same directory still verifies",
+			verifier: eddsa,
+			token:    issue(rs256, TypeAccess),
+			want:     TypeAccess,
+		},
+		{
+			name:     "unknown kid",
+			verifier: hmac,
+			token:    issue(NewHMACManager("retired", "old-secret"), TypeAccess),
+			wantErr:  ErrInvalidToken,
+		},
+		{
+			name:     "known kid with another secret",
+			verifier: hmac,
+			token:    issue(NewHMACManager("old", "guessed-secret"), TypeAccess),
+			wantErr:  ErrInvalidToken,
+		},
+		{
+			name:     "HS256 signed with the RSA public key",
+			verifier: rs256,
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, "rsa", rsaPublicPEM(t), TypeAccess)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:     "HS256 signed with the RSA public key and no kid",
+			verifier: rs256,
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, "", rsaPublicPEM(t), TypeAccess)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:     "RS256 token under an HMAC kid",
+			verifier: hmac,
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodRS256, "current", rsaKey, TypeAccess)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:     "no kid with HS256 signing",
+			verifier: hmac,
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodHS256, "", []byte("current-secret"), TypeAccess)
+			},
+			want: TypeAccess,
+		},
+		{
+			name:     "no kid with RS256 signing",
+			verifier: rs256,
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodRS256, "", rsaKey, TypeAccess)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{name: "refresh token as access", verifier: hmac, token: issue(hmac, TypeRefresh), want: TypeAccess, wantErr: ErrTokenType},
+		{name: "MFA token as access", verifier: hmac, token: issue(hmac, TypeMFA), want: TypeAccess, wantErr: ErrTokenType},
+		{name: "access token as refresh", verifier: rs256, token: issue(rs256, TypeAccess), want: TypeRefresh, wantErr: ErrTokenType},
+		{
+			name:     "expired",
+			verifier: hmac,
+			token: func(t *testing.T) string {
+				past := time.Now().Add(-time.Hour)
+				raw, err := hmac.Issue(Claims{UserID: encodeUUID(testUser), Type: TypeAccess}, past, past.Add(time.Minute))
+				if err != nil {
+					t.Fatalf("Issue() error = %v", err)
+				}
+				return raw
+			},
+			want:    TypeAccess,
+			wantErr: ErrExpiredToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := tt.verifier.Parse(tt.token(t), tt.want)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if user, _ := claims.User(); user != testUser {
+				t.Fatalf("Parse() user = %v, want %v", user, testUser)
+			}
+		})
+	}
+}
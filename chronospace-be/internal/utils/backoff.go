@@ -0,0 +1,27 @@
+package utils
+
+import "time"
+
+const (
+	loginBackoffBase = time.Second
+	loginBackoffMax  = time.Minute
+)
+
+// LoginBackoff returns how long a client has to wait after its last failed
+// sign-in before it may try again. The first free failures cost nothing;
+// every further one doubles the delay, starting at one second and capped at
+// one minute.
+func LoginBackoff(failures, free int) time.Duration {
+	if failures < free {
+		return 0
+	}
+
+	delay := loginBackoffBase
+	for i := free; i < failures && delay < loginBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > loginBackoffMax {
+		delay = loginBackoffMax
+	}
+	return delay
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	uniqueViolationCode    = "23505"
+	exclusionViolationCode = "23P01"
+)
+
+// IsUniqueViolation reports whether err was caused by a unique constraint.
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
+
+// IsExclusionViolation reports whether err was caused by an exclusion constraint.
+func IsExclusionViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == exclusionViolationCode
+}
+
+// ReplaceError swaps the sentinel from at the start of err's message for to,
+// keeping the details that follow it. Other errors are returned unchanged.
+func ReplaceError(err, from, to error) error {
+	if !errors.Is(err, from) {
+		return err
+	}
+	return fmt.Errorf("%w%s", to, strings.TrimPrefix(err.Error(), from.Error()))
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"chronospace-be/internal/models"
+	"encoding/json"
+)
+
+// ExportArchive packs a user data export into a ZIP file with one JSON
+// document per kind of data.
+func ExportArchive(export models.UserDataExport) ([]byte, error) {
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"bookings.json", export.Bookings},
+		{"sessions.json", export.Sessions},
+		{"identities.json", export.Identities},
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.name,
+			Method:   zip.Deflate,
+			Modified: export.ExportedAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
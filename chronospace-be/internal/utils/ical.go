@@ -0,0 +1,260 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ICalEvent is an all-day event as exchanged with other booking channels.
+// End is exclusive, so a one-night stay ends on the check-out date.
+type ICalEvent struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+const (
+	icalDateLayout     = "20060102"
+	icalDateTimeLayout = "20060102T150405"
+	icalLineLimit      = 75
+)
+
+var ErrInvalidICalendar = errors.New("invalid iCalendar data")
+
+// WriteICalendar writes events as an RFC 5545 VCALENDAR with CRLF line
+// endings and folded long lines.
+func WriteICalendar(w io.Writer, name string, events []ICalEvent) error {
+	bw := bufio.NewWriter(w)
+	stamp := time.Now().UTC().Format(icalDateTimeLayout) + "Z"
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Chronospace//Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeICalText(name),
+	}
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeICalText(event.UID),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+event.Start.Format(icalDateLayout),
+			"DTEND;VALUE=DATE:"+event.End.Format(icalDateLayout),
+			"SUMMARY:"+escapeICalText(event.Summary),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(foldICalLine(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// ParseICalendar reads the VEVENTs of a calendar feed. Only the dates of the
+// events are kept: timed events block every night they span. Cancelled
+// events are skipped.
+func ParseICalendar(r io.Reader) ([]ICalEvent, error) {
+	lines, err := unfoldICalLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []ICalEvent
+		inEvent  bool
+		skip     bool
+		event    ICalEvent
+		hasEnd   bool
+		duration time.Duration
+	)
+	for _, line := range lines {
+		name, params, value, ok := splitICalLine(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && strings.EqualFold(value, "VEVENT"):
+			inEvent, skip, hasEnd, duration = true, false, false, 0
+			event = ICalEvent{}
+		case name == "END" && strings.EqualFold(value, "VEVENT"):
+			if !inEvent {
+				return nil, fmt.Errorf("%w: unexpected END:VEVENT", ErrInvalidICalendar)
+			}
+			inEvent = false
+			if skip {
+				continue
+			}
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("%w: event %q has no DTSTART", ErrInvalidICalendar, event.UID)
+			}
+			if !hasEnd {
+				event.End = event.Start.Add(duration)
+			}
+			// Events shorter than a night still block their start date
+			if !event.End.After(event.Start) {
+				event.End = event.Start.AddDate(0, 0, 1)
+			}
+			events = append(events, event)
+		case !inEvent:
+			continue
+		case name == "UID":
+			event.UID = unescapeICalText(value)
+		case name == "SUMMARY":
+			event.Summary = unescapeICalText(value)
+		case name == "STATUS":
+			skip = strings.EqualFold(value, "CANCELLED")
+		case name == "DTSTART":
+			if event.Start, err = parseICalDate(params, value); err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			if event.End, err = parseICalDate(params, value); err != nil {
+				return nil, err
+			}
+			hasEnd = true
+		case name == "DURATION":
+			if duration, err = parseICalDuration(value); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if inEvent {
+		return nil, fmt.Errorf("%w: unterminated VEVENT", ErrInvalidICalendar)
+	}
+	return events, nil
+}
+
+func foldICalLine(line string) string {
+	var sb strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > icalLineLimit {
+			sb.WriteString("\r\n ")
+			width = 1
+		}
+		sb.WriteRune(r)
+		width += size
+	}
+	sb.WriteString("\r\n")
+	return sb.String()
+}
+
+func unfoldICalLines(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// splitICalLine splits a content line into its upper-cased name, its
+// parameters and its value. Colons inside quoted parameter values are
+// ignored.
+func splitICalLine(line string) (string, map[string]string, string, bool) {
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		}
+		if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, value, _ := strings.Cut(part, "=")
+		params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:], true
+}
+
+func parseICalDate(params map[string]string, value string) (time.Time, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(icalDateLayout) {
+		t, err := time.Parse(icalDateLayout, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("%w: bad date %q", ErrInvalidICalendar, value)
+		}
+		return t, nil
+	}
+
+	loc := time.UTC
+	if tzid := params["TZID"]; tzid != "" && !strings.HasSuffix(value, "Z") {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation(icalDateTimeLayout, strings.TrimSuffix(value, "Z"), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: bad date-time %q", ErrInvalidICalendar, value)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+// parseICalDuration supports the day and week forms of RFC 5545 durations
+// (e.g. P1D, P2W); time parts are ignored since only dates are kept.
+func parseICalDuration(value string) (time.Duration, error) {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	if datePart, _, _ := strings.Cut(value, "T"); datePart != "" {
+		var n int
+		var unit byte
+		if _, err := fmt.Sscanf(datePart, "%d%c", &n, &unit); err != nil {
+			return 0, fmt.Errorf("%w: bad duration %q", ErrInvalidICalendar, value)
+		}
+		switch unit {
+		case 'D':
+			return time.Duration(n) * 24 * time.Hour, nil
+		case 'W':
+			return time.Duration(n) * 7 * 24 * time.Hour, nil
+		}
+		return 0, fmt.Errorf("%w: bad duration %q", ErrInvalidICalendar, value)
+	}
+	return 0, nil
+}
+
+var icalEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+var icalUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escapeICalText(value string) string {
+	return icalEscaper.Replace(strings.ReplaceAll(value, "\r\n", "\n"))
+}
+
+func unescapeICalText(value string) string {
+	return icalUnescaper.Replace(value)
+}
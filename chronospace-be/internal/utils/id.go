@@ -1,6 +1,9 @@
 package utils
 
 import (
+	"chronospace-be/internal/models"
+	"chronospace-be/internal/token"
+	"crypto/rand"
 	"errors"
 
 	"github.com/gin-gonic/gin"
@@ -8,30 +11,74 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// GetClaimsFromContext returns the claims of the access token the JWT
+// middleware authenticated the request with.
+func GetClaimsFromContext(ctx *gin.Context) (*token.Claims, bool) {
+	value, exists := ctx.Get(token.ContextKey)
+	if !exists {
+		return nil, false
+	}
+
+	claims, ok := value.(*token.Claims)
+	return claims, ok
+}
+
+// GetUserIDFromContext returns the ID of the user authenticated by the JWT
+// middleware.
 func GetUserIDFromContext(ctx *gin.Context) (pgtype.UUID, error) {
-	authHeader := ctx.GetHeader("Authorization")
-	if authHeader == "" {
-		return pgtype.UUID{}, errors.New("no authorization header")
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return pgtype.UUID{}, errors.New("no authenticated user")
 	}
 
-	if len(authHeader) <= 7 || authHeader[:7] != "Bearer " {
-		return pgtype.UUID{}, errors.New("invalid authorization header format")
+	userID, err := claims.User()
+	if err != nil {
+		return pgtype.UUID{}, errors.New("failed to parse user ID from token")
+	}
+
+	return userID, nil
+}
+
+// GetActorFromContext returns the authenticated user together with the role
+// carried by their token.
+func GetActorFromContext(ctx *gin.Context) (models.Actor, error) {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return models.Actor{}, errors.New("no authenticated user")
 	}
-	tokenString := authHeader[7:]
 
-	// Parse the token and extract user ID
-	claims, err := ParseToken(tokenString)
+	userID, err := claims.User()
 	if err != nil {
-		return pgtype.UUID{}, err
+		return models.Actor{}, errors.New("failed to parse user ID from token")
 	}
 
-	// Assuming the ID is stored in claims as string
-	var userID pgtype.UUID
-	if err := userID.Scan(claims.ID); err != nil {
-		return pgtype.UUID{}, errors.New("failed to parse user ID from token")
+	return models.Actor{
+		UserID: userID,
+		Role:   claims.Role,
+	}, nil
+}
+
+// GetSessionIDFromContext returns the session the access token of the request
+// was issued for. Tokens issued before sessions were tracked carry none.
+func GetSessionIDFromContext(ctx *gin.Context) (pgtype.UUID, bool) {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return pgtype.UUID{}, false
 	}
 
-	return userID, nil
+	return claims.Session()
+}
+
+// NewUUID returns a random (version 4) UUID.
+func NewUUID() (pgtype.UUID, error) {
+	var uuid pgtype.UUID
+	if _, err := rand.Read(uuid.Bytes[:]); err != nil {
+		return pgtype.UUID{}, err
+	}
+	uuid.Bytes[6] = (uuid.Bytes[6] & 0x0f) | 0x40
+	uuid.Bytes[8] = (uuid.Bytes[8] & 0x3f) | 0x80
+	uuid.Valid = true
+	return uuid, nil
 }
 
 func ParseUUID(id string) (pgtype.UUID, error) {
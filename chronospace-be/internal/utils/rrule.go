@@ -0,0 +1,354 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RRule is the subset of an RFC 5545 recurrence rule that makes sense for
+// day-based schedules: DAILY, WEEKLY, MONTHLY and YEARLY frequencies with
+// INTERVAL, COUNT, UNTIL, BYDAY, BYMONTHDAY and BYMONTH.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []RRuleWeekday
+	ByMonthDay []int
+	ByMonth    []time.Month
+}
+
+// RRuleWeekday is a BYDAY entry. N is the optional ordinal (e.g. 1 for the
+// first Monday, -1 for the last Friday); zero matches every such weekday.
+type RRuleWeekday struct {
+	Weekday time.Weekday
+	N       int
+}
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// maxRRuleCount caps COUNT so expanding a rule stays cheap.
+const maxRRuleCount = 1000
+
+var ErrInvalidRRule = errors.New("invalid recurrence rule")
+
+// ParseRRule parses a recurrence rule such as "FREQ=WEEKLY;BYDAY=MO,WE,FR".
+// An optional "RRULE:" prefix is accepted.
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, ErrInvalidRRule
+	}
+
+	rule := &RRule{Interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			switch strings.ToUpper(val) {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				rule.Freq = strings.ToUpper(val)
+			default:
+				return nil, fmt.Errorf("%w: unsupported frequency %q", ErrInvalidRRule, val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("must be positive")
+			}
+			if err == nil && rule.Count > maxRRuleCount {
+				err = fmt.Errorf("must be at most %d", maxRRuleCount)
+			}
+		case "UNTIL":
+			rule.Until, err = parseRRuleDate(val)
+		case "BYDAY":
+			rule.ByDay, err = parseRRuleWeekdays(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseRRuleInts(val, 1, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseRRuleInts(val, 1, 12)
+			for _, month := range months {
+				if month < 0 {
+					err = errors.New("must be positive")
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			// Weeks always start on Monday; the value is validated but ignored.
+			if _, ok := rruleWeekdays[strings.ToUpper(val)]; !ok {
+				err = errors.New("unknown weekday")
+			}
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRRule, key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRRule, key, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRRule)
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != FreqMonthly && rule.Freq != FreqYearly {
+			return nil, fmt.Errorf("%w: BYDAY ordinals require MONTHLY or YEARLY", ErrInvalidRRule)
+		}
+	}
+
+	return rule, nil
+}
+
+// Between returns the occurrence dates of the rule starting at dtstart that
+// fall in the window [from, to). Dates are returned in ascending order at
+// midnight UTC.
+func (r *RRule) Between(dtstart, from, to time.Time) []time.Time {
+	start := truncateDay(dtstart)
+	from = truncateDay(from)
+	to = truncateDay(to)
+
+	end := to
+	if !r.Until.IsZero() && r.Until.Before(end) {
+		end = r.Until.AddDate(0, 0, 1)
+	}
+
+	// COUNT limits occurrences from dtstart, so they have to be walked from
+	// the beginning; otherwise the walk can start at the window.
+	day := start
+	if r.Count == 0 && from.After(day) {
+		day = from
+	}
+
+	var dates []time.Time
+	seen := 0
+	for ; day.Before(end); day = r.next(start, day) {
+		if !r.matches(start, day) {
+			continue
+		}
+		seen++
+		if !day.Before(from) {
+			dates = append(dates, day)
+		}
+		if r.Count > 0 && seen >= r.Count {
+			break
+		}
+	}
+
+	return dates
+}
+
+// next returns the day after day that can hold an occurrence. Periods
+// skipped by INTERVAL and months excluded by BYMONTH are jumped over whole.
+func (r *RRule) next(start, day time.Time) time.Time {
+	if !r.inInterval(start, day) {
+		switch r.Freq {
+		case FreqWeekly:
+			return weekStart(day).AddDate(0, 0, 7)
+		case FreqMonthly:
+			return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case FreqYearly:
+			return time.Date(day.Year()+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		}
+	}
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, day.Month()) {
+		return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day.AddDate(0, 0, 1)
+}
+
+func (r *RRule) matches(start, day time.Time) bool {
+	if !r.inInterval(start, day) {
+		return false
+	}
+
+	if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, day.Month()) {
+		return false
+	}
+
+	if len(r.ByMonthDay) > 0 && !matchesMonthDay(r.ByMonthDay, day) {
+		return false
+	}
+
+	if len(r.ByDay) > 0 && !r.matchesWeekday(day) {
+		return false
+	}
+
+	// Without BYxxx parts the rule repeats on dtstart's weekday, day of
+	// month or day of year respectively.
+	switch r.Freq {
+	case FreqWeekly:
+		if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+			return false
+		}
+	case FreqMonthly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && day.Day() != start.Day() {
+			return false
+		}
+	case FreqYearly:
+		if len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 {
+			if day.Day() != start.Day() {
+				return false
+			}
+			if len(r.ByMonth) == 0 && day.Month() != start.Month() {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (r *RRule) inInterval(start, day time.Time) bool {
+	var elapsed int
+	switch r.Freq {
+	case FreqDaily:
+		elapsed = int(day.Sub(start).Hours() / 24)
+	case FreqWeekly:
+		elapsed = int(weekStart(day).Sub(weekStart(start)).Hours() / (24 * 7))
+	case FreqMonthly:
+		elapsed = (day.Year()-start.Year())*12 + int(day.Month()) - int(start.Month())
+	case FreqYearly:
+		elapsed = day.Year() - start.Year()
+	}
+	return elapsed%r.Interval == 0
+}
+
+func (r *RRule) matchesWeekday(day time.Time) bool {
+	for _, byDay := range r.ByDay {
+		if byDay.Weekday != day.Weekday() {
+			continue
+		}
+		if byDay.N == 0 {
+			return true
+		}
+
+		// Ordinals count within the month, or within the year for YEARLY
+		// rules without BYMONTH.
+		var first, last time.Time
+		if r.Freq == FreqYearly && len(r.ByMonth) == 0 {
+			first = time.Date(day.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+			last = time.Date(day.Year(), time.December, 31, 0, 0, 0, 0, time.UTC)
+		} else {
+			first = time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+			last = first.AddDate(0, 1, -1)
+		}
+
+		if byDay.N > 0 && int(day.Sub(first).Hours()/24)/7+1 == byDay.N {
+			return true
+		}
+		if byDay.N < 0 && -(int(last.Sub(day).Hours()/24)/7+1) == byDay.N {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesMonthDay(monthDays []int, day time.Time) bool {
+	daysInMonth := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	for _, monthDay := range monthDays {
+		if monthDay > 0 && day.Day() == monthDay {
+			return true
+		}
+		if monthDay < 0 && day.Day() == daysInMonth+monthDay+1 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
+
+// weekStart returns the Monday of the week containing day.
+func weekStart(day time.Time) time.Time {
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func parseRRuleDate(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return truncateDay(t), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", value)
+}
+
+func parseRRuleWeekdays(value string) ([]RRuleWeekday, error) {
+	var days []RRuleWeekday
+	for _, item := range strings.Split(value, ",") {
+		item = strings.ToUpper(strings.TrimSpace(item))
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		weekday, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", item)
+		}
+
+		n := 0
+		if prefix := item[:len(item)-2]; prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("invalid weekday ordinal %q", item)
+			}
+		}
+		days = append(days, RRuleWeekday{Weekday: weekday, N: n})
+	}
+	return days, nil
+}
+
+func parseRRuleInts(value string, min, max int) ([]int, error) {
+	var result []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < -max || n > max || (n > 0 && n < min) {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		result = append(result, n)
+	}
+	return result, nil
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestRRuleBetweenOrdinalWeekdays(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     string
+		dtstart  time.Time
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:    "first Monday with COUNT",
+			rule:    "FREQ=MONTHLY;BYDAY=1MO;COUNT=3",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.January, 1),
+			to:      date(2025, time.January, 1),
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.February, 5), date(2024, time.March, 4)},
+		},
+		{
+			name:    "COUNT is counted from dtstart, not from the window",
+			rule:    "FREQ=MONTHLY;BYDAY=2TU;COUNT=4",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.March, 1),
+			to:      date(2024, time.December, 31),
+			want:    []time.Time{date(2024, time.March, 12), date(2024, time.April, 9)},
+		},
+		{
+			name:    "last Friday until an inclusive date",
+			rule:    "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20240426",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.January, 1),
+			to:      date(2025, time.January, 1),
+			want: []time.Time{
+				date(2024, time.January, 26), date(2024, time.February, 23),
+				date(2024, time.March, 29), date(2024, time.April, 26),
+			},
+		},
+		{
+			name:    "every other month with COUNT",
+			rule:    "FREQ=MONTHLY;INTERVAL=2;BYDAY=1MO;COUNT=3",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.January, 1),
+			to:      date(2025, time.January, 1),
+			want:    []time.Time{date(2024, time.January, 1), date(2024, time.March, 4), date(2024, time.May, 6)},
+		},
+		{
+			name:    "yearly ordinal counts within the year",
+			rule:    "FREQ=YEARLY;BYDAY=1MO;COUNT=2",
+			dtstart: date(2024, time.January, 1),
+			from:    date(2024, time.January, 1),
+			to:      date(2026, time.January, 1),
+			want:    []time.Time{date(2024, time.January, 1), date(2025, time.January, 6)},
+		},
+		{
+			name:    "yearly ordinal counts within BYMONTH",
+			rule:    "FREQ=YEARLY;BYMONTH=11;BYDAY=4TH;UNTIL=20251231",
+			dtstart: date(2023, time.January, 1),
+			from:    date(2023, time.January, 1),
+			to:      date(2027, time.January, 1),
+			want:    []time.Time{date(2023, time.November, 23), date(2024, time.November, 28), date(2025, time.November, 27)},
+		},
+		{
+			name:    "COUNT rule that never matches from an ancient dtstart",
+			rule:    "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=30;COUNT=5",
+			dtstart: date(1, time.January, 1),
+			from:    date(2024, time.January, 1),
+			to:      date(2025, time.January, 1),
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q): %v", tt.rule, err)
+			}
+
+			got := rule.Between(tt.dtstart, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Between() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Fatalf("Between() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRRuleLimits(t *testing.T) {
+	tests := []struct {
+		rule    string
+		wantErr bool
+	}{
+		{rule: "FREQ=DAILY;COUNT=1000"},
+		{rule: "FREQ=DAILY;COUNT=1001", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=0", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYDAY=-1SU;COUNT=12"},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYDAY=1MO;COUNT=3;UNTIL=20240101", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			_, err := ParseRRule(tt.rule)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("ParseRRule(%q) error = %v, want error %v", tt.rule, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRRule) {
+				t.Fatalf("ParseRRule(%q) error = %v, want ErrInvalidRRule", tt.rule, err)
+			}
+		})
+	}
+}